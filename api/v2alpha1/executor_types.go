@@ -46,6 +46,24 @@ type StoreOptions struct {
 
 	// Parameters is additional parameters for the store. Optional.
 	Parameters runtime.RawExtension `json:"parameters,omitempty"`
+
+	// DiskCache configures the cache of the manifests and blobs fetched by the
+	// store on local disk. Optional.
+	// +optional
+	DiskCache *DiskCacheOptions `json:"diskCache,omitempty"`
+}
+
+type DiskCacheOptions struct {
+	// Path is the directory the manifests and blobs are cached in. Required.
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// MaxSizeBytes is the maximum total size of the cached content. The least
+	// recently used content is evicted once exceeded. Defaults to 1 GiB.
+	// Optional.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxSizeBytes int64 `json:"maxSizeBytes,omitempty"`
 }
 
 type PolicyEnforcerOptions struct {
@@ -57,6 +75,21 @@ type PolicyEnforcerOptions struct {
 	Parameters runtime.RawExtension `json:"parameters,omitempty"`
 }
 
+type PlatformPolicyOptions struct {
+	// Mode defines which manifests of an image index are validated. Set to
+	// "platforms" to validate the child manifests matching Platforms, or "all"
+	// to validate every platform specific child manifest. Defaults to "index",
+	// which validates the image index only. Optional.
+	// +kubebuilder:validation:Enum=index;platforms;all
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Platforms is the list of platforms to validate in the format of
+	// "os/arch" or "os/arch/variant". Required if Mode is "platforms".
+	// +optional
+	Platforms []string `json:"platforms,omitempty"`
+}
+
 // ExecutorSpec defines the desired state of Executor.
 type ExecutorSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -67,19 +100,48 @@ type ExecutorSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	Scopes []string `json:"scopes"`
 
+	// ExcludeScopes defines the scopes exempted from validation. Artifacts
+	// matching an excluded scope are reported as exempt instead of being
+	// validated, unless they match a more specific scope. Optional.
+	// +optional
+	ExcludeScopes []string `json:"excludeScopes,omitempty"`
+
+	// EnforcementMode defines how the validation result is enforced. Set to
+	// "exempt" to skip validation of all artifacts in the scopes. Set to
+	// "warn" or "audit" to allow artifacts failing the validation while still
+	// reporting the outcome, where "warn" also logs the would-be denials.
+	// Defaults to "enforce". Optional.
+	// +kubebuilder:validation:Enum=enforce;exempt;warn;audit
+	// +optional
+	EnforcementMode string `json:"enforcementMode,omitempty"`
+
 	// Verifiers contains the configuration options for the verifiers. At least
-	// one verifier must be provided. Required.
+	// one verifier must be provided unless the enforcement mode is "exempt".
 	// +kubebuilder:validation:MinItems=1
-	Verifiers []*VerifierOptions `json:"verifiers"`
+	// +optional
+	Verifiers []*VerifierOptions `json:"verifiers,omitempty"`
 
 	// Stores contains the configuration options for the stores. At least one
-	// store must be provided. Required.
+	// store must be provided unless the enforcement mode is "exempt".
 	// +kubebuilder:validation:MinItems=1
-	Stores []*StoreOptions `json:"stores"`
+	// +optional
+	Stores []*StoreOptions `json:"stores,omitempty"`
 
 	// PolicyEnforcer contains the configuration options for the policy
 	// enforcer. Optional.
 	PolicyEnforcer *PolicyEnforcerOptions `json:"policyEnforcer,omitempty"`
+
+	// PlatformPolicy defines how image indexes are validated. Optional.
+	// +optional
+	PlatformPolicy *PlatformPolicyOptions `json:"platformPolicy,omitempty"`
+
+	// Shadow marks the executor as a candidate configuration. A shadow
+	// executor validates live traffic alongside the active executors, but
+	// never decides the outcome. Disagreements with the active executors are
+	// logged and counted. Shadow executors do not conflict with active
+	// executors registering the same scopes. Optional.
+	// +optional
+	Shadow bool `json:"shadow,omitempty"`
 }
 
 // ExecutorStatus defines the observed state of Executor.
@@ -91,7 +153,8 @@ type ExecutorStatus struct {
 	// ready to process requests. Required.
 	Succeeded bool `json:"succeeded"`
 
-	// Error is the error message if the executor failed to start.
+	// Error is the error message if the executor failed to start. It also
+	// reports scope conflicts with other executors while Succeeded is true.
 	// +optional
 	Error string `json:"error,omitempty"`
 
@@ -24,6 +24,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskCacheOptions) DeepCopyInto(out *DiskCacheOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskCacheOptions.
+func (in *DiskCacheOptions) DeepCopy() *DiskCacheOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskCacheOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Executor) DeepCopyInto(out *Executor) {
 	*out = *in
@@ -91,6 +106,11 @@ func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExcludeScopes != nil {
+		in, out := &in.ExcludeScopes, &out.ExcludeScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Verifiers != nil {
 		in, out := &in.Verifiers, &out.Verifiers
 		*out = make([]*VerifierOptions, len(*in))
@@ -118,6 +138,11 @@ func (in *ExecutorSpec) DeepCopyInto(out *ExecutorSpec) {
 		*out = new(PolicyEnforcerOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PlatformPolicy != nil {
+		in, out := &in.PlatformPolicy, &out.PlatformPolicy
+		*out = new(PlatformPolicyOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutorSpec.
@@ -145,6 +170,26 @@ func (in *ExecutorStatus) DeepCopy() *ExecutorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformPolicyOptions) DeepCopyInto(out *PlatformPolicyOptions) {
+	*out = *in
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformPolicyOptions.
+func (in *PlatformPolicyOptions) DeepCopy() *PlatformPolicyOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformPolicyOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyEnforcerOptions) DeepCopyInto(out *PolicyEnforcerOptions) {
 	*out = *in
@@ -165,6 +210,11 @@ func (in *PolicyEnforcerOptions) DeepCopy() *PolicyEnforcerOptions {
 func (in *StoreOptions) DeepCopyInto(out *StoreOptions) {
 	*out = *in
 	in.Parameters.DeepCopyInto(&out.Parameters)
+	if in.DiskCache != nil {
+		in, out := &in.DiskCache, &out.DiskCache
+		*out = new(DiskCacheOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoreOptions.
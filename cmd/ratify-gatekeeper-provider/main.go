@@ -16,15 +16,40 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/notaryproject/ratify/v2/internal/cache/factory"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
 	"github.com/notaryproject/ratify/v2/internal/httpserver"
+	"github.com/notaryproject/ratify/v2/internal/logger"
 	"github.com/notaryproject/ratify/v2/internal/manager"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/store/credentialprovider"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultTraceIDHeader = "X-Ratify-Trace-Id"
+	defaultCacheTTL      = 5 * time.Second
+
+	// defaultCredentialCacheSize is the maximum number of registry
+	// credentials cached by each credential provider.
+	defaultCredentialCacheSize = 10
+
+	// redisPasswordEnv is the environment variable holding the password of
+	// Redis, which is not accepted as a flag to keep it out of the process
+	// arguments and the logs.
+	redisPasswordEnv = "RATIFY_REDIS_PASSWORD"
+)
+
 var startManagerFunc = manager.StartManager
 
 // main is the entry point for the Ratify server.
@@ -36,56 +61,185 @@ func main() {
 }
 
 type options struct {
-	configFilePath       string
-	httpServerAddress    string
-	certFile             string
-	keyFile              string
-	gatekeeperCACertFile string
-	disableCertRotation  bool
-	disableMutation      bool
-	disableCRDManager    bool
-	verifyTimeout        time.Duration
-	mutateTimeout        time.Duration
+	configFilePath         string
+	shadowConfigFilePath   string
+	httpServerAddress      string
+	certFile               string
+	keyFile                string
+	gatekeeperCACertFile   string
+	adminAddress           string
+	adminClientCACertFile  string
+	disableCertRotation    bool
+	disableMutation        bool
+	disableCRDManager      bool
+	enableAdmissionWebhook bool
+	admissionAddress       string
+	healthAddress          string
+	keepTagOnMutation      bool
+	verifyThenPin          bool
+	verifyTimeout          time.Duration
+	verifyItemTimeout      time.Duration
+	mutateTimeout          time.Duration
+	verifyConcurrency      int
+	metricsPort            int
+	tracingEndpoint        string
+	tracingSampleRatio     float64
+	logFormatter           string
+	traceIDHeaders         string
+	verifyCache            httpserver.CacheOptions
+	mutateCache            httpserver.CacheOptions
+	validationCache        httpserver.CacheOptions
+	credentialCacheType    string
+	redisAddress           string
+	redisUsername          string
+	redisDB                int
+	redisTLS               bool
 }
 
 func parse() *options {
 	opts := &options{}
 	flag.StringVar(&opts.configFilePath, "config", "", "Path to the Ratify configuration file")
+	flag.StringVar(&opts.shadowConfigFilePath, "shadow-config", "", "Path to the configuration file of the shadow executor, only used if the CRD manager is disabled")
 	flag.StringVar(&opts.httpServerAddress, "address", "", "HTTP server address")
 	flag.StringVar(&opts.certFile, "cert-file", "", "Path to the TLS certificate file")
 	flag.StringVar(&opts.keyFile, "key-file", "", "Path to the TLS key file")
 	flag.StringVar(&opts.gatekeeperCACertFile, "gatekeeper-ca-cert-file", "", "Path to the Gatekeeper CA certificate file")
+	flag.StringVar(&opts.adminAddress, "admin-address", "", "Address of the admin endpoint reporting and purging the caches, the admin endpoint is disabled if empty")
+	flag.StringVar(&opts.adminClientCACertFile, "admin-client-ca-cert-file", "", "Path to the CA certificate file the client certificates of the admin endpoint are verified against")
 	flag.DurationVar(&opts.verifyTimeout, "verify-timeout", 5*time.Second, "Verification timeout duration (e.g. 5s, 1m), default is 5 seconds")
+	flag.DurationVar(&opts.verifyItemTimeout, "verify-item-timeout", 0, "Timeout of each image of a verification request (e.g. 1s), images timing out are reported with the \"timeout\" error code and keep being verified in the background, only bounded by the verification timeout if 0")
 	flag.DurationVar(&opts.mutateTimeout, "mutate-timeout", 2*time.Second, "Mutation timeout duration (e.g. 5s, 1m), default is 2 seconds")
+	flag.IntVar(&opts.verifyConcurrency, "verify-concurrency", 5, "Maximum number of artifacts verified concurrently per request, default is 5")
+	flag.IntVar(&opts.metricsPort, "metrics-port", 0, "Port to serve Prometheus metrics on (e.g. 8888), metrics are disabled if 0, default is 0")
+	flag.StringVar(&opts.tracingEndpoint, "tracing-endpoint", "", "URL of the OTLP/HTTP collector to export traces to (e.g. http://localhost:4318), tracing is disabled if empty")
+	flag.Float64Var(&opts.tracingSampleRatio, "tracing-sample-ratio", 1, "Fraction of traces started by Ratify to sample, between 0 and 1, default is 1")
+	flag.StringVar(&opts.logFormatter, "log-formatter", "text", "Log formatter, one of text, json or logstash, default is text")
+	flag.StringVar(&opts.traceIDHeaders, "trace-id-headers", defaultTraceIDHeader, "Comma-separated names of the request headers carrying the trace ID of a request. The trace ID is returned in the same headers of the response and generated if absent from the request")
+	cacheFlags(&opts.verifyCache, "verify", "verification results")
+	flag.DurationVar(&opts.verifyCache.StaleWhileRevalidate, "verify-cache-stale-while-revalidate", 0, "Grace period after the TTL during which the last successful verification result of a digest is served while revalidated in the background, stale results are not served if 0")
+	cacheFlags(&opts.mutateCache, "mutate", "pinned references")
+	cacheFlags(&opts.validationCache, "validation", "artifact validation API results")
+	flag.StringVar(&opts.credentialCacheType, "credential-cache-type", factory.TypeInMemory, "Type of the cache of registry credentials, one of ristretto, inmemory or redis, default is inmemory")
+	flag.StringVar(&opts.redisAddress, "redis-address", "", "Address (host:port) of the Redis server used by the caches of the redis type, the password is read from the "+redisPasswordEnv+" environment variable")
+	flag.StringVar(&opts.redisUsername, "redis-username", "", "Username to authenticate to Redis with, if Redis uses ACLs")
+	flag.IntVar(&opts.redisDB, "redis-db", 0, "Redis database used by the caches, default is 0")
+	flag.BoolVar(&opts.redisTLS, "redis-tls", false, "Connect to Redis over TLS")
 	flag.BoolVar(&opts.disableCertRotation, "disable-cert-rotation", false, "Disable certificate rotation")
+	flag.BoolVar(&opts.verifyThenPin, "verify-then-pin", false, "Only pin references to digests that pass the verification")
 	flag.BoolVar(&opts.disableMutation, "disable-mutation", false, "Disable mutation wehbook")
 	flag.BoolVar(&opts.disableCRDManager, "disable-crd-manager", false, "Disable CRD manager for Gatekeeper provider")
+	flag.BoolVar(&opts.enableAdmissionWebhook, "enable-admission-webhook", false, "Enable the native Kubernetes admission webhooks")
+	flag.StringVar(&opts.admissionAddress, "admission-address", "", "Address of the admission webhooks served with TLS without client certificate authentication, the admission webhooks are served at the server address if empty")
+	flag.StringVar(&opts.healthAddress, "health-address", "", "Address of the liveness, readiness and status endpoints served without TLS, they are served at the server address if empty")
+	flag.BoolVar(&opts.keepTagOnMutation, "keep-tag-on-mutation", false, "Keep the tag of images pinned by the mutating admission webhook in the form of repo:tag@digest")
 
 	flag.Parse()
 	logrus.Infof("Starting Ratify with options: %+v", opts)
 	return opts
 }
 
+// cacheFlags registers the flags configuring the cache of the given name.
+func cacheFlags(opts *httpserver.CacheOptions, name, description string) {
+	flag.BoolVar(&opts.Disabled, "disable-"+name+"-cache", false, fmt.Sprintf("Disable the cache of %s", description))
+	flag.DurationVar(&opts.TTL, name+"-cache-ttl", defaultCacheTTL, fmt.Sprintf("TTL of successful %s in the cache, default is 5 seconds", description))
+	flag.DurationVar(&opts.FailureTTL, name+"-cache-failure-ttl", 0, fmt.Sprintf("TTL of failed %s and errors in the cache, failures are cached for the TTL of successes and errors are not cached if 0", description))
+	flag.Int64Var(&opts.MaxCost, name+"-cache-max-cost", 0, fmt.Sprintf("Maximum number of %s in the cache, the cache is bounded by its default maximum cost if 0", description))
+	flag.StringVar(&opts.Type, name+"-cache-type", factory.TypeRistretto, fmt.Sprintf("Type of the cache of %s, one of ristretto, inmemory or redis, a redis cache is shared by the replicas and falls back to a local cache while Redis is unreachable, default is ristretto", description))
+}
+
 func startRatify(opts *options) error {
 	if len(opts.httpServerAddress) == 0 {
 		return errors.New("HTTP server address is required")
 	}
+	if err := logger.InitLogConfig(logger.Config{
+		Formatter: opts.logFormatter,
+		RequestHeaders: map[string]interface{}{
+			logger.TraceIDHeaderName: splitHeaderNames(opts.traceIDHeaders),
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	if opts.metricsPort != 0 {
+		if err := metrics.StartPrometheusExporter(opts.metricsPort); err != nil {
+			return fmt.Errorf("failed to start metrics exporter: %w", err)
+		}
+	}
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Options{
+		Endpoint:    opts.tracingEndpoint,
+		SampleRatio: opts.tracingSampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.Warnf("failed to shut down tracing: %v", err)
+		}
+	}()
+
+	redisOpts := opts.redisOptions()
+	credentialprovider.SetCacheOptions(factory.Options{
+		Type:    opts.credentialCacheType,
+		MaxCost: defaultCredentialCacheSize,
+		Redis:   redisOpts,
+	})
+
 	var certRotatorReady chan struct{}
 	if !opts.disableCertRotation {
 		certRotatorReady = make(chan struct{})
 	}
 	serverOpts := &httpserver.ServerOptions{
-		HTTPServerAddress:    opts.httpServerAddress,
-		CertFile:             opts.certFile,
-		KeyFile:              opts.keyFile,
-		GatekeeperCACertFile: opts.gatekeeperCACertFile,
-		VerifyTimeout:        opts.verifyTimeout,
-		MutateTimeout:        opts.mutateTimeout,
-		DisableMutation:      opts.disableMutation,
-		DisableCRDManager:    opts.disableCRDManager,
-		CertRotatorReady:     certRotatorReady,
+		HTTPServerAddress:      opts.httpServerAddress,
+		CertFile:               opts.certFile,
+		KeyFile:                opts.keyFile,
+		GatekeeperCACertFile:   opts.gatekeeperCACertFile,
+		AdminAddress:           opts.adminAddress,
+		AdminClientCACertFile:  opts.adminClientCACertFile,
+		VerifyTimeout:          opts.verifyTimeout,
+		VerifyItemTimeout:      opts.verifyItemTimeout,
+		MutateTimeout:          opts.mutateTimeout,
+		VerifyConcurrency:      opts.verifyConcurrency,
+		VerifyThenPin:          opts.verifyThenPin,
+		VerifyCache:            opts.verifyCache,
+		MutateCache:            opts.mutateCache,
+		ValidationCache:        opts.validationCache,
+		Redis:                  redisOpts,
+		DisableMutation:        opts.disableMutation,
+		DisableCRDManager:      opts.disableCRDManager,
+		ShadowConfigFile:       opts.shadowConfigFilePath,
+		EnableAdmissionWebhook: opts.enableAdmissionWebhook,
+		AdmissionAddress:       opts.admissionAddress,
+		HealthAddress:          opts.healthAddress,
+		KeepTagOnMutation:      opts.keepTagOnMutation,
+		CertRotatorReady:       certRotatorReady,
 	}
 
-	go startManagerFunc(certRotatorReady, serverOpts.DisableMutation, serverOpts.DisableCRDManager)
+	go startManagerFunc(certRotatorReady, serverOpts.DisableMutation, serverOpts.DisableCRDManager, serverOpts.EnableAdmissionWebhook)
 	return httpserver.StartServer(serverOpts, opts.configFilePath)
 }
+
+// redisOptions returns the options of the connection to Redis.
+func (opts *options) redisOptions() redis.Options {
+	redisOpts := redis.Options{
+		Address:  opts.redisAddress,
+		Username: opts.redisUsername,
+		Password: os.Getenv(redisPasswordEnv),
+		DB:       opts.redisDB,
+	}
+	if opts.redisTLS {
+		redisOpts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return redisOpts
+}
+
+// splitHeaderNames splits the comma-separated header names, ignoring empty
+// names.
+func splitHeaderNames(headers string) []string {
+	var names []string
+	for _, name := range strings.Split(headers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -21,6 +21,9 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache/factory"
+	"github.com/notaryproject/ratify/v2/internal/httpserver"
 )
 
 func TestMain_FailedStartingRatify(t *testing.T) {
@@ -59,14 +62,50 @@ func TestParse(t *testing.T) {
 				"-cert-file=cert.pem",
 				"-key-file=key.pem",
 				"-verify-timeout=10s",
+				"-verify-item-timeout=3s",
+				"-verify-concurrency=10",
+				"-tracing-endpoint=http://localhost:4318",
+				"-log-formatter=json",
+				"-trace-id-headers=X-Request-Id,X-Correlation-Id",
+				"-verify-cache-ttl=1m",
+				"-verify-cache-failure-ttl=1s",
+				"-verify-cache-stale-while-revalidate=1h",
+				"-disable-mutate-cache",
+				"-validation-cache-max-cost=100",
+				"-admin-address=:8443",
+				"-admin-client-ca-cert-file=admin-ca.pem",
+				"-verify-cache-type=redis",
+				"-credential-cache-type=redis",
+				"-redis-address=redis:6379",
+				"-redis-username=ratify",
+				"-redis-db=1",
+				"-redis-tls",
+				"-metrics-port=8888",
 			},
 			expected: &options{
-				configFilePath:    "config.json",
-				httpServerAddress: ":8080",
-				certFile:          "cert.pem",
-				keyFile:           "key.pem",
-				verifyTimeout:     10 * time.Second,
-				mutateTimeout:     2 * time.Second,
+				configFilePath:        "config.json",
+				httpServerAddress:     ":8080",
+				certFile:              "cert.pem",
+				keyFile:               "key.pem",
+				adminAddress:          ":8443",
+				adminClientCACertFile: "admin-ca.pem",
+				verifyTimeout:         10 * time.Second,
+				verifyItemTimeout:     3 * time.Second,
+				mutateTimeout:         2 * time.Second,
+				verifyConcurrency:     10,
+				metricsPort:           8888,
+				tracingEndpoint:       "http://localhost:4318",
+				tracingSampleRatio:    1,
+				logFormatter:          "json",
+				traceIDHeaders:        "X-Request-Id,X-Correlation-Id",
+				verifyCache:           httpserver.CacheOptions{Type: factory.TypeRedis, TTL: time.Minute, FailureTTL: time.Second, StaleWhileRevalidate: time.Hour},
+				mutateCache:           httpserver.CacheOptions{Type: factory.TypeRistretto, Disabled: true, TTL: defaultCacheTTL},
+				validationCache:       httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL, MaxCost: 100},
+				credentialCacheType:   factory.TypeRedis,
+				redisAddress:          "redis:6379",
+				redisUsername:         "ratify",
+				redisDB:               1,
+				redisTLS:              true,
 			},
 		},
 		{
@@ -76,16 +115,32 @@ func TestParse(t *testing.T) {
 				"-mutate-timeout=10s",
 			},
 			expected: &options{
-				verifyTimeout: 30 * time.Second,
-				mutateTimeout: 10 * time.Second,
+				verifyTimeout:       30 * time.Second,
+				mutateTimeout:       10 * time.Second,
+				verifyConcurrency:   5,
+				tracingSampleRatio:  1,
+				logFormatter:        "text",
+				traceIDHeaders:      defaultTraceIDHeader,
+				verifyCache:         httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				mutateCache:         httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				validationCache:     httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				credentialCacheType: factory.TypeInMemory,
 			},
 		},
 		{
 			name: "default values",
 			args: []string{},
 			expected: &options{
-				verifyTimeout: 5 * time.Second,
-				mutateTimeout: 2 * time.Second,
+				verifyTimeout:       5 * time.Second,
+				mutateTimeout:       2 * time.Second,
+				verifyConcurrency:   5,
+				tracingSampleRatio:  1,
+				logFormatter:        "text",
+				traceIDHeaders:      defaultTraceIDHeader,
+				verifyCache:         httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				mutateCache:         httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				validationCache:     httpserver.CacheOptions{Type: factory.TypeRistretto, TTL: defaultCacheTTL},
+				credentialCacheType: factory.TypeInMemory,
 			},
 		},
 	}
@@ -109,7 +164,7 @@ func TestParse(t *testing.T) {
 }
 
 func TestStartRatify(t *testing.T) {
-	startManagerFunc = func(_ chan struct{}, _, _ bool) {}
+	startManagerFunc = func(_ chan struct{}, _, _, _ bool) {}
 	tests := []struct {
 		name        string
 		opts        *options
@@ -125,6 +180,40 @@ func TestStartRatify(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "invalid metrics port",
+			opts: &options{
+				httpServerAddress:   ":8080",
+				configFilePath:      "config.yaml",
+				disableCertRotation: true,
+				disableCRDManager:   true,
+				metricsPort:         -1,
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid log formatter",
+			opts: &options{
+				httpServerAddress:   ":8080",
+				configFilePath:      "config.yaml",
+				disableCertRotation: true,
+				disableCRDManager:   true,
+				logFormatter:        "invalid",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid tracing endpoint",
+			opts: &options{
+				httpServerAddress:   ":8080",
+				configFilePath:      "config.yaml",
+				disableCertRotation: true,
+				disableCRDManager:   true,
+				tracingEndpoint:     "localhost:4318",
+				tracingSampleRatio:  1,
+			},
+			expectError: true,
+		},
 		{
 			name: "failed to start the server",
 			opts: &options{
@@ -147,3 +236,35 @@ func TestStartRatify(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitHeaderNames(t *testing.T) {
+	tests := []struct {
+		headers  string
+		expected []string
+	}{
+		{headers: "", expected: nil},
+		{headers: "X-Request-Id", expected: []string{"X-Request-Id"}},
+		{headers: " X-Request-Id, ,X-Correlation-Id ", expected: []string{"X-Request-Id", "X-Correlation-Id"}},
+	}
+	for _, tt := range tests {
+		if got := splitHeaderNames(tt.headers); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("splitHeaderNames(%q) = %v, expected %v", tt.headers, got, tt.expected)
+		}
+	}
+}
+
+func TestRedisOptions(t *testing.T) {
+	t.Setenv(redisPasswordEnv, "secret")
+	opts := &options{redisAddress: "redis:6379", redisUsername: "ratify", redisDB: 2}
+	redisOpts := opts.redisOptions()
+	if redisOpts.Address != "redis:6379" || redisOpts.Username != "ratify" || redisOpts.Password != "secret" || redisOpts.DB != 2 {
+		t.Errorf("unexpected redis options: %+v", redisOpts)
+	}
+	if redisOpts.TLSConfig != nil {
+		t.Errorf("expected no TLS configuration")
+	}
+	opts.redisTLS = true
+	if redisOpts := opts.redisOptions(); redisOpts.TLSConfig == nil {
+		t.Errorf("expected TLS configuration")
+	}
+}
@@ -38,4 +38,24 @@ type Cache[T any] interface {
 
 	// Delete removes the specified key/value from the cache.
 	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix removes all key/values whose key starts with the prefix
+	// and returns the number of removed entries. All entries are removed if
+	// the prefix is empty.
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+
+	// Stats returns the statistics of the cache.
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Stats holds the statistics of a cache.
+type Stats struct {
+	// Entries is the number of entries held by the cache.
+	Entries int `json:"entries"`
+
+	// Hits is the number of lookups that found an entry.
+	Hits uint64 `json:"hits"`
+
+	// Misses is the number of lookups that found no entry.
+	Misses uint64 `json:"misses"`
 }
@@ -0,0 +1,94 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
+)
+
+// Types of the caches created by [NewCache].
+const (
+	TypeRistretto = "ristretto"
+	TypeInMemory  = "inmemory"
+	TypeRedis     = "redis"
+)
+
+// redisKeyPrefix is the prefix of the Redis keys of all caches.
+const redisKeyPrefix = "ratify:"
+
+// Options configures a cache created by [NewCache].
+type Options struct {
+	// Type is the type of the cache, one of [TypeRistretto], [TypeInMemory]
+	// and [TypeRedis]. Default is [TypeRistretto] if not specified.
+	// Optional.
+	Type string
+
+	// Name identifies the cache. The Redis keys of the cache are prefixed
+	// with the name, so that caches sharing a Redis database do not collide.
+	// Required for [TypeRedis].
+	Name string
+
+	// TTL is the default TTL of the entries of the cache.
+	// Optional.
+	TTL time.Duration
+
+	// MaxCost is the maximum number of entries kept in the local cache.
+	// Default is the default maximum size of the cache type if not specified.
+	// Optional.
+	MaxCost int64
+
+	// Redis configures the connection to Redis. Only used by [TypeRedis].
+	// Optional.
+	Redis redis.Options
+}
+
+// NewCache creates the cache configured by the options. A Redis cache falls
+// back to a ristretto cache while Redis is unreachable, and encodes its values
+// as JSON.
+func NewCache[T any](opts Options) (cache.Cache[T], error) {
+	switch opts.Type {
+	case "", TypeRistretto:
+		return newRistrettoCache[T](opts)
+	case TypeInMemory:
+		return inmemory.NewCacheWithTTL[T](int(opts.MaxCost), opts.TTL)
+	case TypeRedis:
+		if opts.Name == "" {
+			return nil, fmt.Errorf("name is required for a %s cache", TypeRedis)
+		}
+		local, err := newRistrettoCache[T](opts)
+		if err != nil {
+			return nil, err
+		}
+		redisOpts := opts.Redis
+		redisOpts.KeyPrefix = redisKeyPrefix + opts.Name + ":" + redisOpts.KeyPrefix
+		return redis.NewCache(redisOpts, opts.TTL, redis.JSONSerializer[T]{}, local)
+	default:
+		return nil, fmt.Errorf("unsupported cache type %q, supported types are %q, %q and %q", opts.Type, TypeRistretto, TypeInMemory, TypeRedis)
+	}
+}
+
+func newRistrettoCache[T any](opts Options) (cache.Cache[T], error) {
+	if opts.MaxCost == 0 {
+		return ristretto.NewCache[T](opts.TTL)
+	}
+	return ristretto.NewCacheWithMaxCost[T](opts.TTL, opts.MaxCost)
+}
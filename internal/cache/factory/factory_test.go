@@ -0,0 +1,67 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
+)
+
+func TestNewCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantType string
+		wantErr  bool
+	}{
+		{name: "default", opts: Options{TTL: time.Second}, wantType: TypeRistretto},
+		{name: "ristretto with max cost", opts: Options{Type: TypeRistretto, TTL: time.Second, MaxCost: 10}, wantType: TypeRistretto},
+		{name: "invalid ristretto max cost", opts: Options{Type: TypeRistretto, MaxCost: -1}, wantErr: true},
+		{name: "inmemory", opts: Options{Type: TypeInMemory, TTL: time.Second, MaxCost: 10}, wantType: TypeInMemory},
+		{name: "invalid inmemory TTL", opts: Options{Type: TypeInMemory, TTL: -time.Second}, wantErr: true},
+		{name: "redis", opts: Options{Type: TypeRedis, Name: "verify", TTL: time.Second, Redis: redis.Options{Address: "localhost:6379"}}, wantType: TypeRedis},
+		{name: "redis without name", opts: Options{Type: TypeRedis, Redis: redis.Options{Address: "localhost:6379"}}, wantErr: true},
+		{name: "redis without address", opts: Options{Type: TypeRedis, Name: "verify"}, wantErr: true},
+		{name: "unsupported", opts: Options{Type: "memcached"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCache[string](tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %t, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			var ok bool
+			switch tt.wantType {
+			case TypeRistretto:
+				_, ok = c.(*ristretto.Cache[string])
+			case TypeInMemory:
+				_, ok = c.(*inmemory.Cache[string])
+			case TypeRedis:
+				_, ok = c.(*redis.Cache[string])
+			}
+			if !ok {
+				t.Errorf("expected a %s cache, got %T", tt.wantType, c)
+			}
+		})
+	}
+}
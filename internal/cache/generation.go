@@ -0,0 +1,74 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import "sync"
+
+// generations tracks the invalidations of cached results by scope.
+var generations = &generationTracker{
+	scopes: make(map[string]string),
+}
+
+// generationTracker holds the version of the last invalidation of all scopes
+// and of each scope.
+type generationTracker struct {
+	mu     sync.RWMutex
+	all    string
+	scopes map[string]string
+}
+
+// Invalidate invalidates the cached results of the artifacts matching any of
+// the given executor scopes, e.g. after a key provider refreshed the
+// certificates trusted in those scopes. The results of all scopes are
+// invalidated if no scope is given.
+//
+// The version identifies the state the results are invalidated for, e.g. the
+// fingerprint of the refreshed certificates. It is derived from content rather
+// than counted, so that replicas sharing a cache agree on the generation once
+// they reach the same state, and never share results across different states.
+//
+// Cached entries are not removed. Instead, the generation of the scopes
+// changes, and callers fold [Generation] into their cache keys so that entries
+// cached before the invalidation are never served again.
+func Invalidate(version string, scopes ...string) {
+	generations.mu.Lock()
+	defer generations.mu.Unlock()
+
+	if len(scopes) == 0 {
+		generations.all = version
+		return
+	}
+	for _, scope := range scopes {
+		generations.scopes[scope] = version
+	}
+}
+
+// Generation returns the generation of the cached results of the given scope.
+// It is empty until the scope or all scopes are invalidated.
+func Generation(scope string) string {
+	generations.mu.RLock()
+	defer generations.mu.RUnlock()
+
+	all, scoped := generations.all, generations.scopes[scope]
+	switch {
+	case all == "":
+		return scoped
+	case scoped == "":
+		return all
+	default:
+		return all + "+" + scoped
+	}
+}
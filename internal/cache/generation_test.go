@@ -0,0 +1,51 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import "testing"
+
+func TestInvalidate(t *testing.T) {
+	const (
+		scope      = "registry.example.com/team/*"
+		otherScope = "registry.example.com/other/*"
+	)
+	gen, otherGen := Generation(scope), Generation(otherScope)
+
+	Invalidate("v1", scope)
+	if Generation(scope) == gen {
+		t.Errorf("expected the generation of the invalidated scope to change")
+	}
+	if Generation(otherScope) != otherGen {
+		t.Errorf("expected the generation of other scopes to be kept")
+	}
+
+	// The generation is derived from the version, so that replicas reaching
+	// the same state agree on it.
+	gen = Generation(scope)
+	Invalidate("v1", scope)
+	if Generation(scope) != gen {
+		t.Errorf("expected the generation to be kept for the same version")
+	}
+
+	gen, otherGen = Generation(scope), Generation(otherScope)
+	Invalidate("v2")
+	if Generation(scope) == gen || Generation(otherScope) == otherGen {
+		t.Errorf("expected the generations of all scopes to change")
+	}
+	if Generation(scope) == Generation(otherScope) {
+		t.Errorf("expected the generations of scopes invalidated separately to differ")
+	}
+}
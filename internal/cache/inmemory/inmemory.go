@@ -17,7 +17,9 @@ package inmemory
 
 import (
 	"context"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/notaryproject/ratify/v2/internal/cache"
@@ -41,6 +43,9 @@ type Cache[T any] struct {
 	mu      sync.RWMutex
 	items   map[string]*cacheItem[T]
 	maxSize int
+	ttl     time.Duration
+	hits    atomic.Uint64
+	misses  atomic.Uint64
 }
 
 // NewCache creates a new in-memory cache with the specified TTL.
@@ -57,6 +62,20 @@ func NewCache[T any](maxSize int) (cache.Cache[T], error) {
 	}, nil
 }
 
+// NewCacheWithTTL creates a new in-memory cache with the specified max size
+// and default TTL, which applies to the values set without a TTL.
+func NewCacheWithTTL[T any](maxSize int, ttl time.Duration) (cache.Cache[T], error) {
+	if ttl < 0 {
+		return nil, cache.ErrInvalidTTL
+	}
+	c, err := NewCache[T](maxSize)
+	if err != nil {
+		return nil, err
+	}
+	c.(*Cache[T]).ttl = ttl
+	return c, nil
+}
+
 // Get returns the value associated with the key, or an error if not found.
 func (c *Cache[T]) Get(_ context.Context, key string) (T, error) {
 	c.mu.RLock()
@@ -65,20 +84,26 @@ func (c *Cache[T]) Get(_ context.Context, key string) (T, error) {
 	var zero T
 	item, exists := c.items[key]
 	if !exists {
+		c.misses.Add(1)
 		return zero, cache.ErrNotFound
 	}
 
 	if item.isExpired() {
 		// Item has expired, but we don't remove it here to avoid upgrading to
 		// write lock. It will be cleaned up on the next write operation.
+		c.misses.Add(1)
 		return zero, cache.ErrNotFound
 	}
 
+	c.hits.Add(1)
 	return item.value, nil
 }
 
 // Set stores a value with the specified key.
 func (c *Cache[T]) Set(_ context.Context, key string, value T, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.ttl // Use the cache's default TTL if none is provided
+	}
 	if ttl <= 0 {
 		return cache.ErrInvalidTTL
 	}
@@ -119,3 +144,40 @@ func (c *Cache[T]) Delete(_ context.Context, key string) error {
 	delete(c.items, key)
 	return nil
 }
+
+// DeletePrefix removes all key/values whose key starts with the prefix. Only
+// the entries that have not expired are counted.
+func (c *Cache[T]) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deleted int
+	for key, item := range c.items {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if !item.isExpired() {
+			deleted++
+		}
+		delete(c.items, key)
+	}
+	return deleted, nil
+}
+
+// Stats returns the statistics of the cache. Expired entries are not counted.
+func (c *Cache[T]) Stats(_ context.Context) (cache.Stats, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var entries int
+	for _, item := range c.items {
+		if !item.isExpired() {
+			entries++
+		}
+	}
+	return cache.Stats{
+		Entries: entries,
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+	}, nil
+}
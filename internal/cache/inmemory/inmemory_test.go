@@ -239,6 +239,72 @@ func TestCacheDeleteNonExistent(t *testing.T) {
 	}
 }
 
+func TestCacheDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache[string](10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	for _, key := range []string{"verify_app:v1", "verify_app:v2", "mutate_app:v1"} {
+		if err := c.Set(ctx, key, testValue, time.Hour); err != nil {
+			t.Fatalf("failed to set value: %v", err)
+		}
+	}
+	if err := c.Set(ctx, "verify_expired", testValue, time.Millisecond); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	deleted, err := c.DeletePrefix(ctx, "verify_")
+	if err != nil {
+		t.Fatalf("failed to delete prefix: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted entries, got %d", deleted)
+	}
+	if _, err := c.Get(ctx, "verify_app:v1"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := c.Get(ctx, "mutate_app:v1"); err != nil {
+		t.Errorf("expected entry not matching the prefix to be kept, got %v", err)
+	}
+
+	deleted, err = c.DeletePrefix(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to delete prefix: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted entry, got %d", deleted)
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache[string](10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := c.Set(ctx, testKey, testValue, time.Hour); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if err := c.Set(ctx, "expired", testValue, time.Millisecond); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	_, _ = c.Get(ctx, testKey)
+	_, _ = c.Get(ctx, "expired")
+	_, _ = c.Get(ctx, "non-existent-key")
+
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	expected := cache.Stats{Entries: 1, Hits: 1, Misses: 2}
+	if stats != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, stats)
+	}
+}
+
 func TestCacheCleanupExpiredItems(t *testing.T) {
 	ctx := context.Background()
 	c, err := NewCache[string](2) // Small max size to trigger cleanup
@@ -459,3 +525,24 @@ func TestCacheWithDifferentTypes(t *testing.T) {
 		t.Errorf("expected {Name: John, Age: 30}, got %+v", structVal)
 	}
 }
+
+func TestNewCacheWithTTL(t *testing.T) {
+	if _, err := NewCacheWithTTL[string](10, -time.Second); !errors.Is(err, cache.ErrInvalidTTL) {
+		t.Errorf("expected ErrInvalidTTL, got %v", err)
+	}
+	if _, err := NewCacheWithTTL[string](-1, time.Second); !errors.Is(err, cache.ErrInvalidMaxSize) {
+		t.Errorf("expected ErrInvalidMaxSize, got %v", err)
+	}
+
+	ctx := context.Background()
+	c, err := NewCacheWithTTL[string](10, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := c.Set(ctx, testKey, testValue, 0); err != nil {
+		t.Fatalf("expected the default TTL to apply, got %v", err)
+	}
+	if got, err := c.Get(ctx, testKey); err != nil || got != testValue {
+		t.Errorf("expected %s, got %s, %v", testValue, got, err)
+	}
+}
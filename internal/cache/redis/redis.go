@@ -0,0 +1,336 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultDialTimeout   = time.Second
+	defaultRetryInterval = 5 * time.Second
+
+	// scanCount is the number of keys requested per SCAN iteration.
+	scanCount = 1000
+)
+
+var logOpt = logger.Option{ComponentType: logger.Cache}
+
+// Serializer encodes the values stored in Redis and decodes them back.
+type Serializer[T any] interface {
+	// Marshal encodes the value.
+	Marshal(value T) ([]byte, error)
+
+	// Unmarshal decodes the value encoded by Marshal.
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONSerializer is a [Serializer] encoding values as JSON. Only the exported
+// fields of the values are kept.
+type JSONSerializer[T any] struct{}
+
+// Marshal encodes the value as JSON.
+func (JSONSerializer[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the JSON encoded value.
+func (JSONSerializer[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// Options configures the connection to Redis.
+type Options struct {
+	// Address is the host:port address of the Redis server.
+	// Required.
+	Address string
+
+	// Username is the username to authenticate with, if the server uses ACLs.
+	// Optional.
+	Username string
+
+	// Password is the password to authenticate with.
+	// Optional.
+	Password string
+
+	// DB is the database selected after connecting.
+	// Optional.
+	DB int
+
+	// TLSConfig is the TLS configuration of the connection. TLS is not used
+	// if not specified.
+	// Optional.
+	TLSConfig *tls.Config
+
+	// KeyPrefix is prepended to the keys of the cache, so that several caches
+	// can share a database.
+	// Optional.
+	KeyPrefix string
+
+	// DialTimeout is the timeout for establishing a connection, which is also
+	// the timeout of reads and writes. Default is 1 second if not specified.
+	// Optional.
+	DialTimeout time.Duration
+
+	// RetryInterval is the duration the local cache is used for after Redis
+	// is found unreachable, before Redis is tried again. Default is 5 seconds
+	// if not specified.
+	// Optional.
+	RetryInterval time.Duration
+}
+
+// Cache is a [cache.Cache] storing its entries in Redis, which shares them
+// between the replicas of the server. The entries are stored in the local
+// cache instead while Redis is unreachable, so that a Redis outage degrades
+// to per-replica caching rather than failing requests.
+type Cache[T any] struct {
+	client        goredis.UniversalClient
+	serializer    Serializer[T]
+	local         cache.Cache[T]
+	keyPrefix     string
+	ttl           time.Duration
+	retryInterval time.Duration
+
+	// unavailableUntil is the time in Unix nanoseconds until which Redis is
+	// considered unreachable and the local cache is used.
+	unavailableUntil atomic.Int64
+	hits             atomic.Uint64
+	misses           atomic.Uint64
+}
+
+// NewCache creates a new Redis cache with the specified default TTL. Values
+// are encoded by the serializer, and stored in the local cache while Redis is
+// unreachable.
+func NewCache[T any](opts Options, ttl time.Duration, serializer Serializer[T], local cache.Cache[T]) (cache.Cache[T], error) {
+	if ttl < 0 {
+		return nil, cache.ErrInvalidTTL
+	}
+	if opts.Address == "" {
+		return nil, errors.New("redis address is required")
+	}
+	if serializer == nil || local == nil {
+		return nil, errors.New("serializer and local cache are required")
+	}
+	dialTimeout := opts.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = defaultDialTimeout
+	}
+	retryInterval := opts.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = defaultRetryInterval
+	}
+	client := goredis.NewClient(&goredis.Options{
+		Addr:         opts.Address,
+		Username:     opts.Username,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		TLSConfig:    opts.TLSConfig,
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  dialTimeout,
+		WriteTimeout: dialTimeout,
+		// Failures are handled by falling back to the local cache, which is
+		// preferred over retrying against an unreachable server.
+		MaxRetries: -1,
+	})
+	return &Cache[T]{
+		client:        client,
+		serializer:    serializer,
+		local:         local,
+		keyPrefix:     opts.KeyPrefix,
+		ttl:           ttl,
+		retryInterval: retryInterval,
+	}, nil
+}
+
+// Get returns the value associated with the key, or an error if not found.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+	if !c.available() {
+		value, err := c.local.Get(ctx, key)
+		c.count(err == nil)
+		return value, err
+	}
+
+	data, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			c.count(false)
+			return zero, cache.ErrNotFound
+		}
+		c.fail(ctx, err)
+		value, err := c.local.Get(ctx, key)
+		c.count(err == nil)
+		return value, err
+	}
+	value, err := c.serializer.Unmarshal(data)
+	if err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to decode cached value of %s: %v", key, err)
+		c.count(false)
+		return zero, cache.ErrNotFound
+	}
+	c.count(true)
+	return value, nil
+}
+
+// Set stores a value with the specified key. The default TTL of the cache is
+// used if ttl is not positive.
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	if !c.available() {
+		return c.local.Set(ctx, key, value, ttl)
+	}
+
+	data, err := c.serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, c.keyPrefix+key, data, ttl).Err(); err != nil {
+		c.fail(ctx, err)
+		return c.local.Set(ctx, key, value, ttl)
+	}
+	return nil
+}
+
+// Delete removes the specified key/value from the cache. The key is removed
+// from both Redis and the local cache, even if Redis is considered
+// unreachable, so that the entry is not served by either of them.
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	if err := c.local.Delete(ctx, key); err != nil {
+		return err
+	}
+	if err := c.client.Del(ctx, c.keyPrefix+key).Err(); err != nil {
+		c.fail(ctx, err)
+		return err
+	}
+	return nil
+}
+
+// DeletePrefix removes all key/values whose key starts with the prefix from
+// both Redis and the local cache.
+func (c *Cache[T]) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	deleted, err := c.local.DeletePrefix(ctx, prefix)
+	if err != nil {
+		return deleted, err
+	}
+	var redisDeleted int
+	err = c.scan(ctx, prefix, func(keys []string) error {
+		n, err := c.client.Del(ctx, keys...).Result()
+		redisDeleted += int(n)
+		return err
+	})
+	if err != nil {
+		c.fail(ctx, err)
+		return deleted, err
+	}
+	return deleted + redisDeleted, nil
+}
+
+// Stats returns the statistics of the cache. Entries are counted in Redis, or
+// in the local cache while Redis is unreachable.
+func (c *Cache[T]) Stats(ctx context.Context) (cache.Stats, error) {
+	stats := cache.Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if !c.available() {
+		local, err := c.local.Stats(ctx)
+		stats.Entries = local.Entries
+		return stats, err
+	}
+	err := c.scan(ctx, "", func(keys []string) error {
+		stats.Entries += len(keys)
+		return nil
+	})
+	if err != nil {
+		c.fail(ctx, err)
+		return cache.Stats{}, err
+	}
+	return stats, nil
+}
+
+// scan calls fn with the batches of Redis keys of the cache starting with the
+// prefix.
+func (c *Cache[T]) scan(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	match := escapePattern(c.keyPrefix+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, match, scanCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// available returns whether Redis is expected to be reachable.
+func (c *Cache[T]) available() bool {
+	return time.Now().UnixNano() >= c.unavailableUntil.Load()
+}
+
+// fail records a failed Redis command. Redis is considered unreachable for
+// the retry interval unless the command was cancelled by the caller.
+func (c *Cache[T]) fail(ctx context.Context, err error) {
+	if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+		return
+	}
+	until := time.Now().Add(c.retryInterval).UnixNano()
+	if previous := c.unavailableUntil.Swap(until); time.Now().UnixNano() >= previous {
+		logger.GetLogger(ctx, logOpt).Warnf("redis cache unavailable, falling back to the local cache for %v: %v", c.retryInterval, err)
+	}
+}
+
+func (c *Cache[T]) count(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// escapePattern escapes the special characters of Redis glob-style patterns.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,414 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
+)
+
+type testEntry struct {
+	value      string
+	expiration time.Time
+}
+
+// testServer is an in-process stand-in for a Redis server, speaking the RESP2
+// protocol and supporting the commands used by the cache.
+type testServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	entries  map[string]testEntry
+	conns    map[net.Conn]struct{}
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &testServer{
+		listener: listener,
+		entries:  make(map[string]testEntry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+func (s *testServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+// close stops the server and drops its connections.
+func (s *testServer) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *testServer) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command: %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func (s *testServer) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "CLIENT", "SELECT", "AUTH":
+		return "+OK\r\n"
+	case "GET":
+		entry, ok := s.entries[args[1]]
+		if !ok || (!entry.expiration.IsZero() && time.Now().After(entry.expiration)) {
+			return "$-1\r\n"
+		}
+		return bulkString(entry.value)
+	case "SET":
+		entry := testEntry{value: args[2]}
+		for i := 3; i+1 < len(args); i += 2 {
+			n, _ := strconv.Atoi(args[i+1])
+			switch strings.ToUpper(args[i]) {
+			case "PX":
+				entry.expiration = time.Now().Add(time.Duration(n) * time.Millisecond)
+			case "EX":
+				entry.expiration = time.Now().Add(time.Duration(n) * time.Second)
+			}
+		}
+		s.entries[args[1]] = entry
+		return "+OK\r\n"
+	case "DEL":
+		var deleted int
+		for _, key := range args[1:] {
+			if _, ok := s.entries[key]; ok {
+				delete(s.entries, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "SCAN":
+		// All keys are returned in a single iteration.
+		var match string
+		for i := 2; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				match = args[i+1]
+			}
+		}
+		var reply strings.Builder
+		var keys []string
+		for key := range s.entries {
+			if matchPrefixPattern(match, key) {
+				keys = append(keys, key)
+			}
+		}
+		fmt.Fprintf(&reply, "*2\r\n%s*%d\r\n", bulkString("0"), len(keys))
+		for _, key := range keys {
+			reply.WriteString(bulkString(key))
+		}
+		return reply.String()
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// matchPrefixPattern matches the key against an escaped prefix followed by
+// "*", which are the only patterns used by the cache.
+func matchPrefixPattern(pattern, key string) bool {
+	if pattern == "" {
+		return true
+	}
+	var prefix strings.Builder
+	for i := 0; i < len(pattern)-1; i++ {
+		if pattern[i] == '\\' {
+			i++
+		}
+		prefix.WriteByte(pattern[i])
+	}
+	return strings.HasPrefix(key, prefix.String())
+}
+
+func bulkString(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+type testValue struct {
+	Name  string
+	Count int
+}
+
+func newTestCache(t *testing.T, addr string) (cache.Cache[testValue], cache.Cache[testValue]) {
+	t.Helper()
+	local, err := inmemory.NewCacheWithTTL[testValue](10, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	c, err := NewCache(Options{
+		Address:       addr,
+		KeyPrefix:     "test:",
+		DialTimeout:   100 * time.Millisecond,
+		RetryInterval: time.Hour,
+	}, time.Minute, JSONSerializer[testValue]{}, local)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	return c, local
+}
+
+func TestNewCache(t *testing.T) {
+	local, err := inmemory.NewCache[string](10)
+	if err != nil {
+		t.Fatalf("failed to create local cache: %v", err)
+	}
+	tests := []struct {
+		name       string
+		opts       Options
+		ttl        time.Duration
+		serializer Serializer[string]
+		local      cache.Cache[string]
+		wantErr    bool
+	}{
+		{name: "valid", opts: Options{Address: "localhost:6379"}, serializer: JSONSerializer[string]{}, local: local},
+		{name: "negative TTL", opts: Options{Address: "localhost:6379"}, ttl: -time.Second, serializer: JSONSerializer[string]{}, local: local, wantErr: true},
+		{name: "no address", serializer: JSONSerializer[string]{}, local: local, wantErr: true},
+		{name: "no serializer", opts: Options{Address: "localhost:6379"}, local: local, wantErr: true},
+		{name: "no local cache", opts: Options{Address: "localhost:6379"}, serializer: JSONSerializer[string]{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCache(tt.opts, tt.ttl, tt.serializer, tt.local)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %t, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	server := newTestServer(t)
+	c, local := newTestCache(t, server.addr())
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	value := testValue{Name: "value", Count: 1}
+	if err := c.Set(ctx, "key", value, 0); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != value {
+		t.Errorf("expected %+v, got %+v, %v", value, got, err)
+	}
+	if keys := server.keys(); len(keys) != 1 || keys[0] != "test:key" {
+		t.Errorf("expected the prefixed key to be stored in redis, got %v", keys)
+	}
+	if _, err := local.Get(ctx, "key"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected the local cache not to be used while redis is reachable, got %v", err)
+	}
+
+	if err := c.Set(ctx, "expiring", value, 10*time.Millisecond); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := c.Get(ctx, "expiring"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected expired value to be gone, got %v", err)
+	}
+
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatalf("failed to delete value: %v", err)
+	}
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("expected 1 hit and 3 misses, got %+v", stats)
+	}
+}
+
+func TestCache_DeletePrefix(t *testing.T) {
+	server := newTestServer(t)
+	c, _ := newTestCache(t, server.addr())
+	ctx := context.Background()
+
+	for _, key := range []string{"verify_a*1", "verify_a*2", "verify_ab", "mutate_a"} {
+		if err := c.Set(ctx, key, testValue{Name: key}, 0); err != nil {
+			t.Fatalf("failed to set value: %v", err)
+		}
+	}
+	deleted, err := c.DeletePrefix(ctx, "verify_a*")
+	if err != nil {
+		t.Fatalf("failed to delete prefix: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted entries, got %d", deleted)
+	}
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if stats.Entries != 2 {
+		t.Errorf("expected 2 entries, got %d", stats.Entries)
+	}
+	if deleted, err := c.DeletePrefix(ctx, ""); err != nil || deleted != 2 {
+		t.Errorf("expected all 2 entries to be deleted, got %d, %v", deleted, err)
+	}
+}
+
+func TestCache_Fallback(t *testing.T) {
+	server := newTestServer(t)
+	c, local := newTestCache(t, server.addr())
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "shared", testValue{Name: "shared"}, 0); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	server.close()
+
+	value := testValue{Name: "local"}
+	if err := c.Set(ctx, "key", value, 0); err != nil {
+		t.Fatalf("expected the value to be stored in the local cache, got %v", err)
+	}
+	if got, err := local.Get(ctx, "key"); err != nil || got != value {
+		t.Errorf("expected the value in the local cache, got %+v, %v", got, err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != value {
+		t.Errorf("expected %+v from the local cache, got %+v, %v", value, got, err)
+	}
+	if _, err := c.Get(ctx, "shared"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected values only stored in redis to be missing, got %v", err)
+	}
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	if stats.Entries != 1 {
+		t.Errorf("expected the entries of the local cache, got %d", stats.Entries)
+	}
+	if _, err := c.DeletePrefix(ctx, ""); err == nil {
+		t.Errorf("expected purging an unreachable redis to fail")
+	}
+	if _, err := local.Get(ctx, "key"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected the local cache to be purged, got %v", err)
+	}
+}
+
+func TestJSONSerializer(t *testing.T) {
+	serializer := JSONSerializer[*testValue]{}
+	data, err := serializer.Marshal(&testValue{Name: "value", Count: 2})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	value, err := serializer.Unmarshal(data)
+	if err != nil || value == nil || *value != (testValue{Name: "value", Count: 2}) {
+		t.Errorf("expected round-tripped value, got %+v, %v", value, err)
+	}
+	if _, err := serializer.Unmarshal([]byte("invalid")); err == nil {
+		t.Errorf("expected error for invalid data")
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	if got := escapePattern(`a*b?c[d]e\f`); got != `a\*b\?c\[d\]e\\f` {
+		t.Errorf("unexpected escaped pattern: %s", got)
+	}
+}
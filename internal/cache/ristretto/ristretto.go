@@ -17,9 +17,13 @@ package ristretto
 
 import (
 	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
+	"github.com/dgraph-io/ristretto/v2/z"
 	"github.com/notaryproject/ratify/v2/internal/cache"
 	"github.com/sirupsen/logrus"
 )
@@ -32,36 +36,70 @@ const (
 type Cache[T any] struct {
 	cache *ristretto.Cache[string, T]
 	ttl   time.Duration
+
+	// keys tracks the keys stored in the cache by their hash, as ristretto
+	// does not keep the keys. Keys are forgotten when they are deleted or
+	// evicted, and the keys of expired entries not yet cleaned up by
+	// ristretto are ignored.
+	keysMu sync.Mutex
+	keys   map[uint64]string
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
 // NewCache creates a new Ristretto cache with the specified TTL.
 func NewCache[T any](ttl time.Duration) (cache.Cache[T], error) {
-	if ttl < 0 {
-		return nil, cache.ErrInvalidTTL
-	}
-
-	memoryCache, err := ristretto.NewCache(&ristretto.Config[string, T]{
+	return newCache(ttl, &ristretto.Config[string, T]{
 		NumCounters: defaultCountNum, // number of keys to track frequency.
 		MaxCost:     defaultMaxSize,  // Max size in Megabytes.
 		BufferItems: 64,              // number of keys per Get buffer. 64 is recommended by the ristretto library.
 	})
+}
+
+// NewCacheWithMaxCost creates a new Ristretto cache with the specified TTL and
+// maximum cost. Every entry costs 1, so maxCost is the maximum number of
+// entries kept in the cache.
+func NewCacheWithMaxCost[T any](ttl time.Duration, maxCost int64) (cache.Cache[T], error) {
+	if maxCost <= 0 {
+		return nil, cache.ErrInvalidMaxSize
+	}
+	return newCache(ttl, &ristretto.Config[string, T]{
+		NumCounters:        min(10*maxCost, defaultCountNum), // 10x the number of entries is recommended by the ristretto library.
+		MaxCost:            maxCost,
+		BufferItems:        64,
+		IgnoreInternalCost: true,
+	})
+}
+
+func newCache[T any](ttl time.Duration, config *ristretto.Config[string, T]) (cache.Cache[T], error) {
+	if ttl < 0 {
+		return nil, cache.ErrInvalidTTL
+	}
+
+	c := &Cache[T]{
+		ttl:  ttl,
+		keys: make(map[uint64]string),
+	}
+	config.OnEvict = func(item *ristretto.Item[T]) {
+		c.forget(item.Key)
+	}
+	memoryCache, err := ristretto.NewCache(config)
 	if err != nil {
 		logrus.Errorf("could not create ristretto cache, err: %s", err)
 		return nil, err
 	}
-
-	return &Cache[T]{
-		cache: memoryCache,
-		ttl:   ttl,
-	}, nil
+	c.cache = memoryCache
+	return c, nil
 }
 
 // Get returns the value associated with the key, or an error if not found.
 func (r *Cache[T]) Get(_ context.Context, key string) (T, error) {
 	cacheValue, found := r.cache.Get(key)
 	if found {
+		r.hits.Add(1)
 		return cacheValue, nil
 	}
+	r.misses.Add(1)
 	var zero T
 	return zero, cache.ErrNotFound
 }
@@ -74,6 +112,7 @@ func (r *Cache[T]) Set(_ context.Context, key string, value T, ttl time.Duration
 	saved := r.cache.SetWithTTL(key, value, 1, ttl)
 	r.cache.Wait()
 	if saved {
+		r.track(key)
 		return nil
 	}
 	return cache.ErrAddFailed
@@ -84,5 +123,74 @@ func (r *Cache[T]) Delete(_ context.Context, key string) error {
 	r.cache.Del(key)
 	// Note: ristretto does not return a bool for delete.
 	// Delete ops are eventually consistent and we don't want to block on them.
+	r.forget(keyHash(key))
 	return nil
 }
+
+// DeletePrefix removes all key/values whose key starts with the prefix. Only
+// the entries that have not expired are counted.
+func (r *Cache[T]) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	var deleted int
+	for _, key := range r.trackedKeys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if _, found := r.cache.GetTTL(key); found {
+			deleted++
+		}
+		r.cache.Del(key)
+		r.forget(keyHash(key))
+	}
+	return deleted, nil
+}
+
+// Stats returns the statistics of the cache. Expired entries are not counted.
+func (r *Cache[T]) Stats(_ context.Context) (cache.Stats, error) {
+	var entries int
+	for _, key := range r.trackedKeys() {
+		if _, found := r.cache.GetTTL(key); found {
+			entries++
+		}
+	}
+	return cache.Stats{
+		Entries: entries,
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+	}, nil
+}
+
+// track records the key stored in the cache.
+func (r *Cache[T]) track(key string) {
+	r.keysMu.Lock()
+	defer r.keysMu.Unlock()
+
+	r.keys[keyHash(key)] = key
+}
+
+// forget drops the key of the hash. It must not be called while holding
+// keysMu, as ristretto calls it on eviction.
+func (r *Cache[T]) forget(hash uint64) {
+	r.keysMu.Lock()
+	defer r.keysMu.Unlock()
+
+	delete(r.keys, hash)
+}
+
+// trackedKeys returns a snapshot of the keys stored in the cache, so that
+// ristretto is not called while holding keysMu.
+func (r *Cache[T]) trackedKeys() []string {
+	r.keysMu.Lock()
+	defer r.keysMu.Unlock()
+
+	keys := make([]string, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// keyHash returns the hash ristretto stores the key by.
+func keyHash(key string) uint64 {
+	hash, _ := z.KeyToHash(key)
+	return hash
+}
@@ -79,6 +79,35 @@ func TestNewRistrettoCache(t *testing.T) {
 	}
 }
 
+func TestNewCacheWithMaxCost(t *testing.T) {
+	if _, err := NewCacheWithMaxCost[string](time.Second, 0); !errors.Is(err, cache.ErrInvalidMaxSize) {
+		t.Errorf("expected error %v, got %v", cache.ErrInvalidMaxSize, err)
+	}
+	if _, err := NewCacheWithMaxCost[string](-time.Second, 10); !errors.Is(err, cache.ErrInvalidTTL) {
+		t.Errorf("expected error %v, got %v", cache.ErrInvalidTTL, err)
+	}
+
+	c, err := NewCacheWithMaxCost[string](time.Minute, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+	if err := c.Set(ctx, testKey, testValue, 0); err != nil {
+		t.Fatalf("failed to set the first entry: %v", err)
+	}
+	if value, err := c.Get(ctx, testKey); err != nil || value != testValue {
+		t.Fatalf("expected the first entry to be cached, got %q, %v", value, err)
+	}
+	// The cache is full, so the second entry either is rejected or evicts
+	// the first one.
+	_ = c.Set(ctx, "otherKey", testValue, 0)
+	_, firstErr := c.Get(ctx, testKey)
+	_, secondErr := c.Get(ctx, "otherKey")
+	if firstErr == nil && secondErr == nil {
+		t.Errorf("expected at most 1 entry in the cache")
+	}
+}
+
 func TestRistrettoCacheGet(t *testing.T) {
 	cacheInstance, err := NewCache[string](1 * time.Second)
 	if err != nil {
@@ -231,6 +260,69 @@ func TestRistrettoCacheDelete(t *testing.T) {
 	}
 }
 
+func TestRistrettoCacheDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache[string](time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	for _, key := range []string{"verify_app:v1", "verify_app:v2", "mutate_app:v1"} {
+		if err := c.Set(ctx, key, testValue, 0); err != nil {
+			t.Fatalf("failed to set value: %v", err)
+		}
+	}
+
+	deleted, err := c.DeletePrefix(ctx, "verify_")
+	if err != nil {
+		t.Fatalf("failed to delete prefix: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted entries, got %d", deleted)
+	}
+	if _, err := c.Get(ctx, "verify_app:v1"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := c.Get(ctx, "mutate_app:v1"); err != nil {
+		t.Errorf("expected entry not matching the prefix to be kept, got %v", err)
+	}
+
+	deleted, err = c.DeletePrefix(ctx, "")
+	if err != nil {
+		t.Fatalf("failed to delete prefix: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 deleted entry, got %d", deleted)
+	}
+}
+
+func TestRistrettoCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewCache[string](time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := c.Set(ctx, testKey, testValue, 0); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if err := c.Set(ctx, "deleted", testValue, 0); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if err := c.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("failed to delete key: %v", err)
+	}
+	_, _ = c.Get(ctx, testKey)
+	_, _ = c.Get(ctx, "non-existent-key")
+
+	stats, err := c.Stats(ctx)
+	if err != nil {
+		t.Fatalf("failed to get stats: %v", err)
+	}
+	expected := cache.Stats{Entries: 1, Hits: 1, Misses: 1}
+	if stats != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, stats)
+	}
+}
+
 func TestRistrettoCacheExpiration(t *testing.T) {
 	cacheInstance, err := NewCache[string](100 * time.Millisecond)
 	if err != nil {
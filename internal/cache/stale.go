@@ -0,0 +1,106 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// StaleEntry is an entry of a [StaleCache] stored in the underlying cache.
+type StaleEntry[T any] struct {
+	// Value is the cached value.
+	Value T
+
+	// SoftExpiry is the time after which the entry is stale.
+	SoftExpiry time.Time
+}
+
+// StaleCache is a cache whose entries have a soft and a hard TTL. An entry is
+// fresh until its soft TTL expires, which is the TTL it is set with, and
+// stale until its hard TTL expires, which is the soft TTL plus the grace
+// period. [StaleCache.Get] only returns fresh entries while
+// [StaleCache.GetStale] returns stale entries as well, so that callers can
+// serve a stale entry while refreshing it.
+type StaleCache[T any] struct {
+	cache      Cache[StaleEntry[T]]
+	defaultTTL time.Duration
+	grace      time.Duration
+}
+
+// NewStaleCache creates a cache storing its entries in the given cache.
+// Entries set without a TTL get the default TTL as soft TTL. Stale entries are
+// kept for the grace period after their soft TTL expired.
+func NewStaleCache[T any](cache Cache[StaleEntry[T]], defaultTTL, grace time.Duration) (*StaleCache[T], error) {
+	if defaultTTL <= 0 || grace < 0 {
+		return nil, ErrInvalidTTL
+	}
+	return &StaleCache[T]{
+		cache:      cache,
+		defaultTTL: defaultTTL,
+		grace:      grace,
+	}, nil
+}
+
+// Get returns the value associated with the key, or an error if not found or
+// stale.
+func (c *StaleCache[T]) Get(ctx context.Context, key string) (T, error) {
+	value, stale, err := c.GetStale(ctx, key)
+	if err != nil || stale {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return value, nil
+}
+
+// GetStale returns the value associated with the key and whether its soft TTL
+// expired, or an error if not found or its hard TTL expired.
+func (c *StaleCache[T]) GetStale(ctx context.Context, key string) (T, bool, error) {
+	entry, err := c.cache.Get(ctx, key)
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	return entry.Value, time.Now().After(entry.SoftExpiry), nil
+}
+
+// Set stores a value with the specified key. The ttl is the soft TTL of the
+// entry, or the default TTL if not positive.
+func (c *StaleCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	return c.cache.Set(ctx, key, StaleEntry[T]{
+		Value:      value,
+		SoftExpiry: time.Now().Add(ttl),
+	}, ttl+c.grace)
+}
+
+// Delete removes the specified key/value from the cache.
+func (c *StaleCache[T]) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// DeletePrefix removes all key/values whose key starts with the prefix.
+func (c *StaleCache[T]) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	return c.cache.DeletePrefix(ctx, prefix)
+}
+
+// Stats returns the statistics of the underlying cache, which counts stale
+// entries as well.
+func (c *StaleCache[T]) Stats(ctx context.Context) (Stats, error) {
+	return c.cache.Stats(ctx)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ttlEntry[T any] struct {
+	value      T
+	expiration time.Time
+}
+
+// ttlCache is a minimal cache honoring the TTL of its entries.
+type ttlCache[T any] struct {
+	entries map[string]ttlEntry[T]
+}
+
+func (c *ttlCache[T]) Get(_ context.Context, key string) (T, error) {
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiration) {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return entry.value, nil
+}
+
+func (c *ttlCache[T]) Set(_ context.Context, key string, value T, ttl time.Duration) error {
+	c.entries[key] = ttlEntry[T]{value: value, expiration: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *ttlCache[T]) Delete(_ context.Context, key string) error {
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *ttlCache[T]) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	var deleted int
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *ttlCache[T]) Stats(_ context.Context) (Stats, error) {
+	return Stats{Entries: len(c.entries)}, nil
+}
+
+func TestNewStaleCache(t *testing.T) {
+	underlying := &ttlCache[StaleEntry[string]]{entries: make(map[string]ttlEntry[StaleEntry[string]])}
+	if _, err := NewStaleCache(underlying, 0, time.Second); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("expected ErrInvalidTTL for zero default TTL, got %v", err)
+	}
+	if _, err := NewStaleCache(underlying, time.Second, -time.Second); !errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("expected ErrInvalidTTL for negative grace period, got %v", err)
+	}
+}
+
+func TestStaleCache(t *testing.T) {
+	ctx := context.Background()
+	underlying := &ttlCache[StaleEntry[string]]{entries: make(map[string]ttlEntry[StaleEntry[string]])}
+	c, err := NewStaleCache(underlying, time.Hour, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := c.Set(ctx, "key", "value", 20*time.Millisecond); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+
+	// Fresh until the soft TTL expires.
+	if value, err := c.Get(ctx, "key"); err != nil || value != "value" {
+		t.Errorf("expected fresh value, got %q, %v", value, err)
+	}
+	if value, stale, err := c.GetStale(ctx, "key"); err != nil || stale || value != "value" {
+		t.Errorf("expected fresh value, got %q, stale %t, %v", value, stale, err)
+	}
+
+	// Stale until the hard TTL expires.
+	time.Sleep(30 * time.Millisecond)
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for stale value, got %v", err)
+	}
+	if value, stale, err := c.GetStale(ctx, "key"); err != nil || !stale || value != "value" {
+		t.Errorf("expected stale value, got %q, stale %t, %v", value, stale, err)
+	}
+
+	// Gone after the hard TTL.
+	time.Sleep(50 * time.Millisecond)
+	if _, _, err := c.GetStale(ctx, "key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after the hard TTL, got %v", err)
+	}
+
+	// The default TTL is the soft TTL of entries set without a TTL.
+	if err := c.Set(ctx, "default", "value", 0); err != nil {
+		t.Fatalf("failed to set value: %v", err)
+	}
+	if expiration := underlying.entries["default"].expiration; time.Until(expiration) <= time.Hour {
+		t.Errorf("expected the hard TTL to extend the default TTL, got expiration %v", expiration)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -59,6 +60,7 @@ func (r *ExecutorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			if err := GlobalExecutorManager.deleteExecutor(req.Namespace, req.Name); err != nil {
 				log.Error(err, "Failed to delete Executor from GlobalExecutorManager", "executor", req.Name)
 			}
+			r.updateChangedStatuses(ctx, req.NamespacedName)
 		} else {
 			log.Error(err, "Failed to get Executor", "executor", req.Name)
 		}
@@ -70,7 +72,8 @@ func (r *ExecutorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Error(err, "Failed to upsert Executor", "executor", req.Name)
 	}
 
-	r.updateStatus(ctx, &executor, err)
+	r.updateStatus(ctx, &executor, GlobalExecutorManager.getStatus(req.Namespace, req.Name))
+	r.updateChangedStatuses(ctx, req.NamespacedName)
 	return ctrl.Result{}, nil
 }
 
@@ -81,16 +84,28 @@ func (r *ExecutorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *ExecutorReconciler) updateStatus(ctx context.Context, executor *configv2alpha1.Executor, err error) {
-	if err != nil {
-		executor.Status.Succeeded = false
-		executor.Status.Error = err.Error()
-	} else {
-		executor.Status.Succeeded = true
-		executor.Status.Error = ""
-	}
+func (r *ExecutorReconciler) updateStatus(ctx context.Context, executor *configv2alpha1.Executor, status configv2alpha1.ExecutorStatus) {
+	executor.Status = status
 	if statusErr := r.Status().Update(ctx, executor); statusErr != nil {
 		log := logf.FromContext(ctx)
 		log.Error(statusErr, "Failed to update Executor status", "executor", executor.Name)
 	}
 }
+
+// updateChangedStatuses updates the status of other Executor resources affected
+// by reconciling the current one, e.g. when a scope conflict between them is
+// introduced or resolved.
+func (r *ExecutorReconciler) updateChangedStatuses(ctx context.Context, current types.NamespacedName) {
+	log := logf.FromContext(ctx)
+	for _, namespacedName := range GlobalExecutorManager.takeStatusChanges() {
+		if namespacedName == current {
+			continue
+		}
+		var executor configv2alpha1.Executor
+		if err := r.Get(ctx, namespacedName, &executor); err != nil {
+			log.Error(err, "Failed to get Executor", "executor", namespacedName.Name)
+			continue
+		}
+		r.updateStatus(ctx, &executor, GlobalExecutorManager.getStatus(namespacedName.Namespace, namespacedName.Name))
+	}
+}
@@ -17,9 +17,16 @@ limitations under the License.
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
 
 	configv2alpha1 "github.com/notaryproject/ratify/v2/api/v2alpha1"
 	e "github.com/notaryproject/ratify/v2/internal/executor"
@@ -28,12 +35,45 @@ import (
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 )
 
+// maxBriefErrorLength is the maximum length of the brief error reported in the
+// status of an Executor resource.
+const maxBriefErrorLength = 30
+
 // executorManager manages the lifecycle of executor instances across different
 // namespaces and names.
+//
+// Each Executor resource is built into its own [e.Unit]. Units are reused
+// until the options of their resource change, so that updating one resource
+// does not recreate the verifiers, stores and key providers of the others.
+//
+// Resources are isolated from each other. A resource that fails to build is
+// excluded while all other resources are still served. If resources register
+// the same scope, the resource created first, or the first by namespace and
+// name if created at the same time, keeps serving the scope regardless of the
+// order they are reconciled in. The other one is excluded, and the conflict is
+// reported on both.
+//
+// Resources marked as shadow are built into a separate shadow executor, which
+// is compared against the active executor but never decides the outcome.
+// Shadow resources only conflict with other shadow resources.
 type executorManager struct {
-	mutex    sync.Mutex
-	opts     map[string]e.ScopedOptions
-	executor atomic.Pointer[e.ScopedExecutor]
+	mutex          sync.Mutex
+	opts           map[string]e.ScopedOptions
+	units          map[string]*e.Unit
+	failures       map[string]error
+	shadows        map[string]bool
+	executor       atomic.Pointer[e.ScopedExecutor]
+	shadowExecutor atomic.Pointer[e.ScopedExecutor]
+
+	// created records the creation timestamp of each resource, which decides
+	// the resource keeping a conflicting scope.
+	created map[string]time.Time
+
+	// statuses holds the status of each resource as of the last refresh, and
+	// changed holds the resources whose status changed since the last call
+	// to takeStatusChanges.
+	statuses map[string]configv2alpha1.ExecutorStatus
+	changed  map[string]struct{}
 }
 
 // GlobalExecutorManager is an instance of executorManager that is used by
@@ -52,8 +92,41 @@ func (m *executorManager) GetExecutor() *e.ScopedExecutor {
 	return m.executor.Load()
 }
 
+// GetShadowExecutor returns the current shadow executor instance in concurrent
+// safe manner. It returns nil if no shadow executor is set.
+func (m *executorManager) GetShadowExecutor() *e.ScopedExecutor {
+	return m.shadowExecutor.Load()
+}
+
+// GetErrors returns the errors of the executors excluded from serving as of
+// the last refresh, keyed by the name of the Executor resource. It returns nil
+// if all executors are served.
+func (m *executorManager) GetErrors() map[string]error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var errs map[string]error
+	for key, status := range m.statuses {
+		if status.Succeeded {
+			continue
+		}
+		if errs == nil {
+			errs = make(map[string]error)
+		}
+		if err, failed := m.failures[key]; failed {
+			errs[displayName(key)] = err
+		} else {
+			errs[displayName(key)] = errors.New(status.Error)
+		}
+	}
+	return errs
+}
+
 // upsertExecutor updates or inserts an executor instance under the given
-// namespace and name.
+// namespace and name. The components of the executor are only recreated if
+// its options changed. It returns an error if the executor is excluded from
+// serving, either because it cannot be created or because its scopes conflict
+// with another executor.
 func (m *executorManager) upsertExecutor(namespace, name string, opts *configv2alpha1.Executor) error {
 	if opts == nil {
 		return fmt.Errorf("executor options cannot be nil")
@@ -61,15 +134,43 @@ func (m *executorManager) upsertExecutor(namespace, name string, opts *configv2a
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	key := createOptsKey(namespace, name)
+	if m.created == nil {
+		m.created = make(map[string]time.Time)
+	}
+	m.created[key] = opts.CreationTimestamp.Time
+	if m.shadows == nil {
+		m.shadows = make(map[string]bool)
+	}
+	m.shadows[key] = opts.Spec.Shadow
+
 	scopedOpts, err := convertOptions(opts)
 	if err != nil {
-		return err
+		delete(m.opts, key)
+		m.setFailure(key, err)
+	} else if existing, ok := m.opts[key]; !ok || !reflect.DeepEqual(existing, scopedOpts) || m.units[key] == nil {
+		// Only rebuild the components of the changed resource.
+		m.opts[key] = scopedOpts
+		unit, err := e.NewUnit(scopedOpts)
+		if err != nil {
+			m.setFailure(key, fmt.Errorf("failed to create executor: %w", err))
+		} else {
+			if m.units == nil {
+				m.units = make(map[string]*e.Unit)
+			}
+			m.closeUnit(key)
+			m.units[key] = unit
+			delete(m.failures, key)
+		}
 	}
 
-	key := createOptsKey(namespace, name)
-	m.opts[key] = scopedOpts
-
-	return m.refreshExecutor()
+	if err = m.refreshExecutor(); err != nil {
+		return err
+	}
+	if status := m.statuses[key]; !status.Succeeded {
+		return errors.New(status.Error)
+	}
+	return nil
 }
 
 // deleteExecutor removes an executor instance under the given namespace and
@@ -79,38 +180,184 @@ func (m *executorManager) deleteExecutor(namespace, name string) error {
 	defer m.mutex.Unlock()
 
 	key := createOptsKey(namespace, name)
-	if _, exists := m.opts[key]; exists {
+	if _, exists := m.created[key]; exists {
 		delete(m.opts, key)
+		m.closeUnit(key)
+		delete(m.failures, key)
+		delete(m.shadows, key)
+		delete(m.created, key)
 		return m.refreshExecutor()
 	}
 	return fmt.Errorf("executor resource: %s/%s is not found", namespace, name)
 }
 
-// refreshExecutor creates a new executor instance based on the current options.
+// getStatus returns the status of the executor under the given namespace and
+// name as of the last refresh.
+func (m *executorManager) getStatus(namespace, name string) configv2alpha1.ExecutorStatus {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.statuses[createOptsKey(namespace, name)]
+}
+
+// takeStatusChanges returns the namespaces and names of the executors whose
+// status changed since the last call.
+func (m *executorManager) takeStatusChanges() []types.NamespacedName {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	changes := make([]types.NamespacedName, 0, len(m.changed))
+	for key := range m.changed {
+		namespace, name, _ := strings.Cut(key, "/")
+		changes = append(changes, types.NamespacedName{
+			Namespace: namespace,
+			Name:      name,
+		})
+	}
+	m.changed = nil
+	return changes
+}
+
+// setFailure excludes the executor under the given key because it cannot be
+// created.
+func (m *executorManager) setFailure(key string, err error) {
+	m.closeUnit(key)
+	if m.failures == nil {
+		m.failures = make(map[string]error)
+	}
+	m.failures[key] = err
+}
+
+// closeUnit closes and removes the unit of the executor under the given key,
+// if any.
+func (m *executorManager) closeUnit(key string) {
+	if unit, ok := m.units[key]; ok {
+		unit.Close()
+		delete(m.units, key)
+	}
+}
+
+// refreshExecutor creates new active and shadow executor instances from the
+// units of all executors that can be served, and updates the status of every
+// executor.
 func (m *executorManager) refreshExecutor() error {
-	opts := e.Options{
-		Executors: make([]e.ScopedOptions, len(m.opts)),
+	keys := make([]string, 0, len(m.created))
+	for key := range m.created {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ci, cj := m.created[keys[i]], m.created[keys[j]]; !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return keys[i] < keys[j]
+	})
+
+	statuses := make(map[string]configv2alpha1.ExecutorStatus, len(keys))
+	conflicts := make(map[string][]string)
+	// Active and shadow executors own their scopes independently.
+	owners := map[bool]map[string]string{
+		false: make(map[string]string),
+		true:  make(map[string]string),
+	}
+	units := make(map[bool][]*e.Unit)
+	for _, key := range keys {
+		if err, failed := m.failures[key]; failed {
+			statuses[key] = newExecutorStatus(false, err.Error())
+			continue
+		}
+		shadow := m.shadows[key]
+		unit := m.units[key]
+		var conflict string
+		for _, scope := range unit.Scopes() {
+			if owner, ok := owners[shadow][scope]; ok {
+				conflict = fmt.Sprintf("scope %q conflicts with executor %s", scope, displayName(owner))
+				conflicts[owner] = append(conflicts[owner], fmt.Sprintf("scope %q conflicts with executor %s, which is excluded", scope, displayName(key)))
+				break
+			}
+		}
+		if conflict != "" {
+			statuses[key] = newExecutorStatus(false, conflict)
+			continue
+		}
+		for _, scope := range unit.Scopes() {
+			owners[shadow][scope] = key
+		}
+		units[shadow] = append(units[shadow], unit)
+		statuses[key] = newExecutorStatus(true, "")
 	}
-	i := 0
-	for _, scopedOpts := range m.opts {
-		opts.Executors[i] = scopedOpts
-		i++
+	for owner, messages := range conflicts {
+		statuses[owner] = newExecutorStatus(true, strings.Join(messages, "; "))
 	}
 
-	executor, err := e.NewScopedExecutor(opts)
+	executor, err := newExecutorFromUnits(units[false])
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
-
+	shadowExecutor, err := newExecutorFromUnits(units[true])
+	if err != nil {
+		return fmt.Errorf("failed to create shadow executor: %w", err)
+	}
 	m.executor.Store(executor)
+	m.shadowExecutor.Store(shadowExecutor)
+
+	for key, status := range statuses {
+		if previous, ok := m.statuses[key]; !ok || previous != status {
+			if m.changed == nil {
+				m.changed = make(map[string]struct{})
+			}
+			m.changed[key] = struct{}{}
+		}
+	}
+	for key := range m.changed {
+		if _, ok := statuses[key]; !ok {
+			delete(m.changed, key)
+		}
+	}
+	m.statuses = statuses
 	return nil
 }
 
+// newExecutorFromUnits creates an executor from the units. It returns nil if
+// there are no units.
+func newExecutorFromUnits(units []*e.Unit) (*e.ScopedExecutor, error) {
+	if len(units) == 0 {
+		return nil, nil
+	}
+	return e.NewScopedExecutorFromUnits(units)
+}
+
+// newExecutorStatus creates the status of an Executor resource.
+func newExecutorStatus(succeeded bool, message string) configv2alpha1.ExecutorStatus {
+	status := configv2alpha1.ExecutorStatus{
+		Succeeded: succeeded,
+		Error:     message,
+	}
+	if len(message) > maxBriefErrorLength {
+		status.BriefError = message[:maxBriefErrorLength] + "..."
+	} else {
+		status.BriefError = message
+	}
+	return status
+}
+
+// displayName returns the name of the executor under the given key as shown to
+// users. Executors are cluster scoped, so the empty namespace is omitted.
+func displayName(key string) string {
+	return strings.TrimPrefix(key, "/")
+}
+
 // convertOptions converts the provided configv2alpha1.Executor options into a
 // ScopedOptions.
 func convertOptions(opts *configv2alpha1.Executor) (e.ScopedOptions, error) {
 	scopedOpts := e.ScopedOptions{
-		Scopes: opts.Spec.Scopes,
+		Scopes:          opts.Spec.Scopes,
+		ExcludeScopes:   opts.Spec.ExcludeScopes,
+		EnforcementMode: e.EnforcementMode(opts.Spec.EnforcementMode),
+	}
+	if scopedOpts.EnforcementMode == e.EnforcementModeExempt {
+		// Exempt executors never validate artifacts, so verifiers, stores and
+		// policy enforcer are not needed.
+		return scopedOpts, nil
 	}
 
 	verifierOpts, err := convertVerifierOptions(opts.Spec.Verifiers)
@@ -126,6 +373,7 @@ func convertOptions(opts *configv2alpha1.Executor) (e.ScopedOptions, error) {
 	scopedOpts.Stores = storeOpts
 
 	scopedOpts.Policy = convertPolicyOptions(opts.Spec.PolicyEnforcer)
+	scopedOpts.PlatformPolicy = convertPlatformPolicyOptions(opts.Spec.PlatformPolicy)
 
 	return scopedOpts, nil
 }
@@ -157,6 +405,12 @@ func convertStoreOptions(stores []*configv2alpha1.StoreOptions) ([]store.NewOpti
 			Type:       s.Type,
 			Parameters: s.Parameters,
 		}
+		if s.DiskCache != nil {
+			opts.DiskCache = &store.DiskCacheOptions{
+				Path:         s.DiskCache.Path,
+				MaxSizeBytes: s.DiskCache.MaxSizeBytes,
+			}
+		}
 		storeOpts[i] = opts
 	}
 	return storeOpts, nil
@@ -172,6 +426,16 @@ func convertPolicyOptions(policy *configv2alpha1.PolicyEnforcerOptions) *policye
 	}
 }
 
+func convertPlatformPolicyOptions(policy *configv2alpha1.PlatformPolicyOptions) *e.PlatformPolicyOptions {
+	if policy == nil {
+		return nil
+	}
+	return &e.PlatformPolicyOptions{
+		Mode:      e.PlatformMode(policy.Mode),
+		Platforms: policy.Platforms,
+	}
+}
+
 func createOptsKey(namespace, name string) string {
 	return fmt.Sprintf("%s/%s", namespace, name)
 }
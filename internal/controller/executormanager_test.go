@@ -18,7 +18,10 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/notaryproject/ratify-go"
 	configv2alpha1 "github.com/notaryproject/ratify/v2/api/v2alpha1"
@@ -26,6 +29,7 @@ import (
 	"github.com/notaryproject/ratify/v2/internal/store"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -209,3 +213,342 @@ func TestDeleteExecutor_RemoveExistingEntry(t *testing.T) {
 		t.Fatalf("expected non-nil executor after deletion")
 	}
 }
+
+func TestUpsertExecutor_ExemptExecutor(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	executorOpts := &configv2alpha1.Executor{
+		Spec: configv2alpha1.ExecutorSpec{
+			Scopes:          []string{"vendor.example.com"},
+			EnforcementMode: string(e.EnforcementModeExempt),
+		},
+	}
+
+	if err := mgr.upsertExecutor("default", "exempt-exec", executorOpts); err != nil {
+		t.Fatalf("unexpected error for exempt executor without verifiers and stores: %v", err)
+	}
+
+	result, err := mgr.GetExecutor().ValidateArtifact(context.Background(), "vendor.example.com/app:v1")
+	if err != nil {
+		t.Fatalf("unexpected error validating exempt artifact: %v", err)
+	}
+	if !result.Succeeded || result.Exemption == nil {
+		t.Fatalf("expected exempt artifact to succeed with exemption, got %+v", result)
+	}
+}
+
+func TestUpsertExecutor_PlatformPolicy(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	executorOpts := newValidExecutor()
+	executorOpts.Spec.PlatformPolicy = &configv2alpha1.PlatformPolicyOptions{
+		Mode:      string(e.PlatformModePlatforms),
+		Platforms: []string{"linux/amd64"},
+	}
+
+	if err := mgr.upsertExecutor("default", "platform-exec", executorOpts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	platformPolicy := mgr.opts[createOptsKey("default", "platform-exec")].PlatformPolicy
+	if platformPolicy == nil || platformPolicy.Mode != e.PlatformModePlatforms || len(platformPolicy.Platforms) != 1 {
+		t.Fatalf("expected platform policy to be converted, got %+v", platformPolicy)
+	}
+
+	mgr = executorManager{opts: map[string]e.ScopedOptions{}}
+	executorOpts.Spec.PlatformPolicy.Platforms = nil
+	if err := mgr.upsertExecutor("default", "invalid-platform-exec", executorOpts); err == nil {
+		t.Fatalf("expected error for platforms mode without platforms")
+	}
+}
+
+func TestUpsertExecutor_StoreDiskCache(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	executorOpts := newValidExecutor()
+	executorOpts.Spec.Stores[0].DiskCache = &configv2alpha1.DiskCacheOptions{
+		Path:         t.TempDir(),
+		MaxSizeBytes: 1024,
+	}
+
+	if err := mgr.upsertExecutor("default", "disk-cache-exec", executorOpts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	diskCache := mgr.opts[createOptsKey("default", "disk-cache-exec")].Stores[0].DiskCache
+	if diskCache == nil || diskCache.Path != executorOpts.Spec.Stores[0].DiskCache.Path || diskCache.MaxSizeBytes != 1024 {
+		t.Fatalf("expected disk cache options to be converted, got %+v", diskCache)
+	}
+}
+
+func TestUpsertExecutor_ReuseUnchangedUnits(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("default", "exec1", newValidExecutor()); err != nil {
+		t.Fatalf("failed to upsert exec1: %v", err)
+	}
+	executor2 := newValidExecutor()
+	executor2.Spec.Scopes = []string{"example2.com"}
+	if err := mgr.upsertExecutor("default", "exec2", executor2); err != nil {
+		t.Fatalf("failed to upsert exec2: %v", err)
+	}
+	key1 := createOptsKey("default", "exec1")
+	key2 := createOptsKey("default", "exec2")
+	unit1 := mgr.units[key1]
+	unit2 := mgr.units[key2]
+
+	// Updating exec2 must not rebuild exec1.
+	executor2.Spec.Scopes = []string{"example3.com"}
+	if err := mgr.upsertExecutor("default", "exec2", executor2); err != nil {
+		t.Fatalf("failed to update exec2: %v", err)
+	}
+	if mgr.units[key1] != unit1 {
+		t.Errorf("expected unit of unchanged exec1 to be reused")
+	}
+	if mgr.units[key2] == unit2 {
+		t.Errorf("expected unit of changed exec2 to be rebuilt")
+	}
+
+	// Reconciling exec1 without changes must not rebuild it either.
+	if err := mgr.upsertExecutor("default", "exec1", newValidExecutor()); err != nil {
+		t.Fatalf("failed to upsert exec1 again: %v", err)
+	}
+	if mgr.units[key1] != unit1 {
+		t.Errorf("expected unit of exec1 to be reused when its options are unchanged")
+	}
+
+	// A failed update excludes the executor.
+	invalid := newValidExecutor()
+	invalid.Spec.Scopes = []string{"example.com/repo:tag"}
+	if err := mgr.upsertExecutor("default", "exec1", invalid); err == nil {
+		t.Fatalf("expected error for invalid scope")
+	}
+	if _, ok := mgr.units[key1]; ok {
+		t.Errorf("expected unit of exec1 to be removed after a failed update")
+	}
+
+	if err := mgr.deleteExecutor("default", "exec2"); err != nil {
+		t.Fatalf("failed to delete exec2: %v", err)
+	}
+	if _, ok := mgr.units[key2]; ok {
+		t.Errorf("expected unit of exec2 to be removed")
+	}
+}
+
+// closingVerifier counts the times the verifiers of its type are closed.
+type closingVerifier struct {
+	mockVerifier
+}
+
+var closedVerifiers atomic.Int32
+
+func (v *closingVerifier) Close() error {
+	closedVerifiers.Add(1)
+	return nil
+}
+
+func TestUpsertExecutor_CloseReplacedUnits(t *testing.T) {
+	verifier.Register("mock-verifier-close", func(verifier.NewOptions, []string) (ratify.Verifier, error) {
+		return &closingVerifier{}, nil
+	})
+	newClosingExecutor := func(scope string) *configv2alpha1.Executor {
+		executor := newValidExecutor()
+		executor.Spec.Scopes = []string{scope}
+		executor.Spec.Verifiers[0].Type = "mock-verifier-close"
+		return executor
+	}
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("default", "exec1", newClosingExecutor("example.com")); err != nil {
+		t.Fatalf("failed to upsert exec1: %v", err)
+	}
+
+	// An unchanged executor keeps its unit open.
+	if err := mgr.upsertExecutor("default", "exec1", newClosingExecutor("example.com")); err != nil {
+		t.Fatalf("failed to upsert exec1 again: %v", err)
+	}
+	if got := closedVerifiers.Load(); got != 0 {
+		t.Errorf("expected the unit of an unchanged executor not to be closed, got %d closes", got)
+	}
+
+	if err := mgr.upsertExecutor("default", "exec1", newClosingExecutor("example2.com")); err != nil {
+		t.Fatalf("failed to update exec1: %v", err)
+	}
+	if got := closedVerifiers.Load(); got != 1 {
+		t.Errorf("expected the replaced unit to be closed, got %d closes", got)
+	}
+
+	if err := mgr.deleteExecutor("default", "exec1"); err != nil {
+		t.Fatalf("failed to delete exec1: %v", err)
+	}
+	if got := closedVerifiers.Load(); got != 2 {
+		t.Errorf("expected the unit of the deleted executor to be closed, got %d closes", got)
+	}
+}
+
+func TestUpsertExecutor_IsolateBrokenExecutor(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("", "good", newValidExecutor()); err != nil {
+		t.Fatalf("failed to upsert good executor: %v", err)
+	}
+
+	broken := newValidExecutor()
+	broken.Spec.Scopes = []string{"broken.example.com"}
+	broken.Spec.Verifiers[0].Type = "unknown-verifier-type"
+	if err := mgr.upsertExecutor("", "broken", broken); err == nil {
+		t.Fatalf("expected error for broken executor")
+	}
+
+	added := newValidExecutor()
+	added.Spec.Scopes = []string{"added.example.com"}
+	if err := mgr.upsertExecutor("", "added", added); err != nil {
+		t.Fatalf("expected executor added after a broken one to be applied, got: %v", err)
+	}
+
+	executor := mgr.GetExecutor()
+	for _, artifact := range []string{"example.com/app:v1", "added.example.com/app:v1"} {
+		if _, err := executor.Explain(artifact); err != nil {
+			t.Errorf("expected artifact %q to be served, got: %v", artifact, err)
+		}
+	}
+	if _, err := executor.Explain("broken.example.com/app:v1"); err == nil {
+		t.Errorf("expected broken executor to be excluded")
+	}
+
+	status := mgr.getStatus("", "broken")
+	if status.Succeeded || status.Error == "" || status.BriefError == "" {
+		t.Errorf("expected broken executor to be marked failed, got: %+v", status)
+	}
+	if status := mgr.getStatus("", "good"); !status.Succeeded || status.Error != "" {
+		t.Errorf("expected good executor to succeed, got: %+v", status)
+	}
+	if errs := mgr.GetErrors(); len(errs) != 1 || errs["broken"] == nil {
+		t.Errorf("expected only the error of the broken executor, got: %v", errs)
+	}
+
+	// Fixing the broken executor applies it.
+	broken.Spec.Verifiers[0].Type = mockVerifierType
+	if err := mgr.upsertExecutor("", "broken", broken); err != nil {
+		t.Fatalf("expected fixed executor to be applied, got: %v", err)
+	}
+	if errs := mgr.GetErrors(); errs != nil {
+		t.Errorf("expected no errors after the broken executor is fixed, got: %v", errs)
+	}
+	if _, err := mgr.GetExecutor().Explain("broken.example.com/app:v1"); err != nil {
+		t.Errorf("expected fixed executor to be served, got: %v", err)
+	}
+}
+
+func TestUpsertExecutor_ScopeConflict(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("", "first", newValidExecutor()); err != nil {
+		t.Fatalf("failed to upsert first executor: %v", err)
+	}
+	mgr.takeStatusChanges()
+
+	second := newValidExecutor()
+	second.Spec.Scopes = []string{"example.com", "second.example.com"}
+	err := mgr.upsertExecutor("", "second", second)
+	if err == nil || !strings.Contains(err.Error(), `scope "example.com" conflicts with executor first`) {
+		t.Fatalf("expected scope conflict error, got: %v", err)
+	}
+
+	first := mgr.getStatus("", "first")
+	if !first.Succeeded || !strings.Contains(first.Error, "conflicts with executor second") {
+		t.Errorf("expected conflict to be reported on the serving executor, got: %+v", first)
+	}
+	if _, err := mgr.GetExecutor().Explain("second.example.com/app:v1"); err == nil {
+		t.Errorf("expected conflicting executor to be excluded")
+	}
+
+	changes := mgr.takeStatusChanges()
+	if len(changes) != 2 {
+		t.Errorf("expected status changes of both executors, got: %v", changes)
+	}
+
+	// Deleting the serving executor resolves the conflict.
+	if err := mgr.deleteExecutor("", "first"); err != nil {
+		t.Fatalf("failed to delete first executor: %v", err)
+	}
+	if status := mgr.getStatus("", "second"); !status.Succeeded || status.Error != "" {
+		t.Errorf("expected second executor to be served after the conflict is resolved, got: %+v", status)
+	}
+	if changes := mgr.takeStatusChanges(); len(changes) != 1 || changes[0].Name != "second" {
+		t.Errorf("expected status change of second executor, got: %v", changes)
+	}
+
+	if err := mgr.deleteExecutor("", "second"); err != nil {
+		t.Fatalf("failed to delete second executor: %v", err)
+	}
+	if mgr.GetExecutor() != nil {
+		t.Errorf("expected no executor after all executors are deleted")
+	}
+}
+
+func TestUpsertExecutor_ScopeConflictOrder(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newExecutor := func(created time.Time) *configv2alpha1.Executor {
+		executor := newValidExecutor()
+		executor.CreationTimestamp = metav1.NewTime(created)
+		return executor
+	}
+
+	// The executor created first keeps the scope even if reconciled last.
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("", "newer", newExecutor(created.Add(time.Hour))); err != nil {
+		t.Fatalf("failed to upsert newer executor: %v", err)
+	}
+	if err := mgr.upsertExecutor("", "older", newExecutor(created)); err != nil {
+		t.Fatalf("expected older executor to be served, got: %v", err)
+	}
+	if status := mgr.getStatus("", "newer"); status.Succeeded {
+		t.Errorf("expected newer executor to be excluded, got: %+v", status)
+	}
+
+	// Executors created at the same time are ordered by namespace and name.
+	mgr = executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("b", "exec", newExecutor(created)); err != nil {
+		t.Fatalf("failed to upsert executor b/exec: %v", err)
+	}
+	if err := mgr.upsertExecutor("a", "exec", newExecutor(created)); err != nil {
+		t.Fatalf("expected executor a/exec to be served, got: %v", err)
+	}
+	if status := mgr.getStatus("b", "exec"); status.Succeeded {
+		t.Errorf("expected executor b/exec to be excluded, got: %+v", status)
+	}
+}
+
+func TestUpsertExecutor_Shadow(t *testing.T) {
+	mgr := executorManager{opts: map[string]e.ScopedOptions{}}
+	if err := mgr.upsertExecutor("", "active", newValidExecutor()); err != nil {
+		t.Fatalf("failed to upsert active executor: %v", err)
+	}
+	if mgr.GetShadowExecutor() != nil {
+		t.Fatalf("expected no shadow executor")
+	}
+
+	// A shadow executor may register the same scopes as an active one.
+	candidate := newValidExecutor()
+	candidate.Spec.Shadow = true
+	if err := mgr.upsertExecutor("", "candidate", candidate); err != nil {
+		t.Fatalf("failed to upsert shadow executor: %v", err)
+	}
+	if mgr.GetExecutor() == nil || mgr.GetShadowExecutor() == nil {
+		t.Fatalf("expected both active and shadow executors")
+	}
+	if status := mgr.getStatus("", "active"); !status.Succeeded || status.Error != "" {
+		t.Errorf("expected active executor to be unaffected, got: %+v", status)
+	}
+
+	// Shadow executors still conflict with each other.
+	other := newValidExecutor()
+	other.Spec.Shadow = true
+	if err := mgr.upsertExecutor("", "other", other); err == nil || !strings.Contains(err.Error(), "conflicts with executor candidate") {
+		t.Errorf("expected conflict between shadow executors, got: %v", err)
+	}
+	if err := mgr.deleteExecutor("", "other"); err != nil {
+		t.Fatalf("failed to delete other executor: %v", err)
+	}
+
+	// Promoting the candidate conflicts with the active executor.
+	candidate.Spec.Shadow = false
+	if err := mgr.upsertExecutor("", "candidate", candidate); err == nil {
+		t.Errorf("expected promoted executor to conflict with the active executor")
+	}
+	if mgr.GetShadowExecutor() != nil {
+		t.Errorf("expected no shadow executor after promotion")
+	}
+}
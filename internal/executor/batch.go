@@ -0,0 +1,84 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMaxConcurrency is the default maximum number of artifacts validated
+// concurrently by [ScopedExecutor.ValidateArtifacts].
+const DefaultMaxConcurrency = 5
+
+// ValidateArtifactsOptions contains the options to validate a batch of
+// artifacts.
+type ValidateArtifactsOptions struct {
+	// MaxConcurrency is the maximum number of artifacts validated
+	// concurrently. Defaults to [DefaultMaxConcurrency] if not positive.
+	// Optional.
+	MaxConcurrency int
+
+	// Validate validates a single artifact. It allows callers to wrap the
+	// validation of each artifact, e.g. with caching or deduplication of
+	// concurrent requests. Defaults to [ScopedExecutor.ValidateArtifact].
+	// Optional.
+	Validate func(ctx context.Context, artifact string) (*ValidationResult, error)
+}
+
+// ArtifactResult is the outcome of validating an artifact in a batch.
+type ArtifactResult struct {
+	// Artifact is the reference to the validated artifact.
+	Artifact string
+
+	// Result is the validation result. It is nil if Err is set.
+	Result *ValidationResult
+
+	// Err is the error occurred while validating the artifact.
+	Err error
+}
+
+// ValidateArtifacts validates the artifacts concurrently with at most
+// opts.MaxConcurrency workers. The results are returned in the same order as
+// the artifacts. A failure of one artifact does not affect the others.
+func (s *ScopedExecutor) ValidateArtifacts(ctx context.Context, artifacts []string, opts ValidateArtifactsOptions) []*ArtifactResult {
+	validateFunc := opts.Validate
+	if validateFunc == nil {
+		validateFunc = s.ValidateArtifact
+	}
+	maxConcurrency := opts.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+
+	results := make([]*ArtifactResult, len(artifacts))
+	var group errgroup.Group
+	group.SetLimit(maxConcurrency)
+	for idx, artifact := range artifacts {
+		group.Go(func() error {
+			result, err := validateFunc(ctx, artifact)
+			results[idx] = &ArtifactResult{
+				Artifact: artifact,
+				Result:   result,
+				Err:      err,
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return results
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+)
+
+func TestValidateArtifacts(t *testing.T) {
+	scopedExecutor := &ScopedExecutor{}
+	entry := &scopedEntry{
+		executor: &ratify.Executor{
+			Store:     &mockStore{},
+			Verifiers: []ratify.Verifier{&mockVerifier{}},
+		},
+	}
+	if err := scopedExecutor.registerExecutor("registry.example.com", entry); err != nil {
+		t.Fatalf("failed to register executor: %v", err)
+	}
+
+	artifacts := []string{
+		"registry.example.com/app1:v1",
+		"unknown.com/app2:v1",
+		"registry.example.com/app3:v1",
+	}
+	results := scopedExecutor.ValidateArtifacts(context.Background(), artifacts, ValidateArtifactsOptions{})
+	if len(results) != len(artifacts) {
+		t.Fatalf("expected %d results, got %d", len(artifacts), len(results))
+	}
+	for idx, result := range results {
+		if result.Artifact != artifacts[idx] {
+			t.Errorf("expected result %d for artifact %q, got %q", idx, artifacts[idx], result.Artifact)
+		}
+		expectErr := idx == 1
+		if (result.Err != nil) != expectErr {
+			t.Errorf("expected error for artifact %q: %v, got: %v", result.Artifact, expectErr, result.Err)
+		}
+		if !expectErr && result.Result == nil {
+			t.Errorf("expected validation result for artifact %q", result.Artifact)
+		}
+	}
+}
+
+func TestValidateArtifacts_MaxConcurrency(t *testing.T) {
+	const maxConcurrency = 2
+	var running, maxRunning atomic.Int32
+	artifacts := make([]string, 10)
+	for idx := range artifacts {
+		artifacts[idx] = fmt.Sprintf("registry.example.com/app%d:v1", idx)
+	}
+
+	scopedExecutor := &ScopedExecutor{}
+	results := scopedExecutor.ValidateArtifacts(context.Background(), artifacts, ValidateArtifactsOptions{
+		MaxConcurrency: maxConcurrency,
+		Validate: func(_ context.Context, artifact string) (*ValidationResult, error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+			for {
+				previous := maxRunning.Load()
+				if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if artifact == artifacts[3] {
+				return nil, errors.New("validation failed")
+			}
+			return &ValidationResult{Succeeded: true}, nil
+		},
+	})
+
+	if got := maxRunning.Load(); got > maxConcurrency {
+		t.Errorf("expected at most %d concurrent validations, got %d", maxConcurrency, got)
+	}
+	for idx, result := range results {
+		if result.Artifact != artifacts[idx] {
+			t.Errorf("expected result %d for artifact %q, got %q", idx, artifacts[idx], result.Artifact)
+		}
+		if (result.Err != nil) != (idx == 3) {
+			t.Errorf("unexpected error for artifact %q: %v", result.Artifact, result.Err)
+		}
+	}
+}
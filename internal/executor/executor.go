@@ -18,17 +18,45 @@ package executor
 import (
 	"context"
 	"fmt"
-	"strings"
+	"slices"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/logger"
 	"github.com/notaryproject/ratify/v2/internal/policyenforcer"
+	"github.com/notaryproject/ratify/v2/internal/scope"
 	"github.com/notaryproject/ratify/v2/internal/store"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 
 	"oras.land/oras-go/v2/registry"
 )
 
+var logOpt = logger.Option{ComponentType: logger.Executor}
+
+// EnforcementMode defines how the result of an executor is enforced.
+type EnforcementMode string
+
+const (
+	// EnforcementModeEnforce validates artifacts and reports the validation
+	// result as is. It is the default mode.
+	EnforcementModeEnforce EnforcementMode = "enforce"
+
+	// EnforcementModeExempt skips validation of artifacts. Artifacts are
+	// reported as exempt and allowed.
+	EnforcementModeExempt EnforcementMode = "exempt"
+
+	// EnforcementModeWarn validates artifacts but allows artifacts failing
+	// the validation. Callers are expected to surface the would-be denials,
+	// e.g. by logging them.
+	EnforcementModeWarn EnforcementMode = "warn"
+
+	// EnforcementModeAudit validates artifacts but allows artifacts failing
+	// the validation. The validation result is only recorded.
+	EnforcementModeAudit EnforcementMode = "audit"
+)
+
 // ScopedOptions contains the configuration options to create a group of plugins
 // for the executor under a scope.
 type ScopedOptions struct {
@@ -36,15 +64,30 @@ type ScopedOptions struct {
 	// Required.
 	Scopes []string `json:"scopes"`
 
-	// Verifiers contains the configuration options for the verifiers. Required.
+	// ExcludeScopes defines the scopes exempted from validation. Artifacts
+	// matching an excluded scope are reported as exempt instead of being
+	// validated, unless they match a more specific scope. Optional.
+	ExcludeScopes []string `json:"excludeScopes,omitempty"`
+
+	// EnforcementMode defines how the validation result is enforced. Defaults
+	// to [EnforcementModeEnforce]. Optional.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+
+	// Verifiers contains the configuration options for the verifiers. Required
+	// unless EnforcementMode is [EnforcementModeExempt].
 	Verifiers []verifier.NewOptions `json:"verifiers"`
 
-	// Stores contains the configuration options for the stores. Required.
+	// Stores contains the configuration options for the stores. Required
+	// unless EnforcementMode is [EnforcementModeExempt].
 	Stores []store.NewOptions `json:"stores"`
 
 	// Policy contains the configuration options for the policy enforcer.
 	// Optional.
 	Policy *policyenforcer.NewOptions `json:"policyEnforcer,omitempty"`
+
+	// PlatformPolicy defines how artifacts referring to an image index are
+	// validated. Only the image index is validated if not set. Optional.
+	PlatformPolicy *PlatformPolicyOptions `json:"platformPolicy,omitempty"`
 }
 
 // Options contains the configuration options to create a scoped executor.
@@ -56,28 +99,163 @@ type Options struct {
 	Executors []ScopedOptions `json:"executors"`
 }
 
+// ValidateOptions contains the options to validate a single artifact.
+type ValidateOptions struct {
+	// Platforms is the list of platforms to validate in the format of
+	// "os/arch" or "os/arch/variant". If set, the child manifests of an image
+	// index matching the platforms are validated regardless of the platform
+	// policy of the matched executor. Optional.
+	Platforms []string `json:"platforms,omitempty"`
+
+	// ReferenceTypes is the list of artifact types of the referrers to
+	// verify. All referrers are verified if empty. Optional.
+	ReferenceTypes []string `json:"referenceTypes,omitempty"`
+
+	// MaxDepth is the maximum depth of the referrers to verify below the
+	// subject, the direct referrers of the subject being at depth 1. The
+	// referrers of all depths are verified if not positive. Optional.
+	MaxDepth int `json:"maxDepth,omitempty"`
+}
+
+// ValidationResult is the result of validating an artifact with a
+// [ScopedExecutor].
+type ValidationResult struct {
+	// Succeeded indicates whether the artifact is allowed. It is always true
+	// for an exempt artifact or an artifact validated by an executor in
+	// [EnforcementModeWarn] or [EnforcementModeAudit].
+	Succeeded bool
+
+	// VerificationSucceeded indicates whether the artifact passed the
+	// validation. It only differs from Succeeded if the artifact failed the
+	// validation by an executor in [EnforcementModeWarn] or
+	// [EnforcementModeAudit].
+	VerificationSucceeded bool
+
+	// EnforcementMode is the enforcement mode of the executor that validated
+	// the artifact. It is empty for an exempt artifact.
+	EnforcementMode EnforcementMode
+
+	// ArtifactReports contains the reports of the artifacts validated against
+	// the subject. It is empty for an exempt artifact.
+	ArtifactReports []*ratify.ValidationReport
+
+	// Exemption describes why the artifact was not validated. It is nil if the
+	// artifact was validated.
+	Exemption *Exemption
+
+	// PlatformResults contains the results of the platform specific manifests
+	// validated in place of an image index according to the platform policy.
+	// The result succeeds only if all platform results succeed.
+	PlatformResults []*PlatformResult
+
+	// Timings holds the time taken to validate each node of the artifact
+	// reports. It is nil for an exempt artifact.
+	Timings *Timings
+}
+
+// Exemption describes why an artifact is exempt from validation.
+type Exemption struct {
+	// Scope is the scope matched by the artifact.
+	Scope string
+
+	// Reason is a human readable explanation of the exemption.
+	Reason string
+}
+
+// Explanation describes how a [ScopedExecutor] routes an artifact.
+type Explanation struct {
+	// Scope is the executor scope matched by the artifact.
+	Scope string `json:"scope"`
+
+	// MatchKind is the kind of the matched scope.
+	MatchKind scope.Kind `json:"matchKind"`
+
+	// Exempt indicates whether the artifact is exempt from validation.
+	Exempt bool `json:"exempt,omitempty"`
+
+	// Reason explains why the artifact is exempt from validation.
+	Reason string `json:"reason,omitempty"`
+
+	// EnforcementMode is the enforcement mode of the executor validating the
+	// artifact.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+
+	// Verifiers contains the names of the verifiers validating the artifact.
+	Verifiers []string `json:"verifiers,omitempty"`
+
+	// Store describes the store the artifact is fetched from.
+	Store *store.Explanation `json:"store,omitempty"`
+
+	// PolicyEnforcerType is the type of the policy enforcer evaluating the
+	// validation result. It is empty if no policy enforcer is configured.
+	PolicyEnforcerType string `json:"policyEnforcerType,omitempty"`
+}
+
+// scopedEntry is the value registered for each scope of a [ScopedExecutor].
+type scopedEntry struct {
+	// executor validates artifacts matching the scope. It may be nil for an
+	// exempt entry of an exempt executor.
+	executor *ratify.Executor
+
+	// platformPolicy selects the platform specific manifests to validate in
+	// place of an image index. Only the image index is validated if nil.
+	platformPolicy *platformPolicy
+
+	// exemption is set if artifacts matching the scope are exempt from
+	// validation.
+	exemption *Exemption
+
+	// enforcementMode is the enforcement mode of the executor.
+	enforcementMode EnforcementMode
+
+	// policyEnforcerType is the type of the policy enforcer of the executor.
+	// It is empty if no policy enforcer is configured.
+	policyEnforcerType string
+
+	// generation is the generation of the unit the entry belongs to.
+	generation string
+}
+
 // ScopedExecutor manages multiple ratify.Executor instances, each associated
 // with specific scopes (registries or repositories). It provides a mechanism to
 // route artifact validation requests to the appropriate executor based on the
 // artifact's reference.
 //
-// The executor supports three types of scope patterns:
-//   - Wildcard registries: "*.example.com" matches any subdomain of example.com
-//   - Specific registries: "registry.example.com" matches only that registry
-//   - Repository paths: "registry.example.com/namespace/repo" matches a
-//     specific repository
-//
-// Note: Top level domain wildcard is also not supported. That is, "*" is not a
-// valid pattern.
+// Scopes are matched by [scope.Matcher]. Besides wildcard registries, specific
+// registries and repository paths, repository prefixes such as
+// "registry.example.com/namespace/*" and "registry.example.com/namespace/**"
+// are supported.
 // Scope matching follows a precedence order from most specific to least
 // specific:
 //  1. Exact repository match
-//  2. Exact registry match
-//  3. Wildcard registry match
+//  2. Longest repository prefix match
+//  3. Exact registry match
+//  4. Wildcard registry match
+//
+// Excluded scopes and scopes of exempt executors take part in the same
+// precedence order. Artifacts resolved to them are reported as exempt.
 type ScopedExecutor struct {
-	wildcard   map[string]*ratify.Executor
-	registry   map[string]*ratify.Executor
-	repository map[string]*ratify.Executor
+	matcher scope.Matcher[*scopedEntry]
+	scopes  []ScopeInfo
+
+	// ownedUnits are the units created by [NewScopedExecutor], closed with
+	// the executor.
+	ownedUnits []*Unit
+}
+
+// ScopeInfo describes a scope registered in a [ScopedExecutor].
+type ScopeInfo struct {
+	// Scope is the scope as registered.
+	Scope string `json:"scope"`
+
+	// Exempt indicates whether artifacts matching the scope are exempt from
+	// validation, either because the scope is excluded or because it belongs
+	// to an exempt executor.
+	Exempt bool `json:"exempt,omitempty"`
+
+	// EnforcementMode is the enforcement mode of the executor validating
+	// artifacts matching the scope. It is empty for exempt scopes.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
 }
 
 // NewScopedExecutor creates a new ScopedExecutor instance based on the provided
@@ -87,24 +265,50 @@ func NewScopedExecutor(opts Options) (*ScopedExecutor, error) {
 	if len(opts.Executors) == 0 {
 		return nil, fmt.Errorf("at least 1 executor should be provided")
 	}
-	scopedExecutor := &ScopedExecutor{
-		wildcard:   make(map[string]*ratify.Executor),
-		registry:   make(map[string]*ratify.Executor),
-		repository: make(map[string]*ratify.Executor),
+	units := make([]*Unit, 0, len(opts.Executors))
+	closeUnits := func() {
+		for _, unit := range units {
+			unit.Close()
+		}
 	}
-
 	for _, executorOpts := range opts.Executors {
-		if len(executorOpts.Scopes) == 0 {
-			return nil, fmt.Errorf("executor options must contain at least one scope")
-		}
-		executor, err := newExecutor(executorOpts)
+		unit, err := NewUnit(executorOpts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create executor: %w", err)
+			closeUnits()
+			return nil, err
 		}
-		for _, scope := range executorOpts.Scopes {
-			if err = scopedExecutor.registerExecutor(scope, executor); err != nil {
-				return nil, fmt.Errorf("failed to register executor for scope %q: %w", scope, err)
-			}
+		units = append(units, unit)
+	}
+	scopedExecutor, err := NewScopedExecutorFromUnits(units)
+	if err != nil {
+		closeUnits()
+		return nil, err
+	}
+	scopedExecutor.ownedUnits = units
+	return scopedExecutor, nil
+}
+
+// Close closes the units created by [NewScopedExecutor] once the executor is
+// replaced. The units passed to [NewScopedExecutorFromUnits] are owned by the
+// caller and left open.
+func (s *ScopedExecutor) Close() {
+	for _, unit := range s.ownedUnits {
+		unit.Close()
+	}
+}
+
+// NewScopedExecutorFromUnits creates a new ScopedExecutor instance routing
+// artifacts to the provided units. Units are not modified, so a unit can be
+// shared by multiple ScopedExecutor instances. It returns an error if no units
+// are provided or if a scope is registered by multiple units.
+func NewScopedExecutorFromUnits(units []*Unit) (*ScopedExecutor, error) {
+	if len(units) == 0 {
+		return nil, fmt.Errorf("at least 1 executor should be provided")
+	}
+	scopedExecutor := &ScopedExecutor{}
+	for _, unit := range units {
+		if err := scopedExecutor.registerUnit(unit); err != nil {
+			return nil, err
 		}
 	}
 	return scopedExecutor, nil
@@ -120,6 +324,7 @@ func newExecutor(opts ScopedOptions) (*ratify.Executor, error) {
 
 	storeMux, err := store.New(opts.Stores, opts.Scopes)
 	if err != nil {
+		closeVerifiers(verifiers)
 		return nil, err
 	}
 
@@ -127,139 +332,233 @@ func newExecutor(opts ScopedOptions) (*ratify.Executor, error) {
 	if opts.Policy != nil {
 		policy, err = policyenforcer.New(*opts.Policy)
 		if err != nil {
+			closeVerifiers(verifiers)
 			return nil, err
 		}
 	}
 
-	return ratify.NewExecutor(storeMux, verifiers, policy)
+	executor, err := ratify.NewExecutor(storeMux, verifiers, policy)
+	if err != nil {
+		closeVerifiers(verifiers)
+		return nil, err
+	}
+	return executor, nil
 }
 
 // ValidateArtifact routes the artifact validation request to the appropriate
 // executor based on the artifact's reference. It returns the validation result
-// or an error if no matching executor is found.
-func (s *ScopedExecutor) ValidateArtifact(ctx context.Context, artifact string) (*ratify.ValidationResult, error) {
-	executor, err := s.matchExecutor(artifact)
+// or an error if no matching executor is found. Artifacts matching an excluded
+// scope or a scope of an exempt executor are not validated and reported as
+// exempt. If the artifact refers to an image index, the platform policy of the
+// matched executor decides which manifests are validated.
+func (s *ScopedExecutor) ValidateArtifact(ctx context.Context, artifact string) (*ValidationResult, error) {
+	return s.ValidateArtifactWithOptions(ctx, artifact, ValidateOptions{})
+}
+
+// ValidateArtifactWithOptions validates the artifact like
+// [ScopedExecutor.ValidateArtifact], with the options overriding the
+// configuration of the matched executor for this validation only.
+func (s *ScopedExecutor) ValidateArtifactWithOptions(ctx context.Context, artifact string, opts ValidateOptions) (*ValidationResult, error) {
+	entry, err := s.matchEntry(ctx, artifact)
 	if err != nil {
 		return nil, fmt.Errorf("failed to match executor for artifact %q: %w", artifact, err)
 	}
-	opts := ratify.ValidateArtifactOptions{
-		Subject: artifact,
+	if entry.exemption != nil {
+		return &ValidationResult{
+			Succeeded: true,
+			Exemption: entry.exemption,
+		}, nil
+	}
+	platformPolicy := entry.platformPolicy
+	if len(opts.Platforms) > 0 {
+		platformPolicy, err = newPlatformPolicy(&PlatformPolicyOptions{
+			Mode:      PlatformModePlatforms,
+			Platforms: opts.Platforms,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	tracker := newTracker(opts.MaxDepth)
+	executor := tracker.wrap(entry.executor)
+	var result *ValidationResult
+	if platformPolicy != nil {
+		result, err = validatePlatforms(ctx, executor, platformPolicy, artifact, opts.ReferenceTypes)
+	} else {
+		result, err = validate(ctx, executor, artifact, opts.ReferenceTypes)
 	}
-	return executor.ValidateArtifact(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Timings = tracker.timings
+	result.EnforcementMode = entry.enforcementMode
+	result.VerificationSucceeded = result.Succeeded
+	if entry.enforcementMode == EnforcementModeWarn || entry.enforcementMode == EnforcementModeAudit {
+		result.Succeeded = true
+	}
+	return result, nil
 }
 
 // Resolve retrieves the descriptor for the specified artifact by routing the
 // request to the appropriate executor based on the artifact's reference.
 // It returns the descriptor or an error if no matching executor is found.
 func (s *ScopedExecutor) Resolve(ctx context.Context, artifact string) (ocispec.Descriptor, error) {
-	executor, err := s.matchExecutor(artifact)
+	executor, err := s.matchExecutor(ctx, artifact)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to match executor for artifact %q: %w", artifact, err)
 	}
 	return executor.Store.Resolve(ctx, artifact)
 }
 
-// matchExecutor finds the appropriate executor for the given artifact.
-func (s *ScopedExecutor) matchExecutor(artifact string) (*ratify.Executor, error) {
+// Explain describes how the artifact is routed without validating it. It
+// reports the matched scope and, unless the artifact is exempt, the verifiers
+// and policy enforcer of the matched executor. The store is reported whenever
+// the matched scope has an executor to resolve the artifact.
+func (s *ScopedExecutor) Explain(artifact string) (*Explanation, error) {
 	ref, err := registry.ParseReference(artifact)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse artifact reference %q: %w", artifact, err)
 	}
-
-	repo := ref.Registry + "/" + ref.Repository
-	if executor, ok := s.repository[repo]; ok {
-		return executor, nil
+	entry, match, ok := s.matcher.MatchScope(ref)
+	if !ok {
+		return nil, fmt.Errorf("no executor configured for the artifact %q", artifact)
 	}
 
-	registry := ref.Registry
-	if executor, ok := s.registry[registry]; ok {
-		return executor, nil
+	explanation := &Explanation{
+		Scope:     match.Scope,
+		MatchKind: match.Kind,
 	}
-
-	if _, after, ok := strings.Cut(ref.Registry, "."); ok {
-		if executor, ok := s.wildcard[after]; ok {
-			return executor, nil
+	if entry.exemption != nil {
+		explanation.Exempt = true
+		explanation.Reason = entry.exemption.Reason
+	} else {
+		explanation.EnforcementMode = entry.enforcementMode
+		explanation.Verifiers = make([]string, len(entry.executor.Verifiers))
+		for idx, verifier := range entry.executor.Verifiers {
+			explanation.Verifiers[idx] = verifier.Name()
+		}
+		explanation.PolicyEnforcerType = entry.policyEnforcerType
+	}
+	if entry.executor != nil {
+		explanation.Store, err = store.Explain(entry.executor.Store, artifact)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain store for artifact %q: %w", artifact, err)
 		}
 	}
-	return nil, fmt.Errorf("no executor configured for the artifact %q", artifact)
+	return explanation, nil
 }
 
-// registerExecutor registers an executor for a given scope.
-func (s *ScopedExecutor) registerExecutor(scope string, executor *ratify.Executor) error {
-	if scope == "" {
-		return fmt.Errorf("scope cannot be empty")
-	}
-	if executor == nil {
-		return fmt.Errorf("executor cannot be nil")
+// matchExecutor finds the appropriate executor for the given artifact.
+func (s *ScopedExecutor) matchExecutor(ctx context.Context, artifact string) (*ratify.Executor, error) {
+	entry, err := s.matchEntry(ctx, artifact)
+	if err != nil {
+		return nil, err
 	}
-
-	if strings.Contains(scope, "/") {
-		return s.registerRepository(scope, executor)
+	if entry.executor == nil {
+		return nil, fmt.Errorf("artifact %q is exempt and no store is configured for it: %s", artifact, entry.exemption.Reason)
 	}
-	return s.registerRegistry(scope, executor)
+	return entry.executor, nil
 }
 
-// registerRepository registers an executor for a specific repository scope.
-// The scope must be a valid repository path without wildcards, tags, or digests.
-// It returns an error if the scope is invalid or if the executor is nil.
-func (s *ScopedExecutor) registerRepository(scope string, executor *ratify.Executor) error {
-	if strings.Contains(scope, "*") {
-		return fmt.Errorf("invalid scope %q: scope cannot contain wildcard for repository", scope)
-	}
-	ref, err := registry.ParseReference(scope)
+// matchEntry finds the entry registered for the most specific scope matching
+// the given artifact.
+func (s *ScopedExecutor) matchEntry(ctx context.Context, artifact string) (entry *scopedEntry, err error) {
+	_, span := tracing.StartSpan(ctx, "executor.match", tracing.AttributeArtifact.String(artifact))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ref, err := registry.ParseReference(artifact)
 	if err != nil {
-		return fmt.Errorf("invalid scope %q: %w", scope, err)
-	}
-	if ref.Reference != "" {
-		return fmt.Errorf("invalid scope %q: scope cannot contain a tag or digest", scope)
+		return nil, fmt.Errorf("failed to parse artifact reference %q: %w", artifact, err)
 	}
 
-	if s.repository == nil {
-		s.repository = map[string]*ratify.Executor{}
+	entry, match, ok := s.matcher.MatchScope(ref)
+	if !ok {
+		return nil, fmt.Errorf("no executor configured for the artifact %q", artifact)
 	}
-	if _, ok := s.repository[scope]; ok {
-		return fmt.Errorf("executor already registered for scope %q", scope)
-	}
-	s.repository[scope] = executor
-	return nil
+	span.SetAttributes(tracing.AttributeScope.String(match.Scope))
+	logger.GetLogger(ctx, logOpt).Debugf("artifact %s matched executor scope %s", artifact, match.Scope)
+	return entry, nil
 }
 
-// registerRegistry registers an executor for a given registry scope.
-// It supports both exact registry matches and wildcard registry matches.
-// The scope can be a specific registry (e.g., "registry.example.com") or a
-// wildcard registry (e.g., "*.example.com").
-func (s *ScopedExecutor) registerRegistry(scope string, executor *ratify.Executor) error {
-	ref := registry.Reference{
-		Registry: scope,
+// noGeneration is the cache generation of the artifacts not matching any unit.
+const noGeneration = "0"
+
+// CacheGeneration returns the generation of the results of the artifact
+// validated by the executor. It is derived from the options of the [Unit]
+// matched by the artifact, and changes whenever the matched scope is
+// invalidated with [cache.Invalidate]. Callers fold it into their cache keys so
+// that results cached before the matched unit is reconfigured or invalidated
+// are never served, while the results of the units kept across a rebuild stay
+// cached and replicas running the same units share their results.
+func (s *ScopedExecutor) CacheGeneration(artifact string) string {
+	ref, err := registry.ParseReference(artifact)
+	if err != nil {
+		return noGeneration
+	}
+	entry, match, ok := s.matcher.MatchScope(ref)
+	if !ok {
+		return noGeneration
 	}
-	if err := ref.ValidateRegistry(); err != nil {
-		return fmt.Errorf("invalid scope %q: %w", scope, err)
+	if scopeGeneration := cache.Generation(match.Scope); scopeGeneration != "" {
+		return entry.generation + "." + scopeGeneration
 	}
+	return entry.generation
+}
 
-	switch strings.Count(scope, "*") {
-	case 0:
-		if s.registry == nil {
-			s.registry = map[string]*ratify.Executor{}
-		}
-		if _, ok := s.registry[scope]; ok {
-			return fmt.Errorf("executor already registered for scope %q", scope)
-		}
-		s.registry[scope] = executor
-	case 1:
-		if !strings.HasPrefix(scope, "*.") {
-			return fmt.Errorf("invalid scope %q: wildcard must be at the beginning of the scope", scope)
+// Scopes returns the scopes registered in the executor in the order of
+// registration.
+func (s *ScopedExecutor) Scopes() []ScopeInfo {
+	return slices.Clone(s.scopes)
+}
+
+// registerUnit registers all scopes of the unit.
+func (s *ScopedExecutor) registerUnit(unit *Unit) error {
+	for _, reg := range unit.registrations {
+		var err error
+		switch {
+		case reg.entry.exemption == nil:
+			if err = s.registerExecutor(reg.scope, reg.entry); err != nil {
+				return fmt.Errorf("failed to register executor for scope %q: %w", reg.scope, err)
+			}
+		case reg.entry.executor == nil:
+			if err = s.matcher.Register(reg.scope, reg.entry); err != nil {
+				return fmt.Errorf("failed to register exempt scope %q: %w", reg.scope, err)
+			}
+		default:
+			if err = s.matcher.Register(reg.scope, reg.entry); err != nil {
+				return fmt.Errorf("failed to register excluded scope %q: %w", reg.scope, err)
+			}
 		}
-		scope = scope[2:]
-		if s.wildcard == nil {
-			s.wildcard = map[string]*ratify.Executor{}
+		info := ScopeInfo{
+			Scope:  reg.scope,
+			Exempt: reg.entry.exemption != nil,
 		}
-		if _, ok := s.wildcard[scope]; ok {
-			return fmt.Errorf("executor already registered for wildcard scope %q", scope)
+		if !info.Exempt {
+			info.EnforcementMode = reg.entry.enforcementMode
 		}
-		s.wildcard[scope] = executor
-	default:
-		return fmt.Errorf("invalid scope %q: scope can only contain one wildcard", scope)
+		s.scopes = append(s.scopes, info)
 	}
-
 	return nil
 }
+
+// registerExecutor registers the entry of an executor for a given scope.
+func (s *ScopedExecutor) registerExecutor(scope string, entry *scopedEntry) error {
+	if scope == "" {
+		return fmt.Errorf("scope cannot be empty")
+	}
+	if entry == nil || entry.executor == nil {
+		return fmt.Errorf("executor cannot be nil")
+	}
+	return s.matcher.Register(scope, entry)
+}
+
+// newExemptionEntry creates the entry of a scope exempt from validation.
+func newExemptionEntry(scope string, executor *ratify.Executor, reason string) *scopedEntry {
+	return &scopedEntry{
+		executor: executor,
+		exemption: &Exemption{
+			Scope:  scope,
+			Reason: reason,
+		},
+	}
+}
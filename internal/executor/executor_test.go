@@ -17,11 +17,14 @@ package executor
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/notaryproject/ratify-go"
 
+	"github.com/notaryproject/ratify/v2/internal/cache"
 	"github.com/notaryproject/ratify/v2/internal/policyenforcer"
+	"github.com/notaryproject/ratify/v2/internal/scope"
 	"github.com/notaryproject/ratify/v2/internal/store"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -231,9 +234,7 @@ func TestRegisterExecutor(t *testing.T) {
 		scope            string
 		executor         *ratify.Executor
 		registerError    bool
-		wildcardScoped   bool
-		registryScoped   bool
-		repositoryScoped bool
+		matchingArtifact string
 	}{
 		{
 			name:          "Register executor with global wildcard scope",
@@ -253,14 +254,14 @@ func TestRegisterExecutor(t *testing.T) {
 			registerError: true,
 		},
 		{
-			name:           "Register executor with registry scope",
-			scope:          "registry.example.com",
-			executor:       &ratify.Executor{},
-			registerError:  false,
-			registryScoped: true,
+			name:             "Register executor with registry scope",
+			scope:            "registry.example.com",
+			executor:         &ratify.Executor{},
+			registerError:    false,
+			matchingArtifact: "registry.example.com/foo:v1",
 		},
 		{
-			name:          "Register repository scoped executor with wildcard scope",
+			name:          "Register repository scoped executor with partial wildcard segment",
 			scope:         "registry.example.com/repository*",
 			executor:      &ratify.Executor{},
 			registerError: true,
@@ -282,7 +283,7 @@ func TestRegisterExecutor(t *testing.T) {
 			scope:            "registry.example.com/repository",
 			executor:         &ratify.Executor{},
 			registerError:    false,
-			repositoryScoped: true,
+			matchingArtifact: "registry.example.com/repository:v1",
 		},
 		{
 			name:          "Register registry scoped executor with invalid registry",
@@ -297,31 +298,55 @@ func TestRegisterExecutor(t *testing.T) {
 			registerError: true,
 		},
 		{
-			name:           "Register wildcard scoped executor",
-			scope:          "*.example.com",
-			executor:       &ratify.Executor{},
-			registerError:  false,
-			wildcardScoped: true,
+			name:             "Register wildcard scoped executor",
+			scope:            "*.example.com",
+			executor:         &ratify.Executor{},
+			registerError:    false,
+			matchingArtifact: "foo.example.com/bar:v1",
+		},
+		{
+			name:          "Register repository scoped executor with wildcard in the middle",
+			scope:         "registry.example.com/*/repository",
+			executor:      &ratify.Executor{},
+			registerError: true,
+		},
+		{
+			name:          "Register repository scoped executor with wildcard registry",
+			scope:         "*.example.com/repository/*",
+			executor:      &ratify.Executor{},
+			registerError: true,
+		},
+		{
+			name:             "Register executor with single level repository prefix",
+			scope:            "registry.example.com/team-a/*",
+			executor:         &ratify.Executor{},
+			registerError:    false,
+			matchingArtifact: "registry.example.com/team-a/app:v1",
+		},
+		{
+			name:             "Register executor with multi level repository prefix",
+			scope:            "registry.example.com/team-a/**",
+			executor:         &ratify.Executor{},
+			registerError:    false,
+			matchingArtifact: "registry.example.com/team-a/group/app:v1",
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			scopedExecutor := &ScopedExecutor{}
-			err := scopedExecutor.registerExecutor(test.scope, test.executor)
+			err := scopedExecutor.registerExecutor(test.scope, &scopedEntry{executor: test.executor})
 			if (err != nil) != test.registerError {
 				t.Errorf("expected register error: %v, got: %v", test.registerError, err)
 			}
 
-			if !test.registerError {
-				if test.wildcardScoped && len(scopedExecutor.wildcard) == 0 {
-					t.Errorf("expected wildcard scoped executors to be registered, but got none")
-				}
-				if test.registryScoped && len(scopedExecutor.registry) == 0 {
-					t.Errorf("expected registry scoped executors to be registered, but got none")
+			if test.matchingArtifact != "" {
+				executor, err := scopedExecutor.matchExecutor(context.Background(), test.matchingArtifact)
+				if err != nil {
+					t.Fatalf("expected executor to match %q, got error: %v", test.matchingArtifact, err)
 				}
-				if test.repositoryScoped && len(scopedExecutor.repository) == 0 {
-					t.Errorf("expected repository scoped executors to be registered, but got none")
+				if executor != test.executor {
+					t.Errorf("expected executor: %v, got: %v", test.executor, executor)
 				}
 			}
 		})
@@ -332,16 +357,22 @@ func TestMatchExecutor(t *testing.T) {
 	e1 := &ratify.Executor{}
 	e2 := &ratify.Executor{}
 	e3 := &ratify.Executor{}
-	scopedExecutor := &ScopedExecutor{
-		wildcard: map[string]*ratify.Executor{
-			"example.com": e1,
-		},
-		registry: map[string]*ratify.Executor{
-			"registry.example.com": e2,
-		},
-		repository: map[string]*ratify.Executor{
-			"registry.example.com/repository/foo": e3,
-		},
+	e4 := &ratify.Executor{}
+	e5 := &ratify.Executor{}
+	e6 := &ratify.Executor{}
+	scopedExecutor := &ScopedExecutor{}
+	scopes := map[string]*ratify.Executor{
+		"*.example.com":                       e1,
+		"registry.example.com":                e2,
+		"registry.example.com/repository/foo": e3,
+		"registry.example.com/team/**":        e4,
+		"registry.example.com/team/*":         e5,
+		"registry.example.com/team/group/**":  e6,
+	}
+	for scope, executor := range scopes {
+		if err := scopedExecutor.registerExecutor(scope, &scopedEntry{executor: executor}); err != nil {
+			t.Fatalf("failed to register executor for scope %q: %v", scope, err)
+		}
 	}
 	tests := []struct {
 		name             string
@@ -373,6 +404,30 @@ func TestMatchExecutor(t *testing.T) {
 			expectedExecutor: e3,
 			expectedError:    false,
 		},
+		{
+			name:             "Match single level prefix over multi level prefix",
+			artifact:         "registry.example.com/team/foo:v1",
+			expectedExecutor: e5,
+			expectedError:    false,
+		},
+		{
+			name:             "Match multi level prefix",
+			artifact:         "registry.example.com/team/foo/bar:v1",
+			expectedExecutor: e4,
+			expectedError:    false,
+		},
+		{
+			name:             "Match longest multi level prefix",
+			artifact:         "registry.example.com/team/group/foo/bar:v1",
+			expectedExecutor: e6,
+			expectedError:    false,
+		},
+		{
+			name:             "Match registry executor for sibling of prefix",
+			artifact:         "registry.example.com/teams/foo:v1",
+			expectedExecutor: e2,
+			expectedError:    false,
+		},
 		{
 			name:             "No match",
 			artifact:         "unknown.com/foo:v1",
@@ -383,7 +438,7 @@ func TestMatchExecutor(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			executor, err := scopedExecutor.matchExecutor(test.artifact)
+			executor, err := scopedExecutor.matchExecutor(context.Background(), test.artifact)
 			if (err != nil) != test.expectedError {
 				t.Errorf("expected error: %v, got: %v", test.expectedError, err)
 			}
@@ -395,10 +450,9 @@ func TestMatchExecutor(t *testing.T) {
 }
 
 func TestValidateArtifact(t *testing.T) {
-	scopedExecutor := &ScopedExecutor{
-		wildcard: map[string]*ratify.Executor{
-			"example.com": {},
-		},
+	scopedExecutor := &ScopedExecutor{}
+	if err := scopedExecutor.registerExecutor("*.example.com", &scopedEntry{executor: &ratify.Executor{}}); err != nil {
+		t.Fatalf("failed to register executor: %v", err)
 	}
 
 	if _, err := scopedExecutor.ValidateArtifact(context.Background(), "unknown.com/foo:v1"); err == nil {
@@ -411,12 +465,9 @@ func TestValidateArtifact(t *testing.T) {
 }
 
 func TestResolve(t *testing.T) {
-	scopedExecutor := &ScopedExecutor{
-		wildcard: map[string]*ratify.Executor{
-			"example.com": {
-				Store: &mockStore{},
-			},
-		},
+	scopedExecutor := &ScopedExecutor{}
+	if err := scopedExecutor.registerExecutor("*.example.com", &scopedEntry{executor: &ratify.Executor{Store: &mockStore{}}}); err != nil {
+		t.Fatalf("failed to register executor: %v", err)
 	}
 
 	if _, err := scopedExecutor.Resolve(context.Background(), "unknown.com/foo:v1"); err == nil {
@@ -427,3 +478,347 @@ func TestResolve(t *testing.T) {
 		t.Error("expected no error for valid artifact with wildcard scope, got:", err)
 	}
 }
+
+func TestValidateArtifact_Exemption(t *testing.T) {
+	store.Register("mock-store-exemption", newMockStore)
+	verifier.Register("mock-verifier-exemption", createMockVerifier)
+
+	scopedExecutor, err := NewScopedExecutor(Options{
+		Executors: []ScopedOptions{
+			{
+				Scopes:        []string{"registry.example.com/**"},
+				ExcludeScopes: []string{"registry.example.com/vendor/*"},
+				Verifiers: []verifier.NewOptions{
+					{
+						Name: mockVerifierName,
+						Type: "mock-verifier-exemption",
+					},
+				},
+				Stores: []store.NewOptions{
+					{
+						Type: "mock-store-exemption",
+					},
+				},
+			},
+			{
+				Scopes:          []string{"vendor.example.com"},
+				EnforcementMode: EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+
+	expectedScopes := []ScopeInfo{
+		{Scope: "registry.example.com/**", EnforcementMode: EnforcementModeEnforce},
+		{Scope: "registry.example.com/vendor/*", Exempt: true},
+		{Scope: "vendor.example.com", Exempt: true},
+	}
+	if scopes := scopedExecutor.Scopes(); !reflect.DeepEqual(scopes, expectedScopes) {
+		t.Errorf("expected scopes: %+v, got: %+v", expectedScopes, scopes)
+	}
+
+	tests := []struct {
+		name          string
+		artifact      string
+		expectExempt  bool
+		expectResolve bool
+	}{
+		{
+			name:          "validated artifact",
+			artifact:      "registry.example.com/team/app:v1",
+			expectExempt:  false,
+			expectResolve: true,
+		},
+		{
+			name:          "excluded artifact",
+			artifact:      "registry.example.com/vendor/app:v1",
+			expectExempt:  true,
+			expectResolve: true,
+		},
+		{
+			name:          "artifact of exempt executor",
+			artifact:      "vendor.example.com/app:v1",
+			expectExempt:  true,
+			expectResolve: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := scopedExecutor.ValidateArtifact(context.Background(), test.artifact)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Exemption != nil) != test.expectExempt {
+				t.Errorf("expected exempt: %v, got: %+v", test.expectExempt, result.Exemption)
+			}
+			if test.expectExempt && !result.Succeeded {
+				t.Errorf("expected exempt artifact to succeed")
+			}
+			if _, err := scopedExecutor.Resolve(context.Background(), test.artifact); (err == nil) != test.expectResolve {
+				t.Errorf("expected resolve: %v, got error: %v", test.expectResolve, err)
+			}
+		})
+	}
+}
+
+func TestNewScopedExecutor_InvalidEnforcementMode(t *testing.T) {
+	_, err := NewScopedExecutor(Options{
+		Executors: []ScopedOptions{
+			{
+				Scopes:          []string{"registry.example.com"},
+				EnforcementMode: "invalid",
+			},
+		},
+	})
+	if err == nil {
+		t.Error("expected error for invalid enforcement mode, got nil")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	store.Register("mock-store-explain", newMockStore)
+	verifier.Register("mock-verifier-explain", createMockVerifier)
+	policyenforcer.Register("mock-policy-enforcer-explain", createPolicyEnforcer)
+
+	scopedExecutor, err := NewScopedExecutor(Options{
+		Executors: []ScopedOptions{
+			{
+				Scopes:        []string{"*.example.com", "registry.example.com/team/*"},
+				ExcludeScopes: []string{"registry.example.com/team/vendor"},
+				Verifiers: []verifier.NewOptions{
+					{
+						Name: mockVerifierName,
+						Type: "mock-verifier-explain",
+					},
+				},
+				Stores: []store.NewOptions{
+					{
+						Type: "mock-store-explain",
+					},
+				},
+				Policy: &policyenforcer.NewOptions{
+					Type: "mock-policy-enforcer-explain",
+				},
+			},
+			{
+				Scopes:          []string{"registry.example.com"},
+				EnforcementMode: EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		artifact string
+		expected *Explanation
+		wantErr  bool
+	}{
+		{
+			name:     "repository prefix",
+			artifact: "registry.example.com/team/app:v1",
+			expected: &Explanation{
+				Scope:           "registry.example.com/team/*",
+				MatchKind:       scope.KindRepository,
+				EnforcementMode: EnforcementModeEnforce,
+				Verifiers:       []string{mockVerifierName},
+				Store: &store.Explanation{
+					Type:      "mock-store-explain",
+					Scope:     "registry.example.com/team/*",
+					MatchKind: scope.KindRepository,
+				},
+				PolicyEnforcerType: "mock-policy-enforcer-explain",
+			},
+		},
+		{
+			name:     "wildcard registry",
+			artifact: "foo.example.com/app:v1",
+			expected: &Explanation{
+				Scope:           "*.example.com",
+				MatchKind:       scope.KindWildcard,
+				EnforcementMode: EnforcementModeEnforce,
+				Verifiers:       []string{mockVerifierName},
+				Store: &store.Explanation{
+					Type:      "mock-store-explain",
+					Scope:     "*.example.com",
+					MatchKind: scope.KindWildcard,
+				},
+				PolicyEnforcerType: "mock-policy-enforcer-explain",
+			},
+		},
+		{
+			name:     "excluded repository",
+			artifact: "registry.example.com/team/vendor:v1",
+			expected: &Explanation{
+				Scope:     "registry.example.com/team/vendor",
+				MatchKind: scope.KindRepository,
+				Exempt:    true,
+				Reason:    `artifact matches excluded scope "registry.example.com/team/vendor"`,
+				Store: &store.Explanation{
+					Type:      "mock-store-explain",
+					Scope:     "registry.example.com/team/*",
+					MatchKind: scope.KindRepository,
+				},
+			},
+		},
+		{
+			name:     "exempt registry",
+			artifact: "registry.example.com/app:v1",
+			expected: &Explanation{
+				Scope:     "registry.example.com",
+				MatchKind: scope.KindRegistry,
+				Exempt:    true,
+				Reason:    `artifact matches scope "registry.example.com" of an exempt executor`,
+			},
+		},
+		{
+			name:     "no matching scope",
+			artifact: "unknown.com/app:v1",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid reference",
+			artifact: "invalid",
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			explanation, err := scopedExecutor.Explain(test.artifact)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error: %v, got: %v", test.wantErr, err)
+			}
+			if !reflect.DeepEqual(explanation, test.expected) {
+				t.Errorf("expected explanation: %+v, got: %+v", test.expected, explanation)
+			}
+		})
+	}
+}
+
+func TestValidateArtifact_EnforcementMode(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            EnforcementMode
+		expectSucceeded bool
+	}{
+		{
+			name:            "enforce mode",
+			mode:            EnforcementModeEnforce,
+			expectSucceeded: false,
+		},
+		{
+			name:            "warn mode",
+			mode:            EnforcementModeWarn,
+			expectSucceeded: true,
+		},
+		{
+			name:            "audit mode",
+			mode:            EnforcementModeAudit,
+			expectSucceeded: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scopedExecutor := &ScopedExecutor{}
+			entry := &scopedEntry{
+				// No policy enforcer is configured, so the validation fails.
+				executor: &ratify.Executor{
+					Store:     &mockStore{},
+					Verifiers: []ratify.Verifier{&mockVerifier{}},
+				},
+				enforcementMode: test.mode,
+			}
+			if err := scopedExecutor.registerExecutor("registry.example.com", entry); err != nil {
+				t.Fatalf("failed to register executor: %v", err)
+			}
+
+			result, err := scopedExecutor.ValidateArtifact(context.Background(), "registry.example.com/app:v1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Succeeded != test.expectSucceeded {
+				t.Errorf("expected succeeded: %v, got: %v", test.expectSucceeded, result.Succeeded)
+			}
+			if result.VerificationSucceeded {
+				t.Errorf("expected verification to fail")
+			}
+			if result.EnforcementMode != test.mode {
+				t.Errorf("expected enforcement mode: %s, got: %s", test.mode, result.EnforcementMode)
+			}
+		})
+	}
+}
+
+func TestCacheGeneration(t *testing.T) {
+	opts := ScopedOptions{
+		Scopes:          []string{"gen.example.com", "other.example.com"},
+		EnforcementMode: EnforcementModeExempt,
+	}
+	unit, err := NewUnit(opts)
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	otherUnit, err := NewUnit(ScopedOptions{Scopes: []string{"team.example.com"}, EnforcementMode: EnforcementModeExempt})
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	first, err := NewScopedExecutorFromUnits([]*Unit{unit, otherUnit})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	// The unit is rebuilt with the same options, e.g. by another replica.
+	rebuiltUnit, err := NewUnit(opts)
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	second, err := NewScopedExecutorFromUnits([]*Unit{rebuiltUnit, otherUnit})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	opts.ExcludeScopes = []string{"gen.example.com/excluded"}
+	reconfiguredUnit, err := NewUnit(opts)
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	third, err := NewScopedExecutorFromUnits([]*Unit{reconfiguredUnit, otherUnit})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	const (
+		artifact      = "gen.example.com/app:v1"
+		otherArtifact = "other.example.com/app:v1"
+		teamArtifact  = "team.example.com/app:v1"
+	)
+	gen, otherGen := first.CacheGeneration(artifact), first.CacheGeneration(otherArtifact)
+	if gen != second.CacheGeneration(artifact) {
+		t.Errorf("expected units of the same options to share the generation, got %s and %s", gen, second.CacheGeneration(artifact))
+	}
+	if gen == third.CacheGeneration(artifact) {
+		t.Errorf("expected the generation to change with the options, got %s", gen)
+	}
+	if first.CacheGeneration(teamArtifact) != third.CacheGeneration(teamArtifact) {
+		t.Errorf("expected the generation of a unit kept across rebuilds to be kept")
+	}
+	if gen == first.CacheGeneration(teamArtifact) {
+		t.Errorf("expected units of different options to differ in generation")
+	}
+
+	cache.Invalidate("v1", "gen.example.com")
+	if first.CacheGeneration(artifact) == gen {
+		t.Errorf("expected the generation to change after the scope is invalidated")
+	}
+	if first.CacheGeneration(otherArtifact) != otherGen {
+		t.Errorf("expected the generation of other scopes to be kept")
+	}
+	if first.CacheGeneration("invalid") != noGeneration {
+		t.Errorf("expected no generation for an invalid artifact, got %s", first.CacheGeneration("invalid"))
+	}
+}
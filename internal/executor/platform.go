@@ -0,0 +1,264 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// mediaTypeDockerManifestList is the media type of a Docker manifest list,
+// the Docker equivalent of an OCI image index.
+const mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+// PlatformMode defines which manifests are validated when the subject is an
+// image index.
+type PlatformMode string
+
+const (
+	// PlatformModeIndex validates the image index only. It is the default
+	// mode.
+	PlatformModeIndex PlatformMode = "index"
+
+	// PlatformModePlatforms validates the child manifests of the image index
+	// matching the configured platforms. Configured platforms missing from the
+	// image index are ignored, but at least one platform must match.
+	PlatformModePlatforms PlatformMode = "platforms"
+
+	// PlatformModeAll validates every platform specific child manifest of the
+	// image index.
+	PlatformModeAll PlatformMode = "all"
+)
+
+// PlatformPolicyOptions defines how image indexes are validated.
+type PlatformPolicyOptions struct {
+	// Mode defines which manifests of an image index are validated. Defaults to
+	// [PlatformModeIndex]. Optional.
+	Mode PlatformMode `json:"mode,omitempty"`
+
+	// Platforms is the list of platforms to validate in the format of
+	// "os/arch" or "os/arch/variant", e.g. "linux/amd64" or "linux/arm64/v8".
+	// Required if Mode is [PlatformModePlatforms].
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// PlatformResult is the result of validating a platform specific manifest of
+// an image index.
+type PlatformResult struct {
+	// Platform is the platform of the manifest in the format of "os/arch" or
+	// "os/arch/variant".
+	Platform string
+
+	// Subject is the reference to the platform specific manifest.
+	Subject string
+
+	// Succeeded indicates whether the manifest passed the validation.
+	Succeeded bool
+
+	// ArtifactReports contains the reports of the artifacts validated against
+	// the manifest.
+	ArtifactReports []*ratify.ValidationReport
+}
+
+// platformPolicy selects the child manifests of an image index to validate.
+type platformPolicy struct {
+	// all indicates whether every platform specific manifest is selected.
+	all bool
+
+	// platforms is the list of platforms to select if all is false.
+	platforms []ocispec.Platform
+}
+
+// newPlatformPolicy creates a platformPolicy from the options. It returns nil
+// if only the image index should be validated.
+func newPlatformPolicy(opts *PlatformPolicyOptions) (*platformPolicy, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	switch opts.Mode {
+	case "", PlatformModeIndex:
+		if len(opts.Platforms) > 0 {
+			return nil, fmt.Errorf("platforms cannot be set for platform mode %q", PlatformModeIndex)
+		}
+		return nil, nil
+	case PlatformModeAll:
+		if len(opts.Platforms) > 0 {
+			return nil, fmt.Errorf("platforms cannot be set for platform mode %q", PlatformModeAll)
+		}
+		return &platformPolicy{all: true}, nil
+	case PlatformModePlatforms:
+		if len(opts.Platforms) == 0 {
+			return nil, fmt.Errorf("at least one platform must be set for platform mode %q", PlatformModePlatforms)
+		}
+		policy := &platformPolicy{
+			platforms: make([]ocispec.Platform, len(opts.Platforms)),
+		}
+		for idx, platform := range opts.Platforms {
+			parsed, err := parsePlatform(platform)
+			if err != nil {
+				return nil, err
+			}
+			policy.platforms[idx] = parsed
+		}
+		return policy, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform mode %q", opts.Mode)
+	}
+}
+
+// parsePlatform parses a platform in the format of "os/arch" or
+// "os/arch/variant".
+func parsePlatform(platform string) (ocispec.Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ocispec.Platform{}, fmt.Errorf("invalid platform %q: expected format os/arch[/variant]", platform)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return ocispec.Platform{}, fmt.Errorf("invalid platform %q: expected format os/arch[/variant]", platform)
+		}
+	}
+	parsed := ocispec.Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+	if len(parts) == 3 {
+		parsed.Variant = parts[2]
+	}
+	return parsed, nil
+}
+
+// formatPlatform formats a platform in the format of "os/arch" or
+// "os/arch/variant".
+func formatPlatform(platform *ocispec.Platform) string {
+	if platform == nil {
+		return ""
+	}
+	formatted := platform.OS + "/" + platform.Architecture
+	if platform.Variant != "" {
+		formatted += "/" + platform.Variant
+	}
+	return formatted
+}
+
+// selectManifests returns the child manifests of an image index selected by
+// the policy. Manifests without a platform or with the "unknown/unknown"
+// platform, such as attestation manifests, are never selected.
+func (p *platformPolicy) selectManifests(manifests []ocispec.Descriptor) []ocispec.Descriptor {
+	var selected []ocispec.Descriptor
+	for _, manifest := range manifests {
+		if manifest.Platform == nil || manifest.Platform.OS == "unknown" {
+			continue
+		}
+		if p.all || p.matches(manifest.Platform) {
+			selected = append(selected, manifest)
+		}
+	}
+	return selected
+}
+
+// matches checks whether the platform matches any configured platform. The
+// variant is only compared if it is configured.
+func (p *platformPolicy) matches(platform *ocispec.Platform) bool {
+	for _, expected := range p.platforms {
+		if expected.OS == platform.OS && expected.Architecture == platform.Architecture &&
+			(expected.Variant == "" || expected.Variant == platform.Variant) {
+			return true
+		}
+	}
+	return false
+}
+
+// isImageIndex checks whether the descriptor refers to an image index.
+func isImageIndex(desc ocispec.Descriptor) bool {
+	return desc.MediaType == ocispec.MediaTypeImageIndex || desc.MediaType == mediaTypeDockerManifestList
+}
+
+// validatePlatforms validates the platform specific manifests of the image
+// index referenced by the artifact. The artifact is validated as is if it does
+// not refer to an image index. Only referrers of the reference types are
+// verified, unless referenceTypes is empty.
+func validatePlatforms(ctx context.Context, executor *ratify.Executor, policy *platformPolicy, artifact string, referenceTypes []string) (*ValidationResult, error) {
+	ref, err := registry.ParseReference(artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse artifact reference %q: %w", artifact, err)
+	}
+	desc, err := executor.Store.Resolve(ctx, artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve artifact %q: %w", artifact, err)
+	}
+	if !isImageIndex(desc) {
+		return validate(ctx, executor, artifact, referenceTypes)
+	}
+
+	repo := ref.Registry + "/" + ref.Repository
+	content, err := executor.Store.FetchManifest(ctx, repo, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image index %s@%s: %w", repo, desc.Digest, err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal image index %s@%s: %w", repo, desc.Digest, err)
+	}
+	manifests := policy.selectManifests(index.Manifests)
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifest in image index %s@%s matches the platform policy", repo, desc.Digest)
+	}
+
+	result := &ValidationResult{
+		Succeeded:       true,
+		PlatformResults: make([]*PlatformResult, len(manifests)),
+	}
+	for idx, manifest := range manifests {
+		ref.Reference = manifest.Digest.String()
+		subject := ref.String()
+		platformResult, err := validate(ctx, executor, subject, referenceTypes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate platform %s of artifact %q: %w", formatPlatform(manifest.Platform), artifact, err)
+		}
+		result.Succeeded = result.Succeeded && platformResult.Succeeded
+		result.PlatformResults[idx] = &PlatformResult{
+			Platform:        formatPlatform(manifest.Platform),
+			Subject:         subject,
+			Succeeded:       platformResult.Succeeded,
+			ArtifactReports: platformResult.ArtifactReports,
+		}
+	}
+	return result, nil
+}
+
+// validate validates the artifact with the executor. Only referrers of the
+// reference types are verified, unless referenceTypes is empty.
+func validate(ctx context.Context, executor *ratify.Executor, artifact string, referenceTypes []string) (*ValidationResult, error) {
+	opts := ratify.ValidateArtifactOptions{
+		Subject:        artifact,
+		ReferenceTypes: referenceTypes,
+	}
+	result, err := executor.ValidateArtifact(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidationResult{
+		Succeeded:       result.Succeeded,
+		ArtifactReports: result.ArtifactReports,
+	}, nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testIndexArtifact = "registry.example.com/app:v1"
+
+var (
+	amd64Manifest = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("amd64"),
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	arm64Manifest = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("arm64"),
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	}
+	attestationManifest = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("attestation"),
+		Platform:  &ocispec.Platform{OS: "unknown", Architecture: "unknown"},
+	}
+)
+
+// indexStore serves an image index with platform specific manifests.
+type indexStore struct {
+	mockStore
+	index     ocispec.Descriptor
+	manifests []ocispec.Descriptor
+	listed    []string
+}
+
+func newIndexStore() *indexStore {
+	content, _ := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{amd64Manifest, arm64Manifest, attestationManifest},
+	})
+	return &indexStore{
+		index: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageIndex,
+			Digest:    digest.FromBytes(content),
+		},
+		manifests: []ocispec.Descriptor{amd64Manifest, arm64Manifest, attestationManifest},
+	}
+}
+
+func (s *indexStore) Resolve(_ context.Context, ref string) (ocispec.Descriptor, error) {
+	for _, manifest := range s.manifests {
+		if strings.HasSuffix(ref, "@"+manifest.Digest.String()) {
+			return manifest, nil
+		}
+	}
+	return s.index, nil
+}
+
+func (s *indexStore) ListReferrers(_ context.Context, ref string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	s.listed = append(s.listed, ref)
+	return nil
+}
+
+func (s *indexStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	if desc.Digest != s.index.Digest {
+		return nil, errors.New("manifest not found")
+	}
+	return json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: s.manifests,
+	})
+}
+
+func TestNewPlatformPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         *PlatformPolicyOptions
+		expectErr    bool
+		expectPolicy *platformPolicy
+	}{
+		{
+			name: "nil options",
+		},
+		{
+			name: "index mode",
+			opts: &PlatformPolicyOptions{Mode: PlatformModeIndex},
+		},
+		{
+			name:      "index mode with platforms",
+			opts:      &PlatformPolicyOptions{Mode: PlatformModeIndex, Platforms: []string{"linux/amd64"}},
+			expectErr: true,
+		},
+		{
+			name:         "all mode",
+			opts:         &PlatformPolicyOptions{Mode: PlatformModeAll},
+			expectPolicy: &platformPolicy{all: true},
+		},
+		{
+			name:      "all mode with platforms",
+			opts:      &PlatformPolicyOptions{Mode: PlatformModeAll, Platforms: []string{"linux/amd64"}},
+			expectErr: true,
+		},
+		{
+			name:      "platforms mode without platforms",
+			opts:      &PlatformPolicyOptions{Mode: PlatformModePlatforms},
+			expectErr: true,
+		},
+		{
+			name:      "platforms mode with invalid platform",
+			opts:      &PlatformPolicyOptions{Mode: PlatformModePlatforms, Platforms: []string{"linux"}},
+			expectErr: true,
+		},
+		{
+			name:      "platforms mode with empty architecture",
+			opts:      &PlatformPolicyOptions{Mode: PlatformModePlatforms, Platforms: []string{"linux/"}},
+			expectErr: true,
+		},
+		{
+			name: "platforms mode",
+			opts: &PlatformPolicyOptions{Mode: PlatformModePlatforms, Platforms: []string{"linux/amd64", "linux/arm64/v8"}},
+			expectPolicy: &platformPolicy{
+				platforms: []ocispec.Platform{
+					{OS: "linux", Architecture: "amd64"},
+					{OS: "linux", Architecture: "arm64", Variant: "v8"},
+				},
+			},
+		},
+		{
+			name:      "unsupported mode",
+			opts:      &PlatformPolicyOptions{Mode: "invalid"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := newPlatformPolicy(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if !reflect.DeepEqual(policy, test.expectPolicy) {
+				t.Errorf("expected policy: %+v, got: %+v", test.expectPolicy, policy)
+			}
+		})
+	}
+}
+
+func TestValidateArtifact_PlatformPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            *platformPolicy
+		expectErr         bool
+		expectedPlatforms []string
+	}{
+		{
+			name:              "index only",
+			policy:            nil,
+			expectedPlatforms: nil,
+		},
+		{
+			name:              "all platforms",
+			policy:            &platformPolicy{all: true},
+			expectedPlatforms: []string{"linux/amd64", "linux/arm64/v8"},
+		},
+		{
+			name: "configured platforms",
+			policy: &platformPolicy{
+				platforms: []ocispec.Platform{
+					{OS: "linux", Architecture: "arm64"},
+					{OS: "windows", Architecture: "amd64"},
+				},
+			},
+			expectedPlatforms: []string{"linux/arm64/v8"},
+		},
+		{
+			name: "no matching platform",
+			policy: &platformPolicy{
+				platforms: []ocispec.Platform{
+					{OS: "windows", Architecture: "amd64"},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newIndexStore()
+			scopedExecutor := &ScopedExecutor{}
+			entry := &scopedEntry{
+				executor: &ratify.Executor{
+					Store:     store,
+					Verifiers: []ratify.Verifier{&mockVerifier{}},
+				},
+				platformPolicy: test.policy,
+			}
+			if err := scopedExecutor.registerExecutor("registry.example.com", entry); err != nil {
+				t.Fatalf("failed to register executor: %v", err)
+			}
+
+			result, err := scopedExecutor.ValidateArtifact(context.Background(), testIndexArtifact)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+
+			var platforms []string
+			for _, platformResult := range result.PlatformResults {
+				platforms = append(platforms, platformResult.Platform)
+				if !strings.HasPrefix(platformResult.Subject, "registry.example.com/app@sha256:") {
+					t.Errorf("unexpected platform subject: %s", platformResult.Subject)
+				}
+			}
+			if !reflect.DeepEqual(platforms, test.expectedPlatforms) {
+				t.Errorf("expected platforms: %v, got: %v", test.expectedPlatforms, platforms)
+			}
+			if test.policy == nil && len(store.listed) != 1 {
+				t.Errorf("expected only the index to be validated, got: %v", store.listed)
+			}
+			if test.policy != nil && len(store.listed) != len(test.expectedPlatforms) {
+				t.Errorf("expected %d manifests to be validated, got: %v", len(test.expectedPlatforms), store.listed)
+			}
+		})
+	}
+}
+
+func TestValidateArtifactWithOptions_Platforms(t *testing.T) {
+	store := newIndexStore()
+	scopedExecutor := &ScopedExecutor{}
+	entry := &scopedEntry{
+		executor: &ratify.Executor{
+			Store:     store,
+			Verifiers: []ratify.Verifier{&mockVerifier{}},
+		},
+	}
+	if err := scopedExecutor.registerExecutor("registry.example.com", entry); err != nil {
+		t.Fatalf("failed to register executor: %v", err)
+	}
+
+	result, err := scopedExecutor.ValidateArtifactWithOptions(context.Background(), testIndexArtifact, ValidateOptions{
+		Platforms: []string{"linux/amd64"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.PlatformResults) != 1 || result.PlatformResults[0].Platform != "linux/amd64" {
+		t.Errorf("expected only linux/amd64 to be validated, got: %+v", result.PlatformResults)
+	}
+
+	if _, err = scopedExecutor.ValidateArtifactWithOptions(context.Background(), testIndexArtifact, ValidateOptions{
+		Platforms: []string{"linux"},
+	}); err == nil {
+		t.Errorf("expected error for invalid platform")
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Timings holds the time taken to validate the nodes of a validation result
+// tree. Each node is a [ratify.ValidationReport] of an artifact verified
+// against its subject. The zero value and nil report no time.
+type Timings struct {
+	mu sync.Mutex
+
+	// verifiers holds the time taken by each verifier of each node.
+	verifiers map[verifierKey]time.Duration
+
+	// verifications holds the time taken by all verifiers of each node.
+	verifications map[nodeKey]time.Duration
+
+	// listings holds the time taken to list the referrers of each subject,
+	// excluding the time taken to verify them.
+	listings map[string]time.Duration
+}
+
+// nodeKey identifies a node of a validation result tree.
+type nodeKey struct {
+	subject  string
+	artifact digest.Digest
+}
+
+// verifierKey identifies a verifier of a node of a validation result tree.
+type verifierKey struct {
+	nodeKey
+	verifier string
+}
+
+// Verifier returns the time taken by the named verifier to verify the artifact
+// of the report against its subject.
+func (t *Timings) Verifier(report *ratify.ValidationReport, verifier string) time.Duration {
+	if t == nil || report == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.verifiers[verifierKey{nodeKey: reportKey(report), verifier: verifier}]
+}
+
+// Node returns the time taken to validate the node of the report, i.e. to
+// verify its artifact against its subject and to list the referrers of its
+// artifact. The time taken by the child nodes is not included.
+func (t *Timings) Node(report *ratify.ValidationReport) time.Duration {
+	if t == nil || report == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := reportKey(report)
+	return t.verifications[key] + t.listings[referrerReference(key.subject, key.artifact)]
+}
+
+// addVerification records the time taken by the verifier of a node.
+func (t *Timings) addVerification(key verifierKey, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.verifiers == nil {
+		t.verifiers = make(map[verifierKey]time.Duration)
+		t.verifications = make(map[nodeKey]time.Duration)
+	}
+	t.verifiers[key] += duration
+	t.verifications[key.nodeKey] += duration
+}
+
+// addListing records the time taken to list the referrers of the subject.
+func (t *Timings) addListing(subject string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listings == nil {
+		t.listings = make(map[string]time.Duration)
+	}
+	t.listings[subject] += duration
+}
+
+// reportKey returns the key of the node of the report.
+func reportKey(report *ratify.ValidationReport) nodeKey {
+	return nodeKey{subject: report.Subject, artifact: report.Artifact.Digest}
+}
+
+// referrerReference returns the reference of the referrer in the repository of
+// the subject reference, in the form the referrers of the referrer are listed
+// with.
+func referrerReference(subject string, referrer digest.Digest) string {
+	repo, _, _ := strings.Cut(subject, "@")
+	return repo + "@" + referrer.String()
+}
+
+// tracker wraps the store and the verifiers of an executor for a single
+// validation, limiting the depth of the referrers validated and recording the
+// time taken by each node of the validation result tree.
+type tracker struct {
+	// maxDepth is the maximum depth of the referrers validated below the
+	// subject. The depth is not limited if not positive.
+	maxDepth int
+
+	mu     sync.Mutex
+	depths map[string]int
+
+	timings *Timings
+}
+
+// newTracker creates a tracker limiting the depth of the referrers validated
+// to maxDepth, if positive.
+func newTracker(maxDepth int) *tracker {
+	return &tracker{
+		maxDepth: maxDepth,
+		depths:   make(map[string]int),
+		timings:  &Timings{},
+	}
+}
+
+// wrap returns a copy of the executor whose store and verifiers report to the
+// tracker.
+func (t *tracker) wrap(executor *ratify.Executor) *ratify.Executor {
+	verifiers := make([]ratify.Verifier, len(executor.Verifiers))
+	for idx, verifier := range executor.Verifiers {
+		verifiers[idx] = &trackedVerifier{Verifier: verifier, tracker: t}
+	}
+	return &ratify.Executor{
+		Store:          &trackedStore{Store: executor.Store, tracker: t},
+		Verifiers:      verifiers,
+		PolicyEnforcer: executor.PolicyEnforcer,
+	}
+}
+
+// depth returns the depth of the referrer reference below the subject
+// validated, which is 0 for the subject itself.
+func (t *tracker) depth(reference string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.depths[reference]
+}
+
+// setDepth records the depth of the referrer reference.
+func (t *tracker) setDepth(reference string, depth int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.depths[reference] = depth
+}
+
+// trackedStore is a [ratify.Store] that stops listing referrers below the
+// maximum depth of its tracker and records the time taken to list them.
+type trackedStore struct {
+	ratify.Store
+	tracker *tracker
+}
+
+// ListReferrers lists the referrers of the subject unless the subject is at
+// the maximum depth, in which case no referrers are listed.
+func (s *trackedStore) ListReferrers(ctx context.Context, reference string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	depth := s.tracker.depth(reference)
+	if s.tracker.maxDepth > 0 && depth >= s.tracker.maxDepth {
+		return nil
+	}
+
+	var verifying time.Duration
+	start := time.Now()
+	err := s.Store.ListReferrers(ctx, reference, artifactTypes, func(referrers []ocispec.Descriptor) error {
+		for _, referrer := range referrers {
+			s.tracker.setDepth(referrerReference(reference, referrer.Digest), depth+1)
+		}
+		fnStart := time.Now()
+		defer func() {
+			verifying += time.Since(fnStart)
+		}()
+		return fn(referrers)
+	})
+	s.tracker.timings.addListing(reference, time.Since(start)-verifying)
+	return err
+}
+
+// trackedVerifier is a [ratify.Verifier] recording the time taken by each
+// verification.
+type trackedVerifier struct {
+	ratify.Verifier
+	tracker *tracker
+}
+
+// Verify verifies the subject against the artifact and records the time taken.
+func (v *trackedVerifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	start := time.Now()
+	result, err := v.Verifier.Verify(ctx, opts)
+	v.tracker.timings.addVerification(verifierKey{
+		nodeKey: nodeKey{
+			subject:  opts.Repository + "@" + opts.SubjectDescriptor.Digest.String(),
+			artifact: opts.ArtifactDescriptor.Digest,
+		},
+		verifier: v.Name(),
+	}, time.Since(start))
+	return result, err
+}
@@ -0,0 +1,142 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testTreeRepo     = "registry.example.com/app"
+	testTreeArtifact = testTreeRepo + ":v1"
+	verifyDelay      = 10 * time.Millisecond
+)
+
+var (
+	testTreeSubject   = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("subject")}
+	testTreeSignature = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("signature")}
+	testTreeSBOM      = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("sbom")}
+)
+
+// treeStore serves a subject referred by a signature, itself referred by an
+// SBOM.
+type treeStore struct {
+	mockStore
+}
+
+func (s *treeStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return testTreeSubject, nil
+}
+
+func (s *treeStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	switch ref {
+	case testTreeRepo + "@" + testTreeSubject.Digest.String():
+		return fn([]ocispec.Descriptor{testTreeSignature})
+	case testTreeRepo + "@" + testTreeSignature.Digest.String():
+		return fn([]ocispec.Descriptor{testTreeSBOM})
+	}
+	return nil
+}
+
+// slowVerifier takes verifyDelay to verify an artifact.
+type slowVerifier struct {
+	mockVerifier
+}
+
+func (v *slowVerifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	time.Sleep(verifyDelay)
+	return v.mockVerifier.Verify(ctx, opts)
+}
+
+func newTreeExecutor(t *testing.T) *ScopedExecutor {
+	t.Helper()
+	scopedExecutor := &ScopedExecutor{}
+	entry := &scopedEntry{
+		executor: &ratify.Executor{
+			Store:     &treeStore{},
+			Verifiers: []ratify.Verifier{&slowVerifier{}},
+		},
+	}
+	if err := scopedExecutor.registerExecutor("registry.example.com", entry); err != nil {
+		t.Fatalf("failed to register executor: %v", err)
+	}
+	return scopedExecutor
+}
+
+func TestValidateArtifactWithOptions_MaxDepth(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxDepth      int
+		expectedDepth int
+	}{
+		{name: "unlimited", maxDepth: 0, expectedDepth: 2},
+		{name: "direct referrers", maxDepth: 1, expectedDepth: 1},
+		{name: "beyond the tree", maxDepth: 5, expectedDepth: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := newTreeExecutor(t).ValidateArtifactWithOptions(context.Background(), testTreeArtifact, ValidateOptions{
+				MaxDepth: test.maxDepth,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			depth := 0
+			for reports := result.ArtifactReports; len(reports) > 0; reports = reports[0].ArtifactReports {
+				depth++
+			}
+			if depth != test.expectedDepth {
+				t.Errorf("expected reports of depth %d, got %d", test.expectedDepth, depth)
+			}
+		})
+	}
+}
+
+func TestValidateArtifactWithOptions_Timings(t *testing.T) {
+	result, err := newTreeExecutor(t).ValidateArtifactWithOptions(context.Background(), testTreeArtifact, ValidateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.ArtifactReports) != 1 || len(result.ArtifactReports[0].ArtifactReports) != 1 {
+		t.Fatalf("expected a signature referred by an SBOM, got: %+v", result.ArtifactReports)
+	}
+	signature := result.ArtifactReports[0]
+	sbom := signature.ArtifactReports[0]
+	for _, report := range []*ratify.ValidationReport{signature, sbom} {
+		verified := result.Timings.Verifier(report, mockVerifierName)
+		if verified < verifyDelay {
+			t.Errorf("expected verifier of %s to take at least %v, got %v", report.Artifact.Digest, verifyDelay, verified)
+		}
+		// The time taken by the child nodes is not counted in the node.
+		if node := result.Timings.Node(report); node < verified || node >= verified+verifyDelay {
+			t.Errorf("expected node %s to take about %v, got %v", report.Artifact.Digest, verified, node)
+		}
+	}
+	if got := result.Timings.Verifier(signature, "unknown"); got != 0 {
+		t.Errorf("expected no time for an unknown verifier, got %v", got)
+	}
+
+	var timings *Timings
+	if timings.Node(signature) != 0 || timings.Verifier(signature, mockVerifierName) != 0 {
+		t.Errorf("expected nil timings to report no time")
+	}
+}
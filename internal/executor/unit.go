@@ -0,0 +1,173 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+)
+
+// Unit is the set of components built from a single [ScopedOptions], i.e. the
+// verifiers, stores, policy enforcer and platform policy of an executor
+// together with the scopes routed to it.
+//
+// A Unit is immutable once created. It can be reused by multiple
+// [ScopedExecutor] instances so that rebuilding a [ScopedExecutor] after one
+// ScopedOptions changes does not recreate the components of the others, which
+// keeps their key providers and credential caches warm.
+type Unit struct {
+	registrations []registration
+}
+
+// unitGenerationLength is the length of the generation of a unit, a prefix of
+// the hex encoded hash of its options.
+const unitGenerationLength = 16
+
+// registration is a scope of a unit and the entry registered for it.
+type registration struct {
+	scope string
+	entry *scopedEntry
+}
+
+// NewUnit creates the components of an executor from the options. It returns
+// an error if any component fails to be created or if the scopes of the
+// options are invalid or conflict with each other.
+func NewUnit(opts ScopedOptions) (*Unit, error) {
+	if len(opts.Scopes) == 0 {
+		return nil, fmt.Errorf("executor options must contain at least one scope")
+	}
+	unit := &Unit{}
+	switch opts.EnforcementMode {
+	case EnforcementModeExempt:
+		for _, scope := range opts.Scopes {
+			reason := fmt.Sprintf("artifact matches scope %q of an exempt executor", scope)
+			unit.add(scope, newExemptionEntry(scope, nil, reason))
+		}
+	case "", EnforcementModeEnforce, EnforcementModeWarn, EnforcementModeAudit:
+		executor, err := newExecutor(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create executor: %w", err)
+		}
+		platformPolicy, err := newPlatformPolicy(opts.PlatformPolicy)
+		if err != nil {
+			closeVerifiers(executor.Verifiers)
+			return nil, fmt.Errorf("failed to create platform policy: %w", err)
+		}
+		entry := &scopedEntry{
+			executor:        executor,
+			platformPolicy:  platformPolicy,
+			enforcementMode: opts.EnforcementMode,
+		}
+		if entry.enforcementMode == "" {
+			entry.enforcementMode = EnforcementModeEnforce
+		}
+		if opts.Policy != nil {
+			entry.policyEnforcerType = opts.Policy.Type
+		}
+		for _, scope := range opts.Scopes {
+			unit.add(scope, entry)
+		}
+		for _, scope := range opts.ExcludeScopes {
+			reason := fmt.Sprintf("artifact matches excluded scope %q", scope)
+			unit.add(scope, newExemptionEntry(scope, executor, reason))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported enforcement mode %q", opts.EnforcementMode)
+	}
+
+	generation, err := unitGeneration(opts)
+	if err != nil {
+		unit.Close()
+		return nil, err
+	}
+	for _, reg := range unit.registrations {
+		reg.entry.generation = generation
+	}
+
+	// Register the scopes on their own so that invalid scopes are reported
+	// against the options they belong to.
+	if err := (&ScopedExecutor{}).registerUnit(unit); err != nil {
+		unit.Close()
+		return nil, err
+	}
+	return unit, nil
+}
+
+// Close releases the background resources of the verifiers of the unit, e.g.
+// stops refreshing their key providers. It must be called once the unit is
+// replaced or removed. The unit still validates artifacts once closed, so
+// that validations in flight are not affected.
+func (u *Unit) Close() {
+	seen := make(map[*ratify.Executor]struct{})
+	for _, reg := range u.registrations {
+		executor := reg.entry.executor
+		if executor == nil {
+			continue
+		}
+		if _, ok := seen[executor]; ok {
+			continue
+		}
+		seen[executor] = struct{}{}
+		closeVerifiers(executor.Verifiers)
+	}
+}
+
+// Scopes returns the scopes registered by the unit, including excluded scopes.
+func (u *Unit) Scopes() []string {
+	scopes := make([]string, len(u.registrations))
+	for idx, reg := range u.registrations {
+		scopes[idx] = reg.scope
+	}
+	return scopes
+}
+
+// add adds the entry registered for the scope.
+func (u *Unit) add(scope string, entry *scopedEntry) {
+	u.registrations = append(u.registrations, registration{
+		scope: scope,
+		entry: entry,
+	})
+}
+
+// unitGeneration derives the generation of a unit from its options, so that
+// results cached by a unit are never served by a unit with other options, while
+// replicas running units of the same options share their cached results.
+func unitGeneration(opts ScopedOptions) (string, error) {
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal executor options: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])[:unitGenerationLength], nil
+}
+
+// closeVerifiers closes the verifiers holding background resources.
+func closeVerifiers(verifiers []ratify.Verifier) {
+	for _, verifier := range verifiers {
+		if closer, ok := verifier.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.GetLogger(context.Background(), logOpt).Warnf("failed to close verifier %s: %v", verifier.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store"
+	"github.com/notaryproject/ratify/v2/internal/verifier"
+)
+
+func newUnitOptions(scopes ...string) ScopedOptions {
+	return ScopedOptions{
+		Scopes: scopes,
+		Verifiers: []verifier.NewOptions{
+			{
+				Name: mockVerifierName,
+				Type: "mock-verifier-unit",
+			},
+		},
+		Stores: []store.NewOptions{
+			{
+				Type: "mock-store-unit",
+			},
+		},
+	}
+}
+
+func TestNewUnit(t *testing.T) {
+	store.Register("mock-store-unit", newMockStore)
+	verifier.Register("mock-verifier-unit", createMockVerifier)
+
+	tests := []struct {
+		name        string
+		opts        ScopedOptions
+		errContains string
+	}{
+		{
+			name: "valid options",
+			opts: newUnitOptions("registry.example.com"),
+		},
+		{
+			name:        "no scopes",
+			opts:        newUnitOptions(),
+			errContains: "at least one scope",
+		},
+		{
+			name:        "duplicate scopes",
+			opts:        newUnitOptions("registry.example.com", "registry.example.com"),
+			errContains: "duplicate registry scope",
+		},
+		{
+			name: "excluded scope conflicts with scope",
+			opts: func() ScopedOptions {
+				opts := newUnitOptions("registry.example.com")
+				opts.ExcludeScopes = []string{"registry.example.com"}
+				return opts
+			}(),
+			errContains: "failed to register excluded scope",
+		},
+		{
+			name: "invalid exempt scope",
+			opts: ScopedOptions{
+				Scopes:          []string{"registry.example.com/repo:tag"},
+				EnforcementMode: EnforcementModeExempt,
+			},
+			errContains: "failed to register exempt scope",
+		},
+		{
+			name: "unsupported enforcement mode",
+			opts: func() ScopedOptions {
+				opts := newUnitOptions("registry.example.com")
+				opts.EnforcementMode = "invalid"
+				return opts
+			}(),
+			errContains: "unsupported enforcement mode",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unit, err := NewUnit(test.opts)
+			if test.errContains == "" {
+				if err != nil || unit == nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.errContains) {
+				t.Errorf("expected error containing %q, got: %v", test.errContains, err)
+			}
+		})
+	}
+}
+
+func TestNewScopedExecutorFromUnits(t *testing.T) {
+	store.Register("mock-store-units", newMockStore)
+	verifier.Register("mock-verifier-units", createMockVerifier)
+	newOpts := func(scope string) ScopedOptions {
+		opts := newUnitOptions(scope)
+		opts.Verifiers[0].Type = "mock-verifier-units"
+		opts.Stores[0].Type = "mock-store-units"
+		return opts
+	}
+
+	if _, err := NewScopedExecutorFromUnits(nil); err == nil {
+		t.Fatal("expected error for no units")
+	}
+
+	unit1, err := NewUnit(newOpts("registry1.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	unit2, err := NewUnit(newOpts("registry2.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	conflicting, err := NewUnit(newOpts("registry1.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+
+	first, err := NewScopedExecutorFromUnits([]*Unit{unit1, unit2})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+	second, err := NewScopedExecutorFromUnits([]*Unit{unit1})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+	firstExecutor, err := first.matchExecutor(context.Background(), "registry1.example.com/app:v1")
+	if err != nil {
+		t.Fatalf("failed to match executor: %v", err)
+	}
+	secondExecutor, err := second.matchExecutor(context.Background(), "registry1.example.com/app:v1")
+	if err != nil {
+		t.Fatalf("failed to match executor: %v", err)
+	}
+	if firstExecutor != secondExecutor {
+		t.Error("expected executors sharing a unit to reuse its components")
+	}
+	if _, err := second.matchExecutor(context.Background(), "registry2.example.com/app:v1"); err == nil {
+		t.Error("expected no executor for scope of a unit not included")
+	}
+
+	if _, err := NewScopedExecutorFromUnits([]*Unit{unit1, conflicting}); err == nil {
+		t.Error("expected error for units with conflicting scopes")
+	}
+}
+
+// closingVerifier counts the times it is closed.
+type closingVerifier struct {
+	mockVerifier
+	closed *atomic.Int32
+}
+
+func (v *closingVerifier) Close() error {
+	v.closed.Add(1)
+	return nil
+}
+
+func TestUnitClose(t *testing.T) {
+	var closed atomic.Int32
+	store.Register("mock-store-close", newMockStore)
+	verifier.Register("mock-verifier-close", func(_ verifier.NewOptions, _ []string) (ratify.Verifier, error) {
+		return &closingVerifier{closed: &closed}, nil
+	})
+	newOpts := func(scope string) ScopedOptions {
+		opts := newUnitOptions(scope)
+		opts.Verifiers[0].Type = "mock-verifier-close"
+		opts.Stores[0].Type = "mock-store-close"
+		opts.ExcludeScopes = []string{scope + "/excluded"}
+		return opts
+	}
+
+	unit, err := NewUnit(newOpts("registry.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create unit: %v", err)
+	}
+	unit.Close()
+	if got := closed.Load(); got != 1 {
+		t.Errorf("expected the verifier shared by the scopes to be closed once, got %d", got)
+	}
+
+	// The units created by an executor are closed with it.
+	closed.Store(0)
+	scopedExecutor, err := NewScopedExecutor(Options{
+		Executors: []ScopedOptions{newOpts("registry1.example.com"), newOpts("registry2.example.com")},
+	})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+	scopedExecutor.Close()
+	if got := closed.Load(); got != 2 {
+		t.Errorf("expected the verifiers of both units to be closed, got %d", got)
+	}
+
+	// The units created before a failure are closed.
+	closed.Store(0)
+	if _, err := NewScopedExecutor(Options{
+		Executors: []ScopedOptions{newOpts("registry1.example.com"), newOpts("registry1.example.com")},
+	}); err == nil {
+		t.Fatal("expected error for executors with conflicting scopes")
+	}
+	if got := closed.Load(); got != 2 {
+		t.Errorf("expected the verifiers of the created units to be closed, got %d", got)
+	}
+}
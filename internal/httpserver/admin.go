@@ -0,0 +1,170 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+)
+
+const (
+	adminRootURL = "/ratify/admin/v1"
+	cachesPath   = "caches"
+
+	// cacheError is the name of the cache of errors in the admin endpoint.
+	cacheError = "error"
+)
+
+// adminCache is the part of [cache.Cache] managed by the admin endpoint,
+// which does not depend on the type of the cached values.
+type adminCache interface {
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+	Stats(ctx context.Context) (cache.Stats, error)
+}
+
+// registerAdminHandlers registers the handlers of the admin endpoint, which
+// are served by a separate listener restricted to authenticated clients.
+func (s *server) registerAdminHandlers() error {
+	cachesURL, err := url.JoinPath(adminRootURL, cachesPath)
+	if err != nil {
+		return err
+	}
+	s.adminRouter.Use(tracing.Middleware, middlewareWithTraceID)
+	s.adminRouter.Methods(http.MethodGet).Path(cachesURL).Handler(s.cacheStatsHandler())
+	s.adminRouter.Methods(http.MethodDelete).Path(cachesURL).Handler(s.purgeCachesHandler())
+	return nil
+}
+
+func (s *server) cacheStatsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.cacheStats(r.Context(), w)
+	}
+}
+
+func (s *server) purgeCachesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.purgeCaches(r.Context(), w, r)
+	}
+}
+
+// cacheStats handles the cache statistics request. It returns the statistics
+// of each cache of the server.
+func (s *server) cacheStats(ctx context.Context, w http.ResponseWriter) error {
+	stats := cacheStatistics{Caches: make(map[string]cache.Stats)}
+	for name, c := range s.adminCaches() {
+		cacheStats, err := c.Stats(ctx)
+		if err != nil {
+			return sendJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("failed to get stats of the %s cache: %v", name, err)})
+		}
+		stats.Caches[name] = cacheStats
+	}
+	return sendJSON(w, http.StatusOK, stats)
+}
+
+// purgeCaches handles the cache purge request. It removes the cached results
+// of the subject given by the "subject" query parameter, of the subjects in
+// the repository given by the "repository" query parameter and in the
+// repositories nested under its path, or of all subjects if the "all" query
+// parameter is true. Exactly one of them must be set.
+//
+// Verification results are cached by the digest reference of the subject
+// while pinned and resolved references are cached by the reference to pin, so
+// that both references may need to be purged to force the re-verification of a
+// tag.
+func (s *server) purgeCaches(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	subject, repository := query.Get("subject"), query.Get("repository")
+	var all bool
+	if value := query.Get("all"); value != "" {
+		var err error
+		if all, err = strconv.ParseBool(value); err != nil {
+			return sendJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid query parameter \"all\": %v", err)})
+		}
+	}
+	var selectors int
+	for _, set := range []bool{subject != "", repository != "", all} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return sendJSON(w, http.StatusBadRequest, errorResponse{Error: "exactly one of the query parameters \"subject\", \"repository\" or \"all\" is required"})
+	}
+
+	var keys, prefixes []string
+	switch {
+	case subject != "":
+		for _, key := range []string{verifyKey(subject), mutateKey(subject), resolveKey(subject)} {
+			keys = append(keys, key)
+			prefixes = append(prefixes, key+generationKeySuffix, key+optionsKeySuffix)
+		}
+	case repository != "":
+		// Match the references of the repository and of the repositories
+		// nested under its path, not of the repositories sharing its name as
+		// a prefix.
+		for _, key := range []string{verifyKey(repository), mutateKey(repository), resolveKey(repository)} {
+			prefixes = append(prefixes, key+"@", key+":", key+"/")
+		}
+	default:
+		prefixes = []string{""}
+	}
+
+	purge := cachePurge{Deleted: make(map[string]int)}
+	for name, c := range s.adminCaches() {
+		for _, key := range keys {
+			if err := c.Delete(ctx, key); err != nil {
+				return sendJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("failed to purge the %s cache: %v", name, err)})
+			}
+		}
+		for _, prefix := range prefixes {
+			deleted, err := c.DeletePrefix(ctx, prefix)
+			if err != nil {
+				return sendJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("failed to purge the %s cache: %v", name, err)})
+			}
+			purge.Deleted[name] += deleted
+		}
+	}
+	logger.GetLogger(ctx, logOpt).Infof("purged caches (subject=%q, repository=%q, all=%t): %v", subject, repository, all, purge.Deleted)
+	return sendJSON(w, http.StatusOK, purge)
+}
+
+// adminCaches returns the caches of the server managed by the admin endpoint
+// by name.
+func (s *server) adminCaches() map[string]adminCache {
+	caches := make(map[string]adminCache, 4)
+	if s.verifyCache != nil {
+		caches[metrics.CacheVerify] = s.verifyCache
+	}
+	if s.mutateCache != nil {
+		caches[metrics.CacheMutate] = s.mutateCache
+	}
+	if s.validationCache != nil {
+		caches[metrics.CacheValidation] = s.validationCache
+	}
+	if s.errorCache != nil {
+		caches[cacheError] = s.errorCache
+	}
+	return caches
+}
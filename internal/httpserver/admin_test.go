@@ -0,0 +1,189 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+)
+
+const adminCachesURL = adminRootURL + "/" + cachesPath
+
+func newAdminTestServer(t *testing.T) *server {
+	t.Helper()
+	s := &server{
+		adminRouter: mux.NewRouter(),
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			"verify_example.com/app@sha256:1_gen=1.0":                               {Succeeded: true},
+			"verify_example.com/app@sha256:2_gen=1.0":                               {Succeeded: true},
+			"verify_example.com/app-other@sha256:3_gen=1.0":                         {Succeeded: true},
+			"verify_example.com/app/nested@sha256:5_gen=1.0":                        {Succeeded: true},
+			"verify_other.com/app@sha256:4_gen=1.0":                                 {Succeeded: true},
+			"verify_example.com/app@sha256:10_gen=1.0":                              {Succeeded: true},
+			"verify_example.com/app@sha256:1":                                       {Succeeded: true},
+			"verify_example.com/app@sha256:1_platforms=linux/amd64_referenceTypes=": {Succeeded: true},
+		}},
+		mutateCache: &mockCache{entries: map[string]string{
+			"mutate_example.com/app:v1_gen=1.0":         "example.com/app@sha256:1",
+			"mutate_example.com/app-other:v1_gen=1.0":   "example.com/app-other@sha256:3",
+			"resolve_example.com/app/nested:v1_gen=1.0": "example.com/app/nested@sha256:5",
+		}},
+		validationCache: &mockValidationCache{entries: map[string]*artifactValidation{}},
+		errorCache: &mockCache{entries: map[string]string{
+			"mutate_example.com/app:v2_gen=1.0": "failed",
+		}},
+	}
+	if err := s.registerAdminHandlers(); err != nil {
+		t.Fatalf("failed to register admin handlers: %v", err)
+	}
+	return s
+}
+
+func TestCacheStats(t *testing.T) {
+	s := newAdminTestServer(t)
+	w := httptest.NewRecorder()
+	s.adminRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, adminCachesURL, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var stats cacheStatistics
+	if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := map[string]cache.Stats{
+		"verify":     {Entries: 8},
+		"mutate":     {Entries: 3},
+		"validation": {Entries: 0},
+		"error":      {Entries: 1},
+	}
+	if !reflect.DeepEqual(stats.Caches, expected) {
+		t.Errorf("expected stats %+v, got %+v", expected, stats.Caches)
+	}
+}
+
+func TestPurgeCaches(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedDeleted map[string]int
+		remainingVerify []string
+	}{
+		{
+			name:           "no selector",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "multiple selectors",
+			query:          "?subject=example.com/app:v1&all=true",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid all",
+			query:          "?all=invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "all false",
+			query:          "?all=false",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "exact subject",
+			query:           "?subject=example.com/app@sha256:1",
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: map[string]int{"verify": 2, "mutate": 0, "validation": 0, "error": 0},
+			remainingVerify: []string{
+				"verify_example.com/app-other@sha256:3_gen=1.0",
+				"verify_example.com/app/nested@sha256:5_gen=1.0",
+				"verify_example.com/app@sha256:10_gen=1.0",
+				"verify_example.com/app@sha256:2_gen=1.0",
+				"verify_other.com/app@sha256:4_gen=1.0",
+			},
+		},
+		{
+			name:            "repository",
+			query:           "?repository=example.com/app",
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: map[string]int{"verify": 6, "mutate": 2, "validation": 0, "error": 1},
+			remainingVerify: []string{
+				"verify_example.com/app-other@sha256:3_gen=1.0",
+				"verify_other.com/app@sha256:4_gen=1.0",
+			},
+		},
+		{
+			name:            "tag subject",
+			query:           "?subject=example.com/app:v1",
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: map[string]int{"verify": 0, "mutate": 1, "validation": 0, "error": 0},
+			remainingVerify: []string{
+				"verify_example.com/app-other@sha256:3_gen=1.0",
+				"verify_example.com/app/nested@sha256:5_gen=1.0",
+				"verify_example.com/app@sha256:1",
+				"verify_example.com/app@sha256:10_gen=1.0",
+				"verify_example.com/app@sha256:1_gen=1.0",
+				"verify_example.com/app@sha256:1_platforms=linux/amd64_referenceTypes=",
+				"verify_example.com/app@sha256:2_gen=1.0",
+				"verify_other.com/app@sha256:4_gen=1.0",
+			},
+		},
+		{
+			name:            "all",
+			query:           "?all=true",
+			expectedStatus:  http.StatusOK,
+			expectedDeleted: map[string]int{"verify": 8, "mutate": 3, "validation": 0, "error": 1},
+			remainingVerify: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAdminTestServer(t)
+			w := httptest.NewRecorder()
+			s.adminRouter.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, adminCachesURL+tt.query, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var purge cachePurge
+			if err := json.NewDecoder(w.Body).Decode(&purge); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(purge.Deleted, tt.expectedDeleted) {
+				t.Errorf("expected deleted %v, got %v", tt.expectedDeleted, purge.Deleted)
+			}
+			remaining := []string{}
+			for key := range s.verifyCache.(*mockResultCache).entries {
+				remaining = append(remaining, key)
+			}
+			sort.Strings(remaining)
+			if !reflect.DeepEqual(remaining, tt.remainingVerify) {
+				t.Errorf("expected remaining verify entries %v, got %v", tt.remainingVerify, remaining)
+			}
+		})
+	}
+}
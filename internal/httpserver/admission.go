@@ -0,0 +1,314 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	e "github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// validateAdmission handles the AdmissionReview requests of the validating
+// admission webhook. The request is denied if any image of the workload fails
+// the validation.
+func (s *server) validateAdmission(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return reviewWorkload(w, r, func(_ string, podSpec *corev1.PodSpec, response *admissionv1.AdmissionResponse) error {
+		s.reviewImages(ctx, podSpec, response)
+		return nil
+	})
+}
+
+// reviewImages denies the admission if any image in the pod spec fails the
+// validation.
+func (s *server) reviewImages(ctx context.Context, podSpec *corev1.PodSpec, response *admissionv1.AdmissionResponse) {
+	start := time.Now()
+	items := s.verifyArtifacts(ctx, podImages(podSpec))
+	metrics.ReportRequest(ctx, metrics.HandlerAdmissionValidate, len(items), time.Since(start))
+
+	var denials []string
+	for _, item := range items {
+		if item.Error != "" {
+			denials = append(denials, fmt.Sprintf("image %s: %s", item.Key, item.Error))
+			continue
+		}
+		rendered, ok := item.Value.(*result)
+		if !ok || rendered == nil {
+			denials = append(denials, fmt.Sprintf("image %s: no validation result", item.Key))
+			continue
+		}
+		if !rendered.Succeeded {
+			denials = append(denials, fmt.Sprintf("image %s failed verification", item.Key))
+			continue
+		}
+		if rendered.EnforcementMode == string(e.EnforcementModeWarn) && rendered.VerificationSucceeded != nil && !*rendered.VerificationSucceeded {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("image %s failed verification and would be denied if the executor was not in warn mode", item.Key))
+		}
+	}
+	if len(denials) > 0 {
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Code:    http.StatusForbidden,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: "Ratify denied the request: " + strings.Join(denials, "; "),
+		}
+	}
+}
+
+// mutateAdmission handles the AdmissionReview requests of the mutating
+// admission webhook. It pins the images of the workload to their digests with
+// a JSONPatch. Images that cannot be resolved are left unchanged and reported
+// as warnings, so that the validating admission webhook decides whether to
+// admit them.
+func (s *server) mutateAdmission(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return reviewWorkload(w, r, func(kind string, podSpec *corev1.PodSpec, response *admissionv1.AdmissionResponse) error {
+		return s.pinImages(ctx, kind, podSpec, response)
+	})
+}
+
+// pinImages patches the images in the pod spec of a workload of the given kind
+// to refer to their digests.
+func (s *server) pinImages(ctx context.Context, kind string, podSpec *corev1.PodSpec, response *admissionv1.AdmissionResponse) error {
+	start := time.Now()
+	var patches []jsonPatchOperation
+	pinned := make(map[string]string)
+	pin := func(path, image string) {
+		if image == "" {
+			return
+		}
+		resolved, ok := pinned[image]
+		if !ok {
+			var warning string
+			resolved, warning = s.pinImage(ctx, image)
+			if warning != "" {
+				response.Warnings = append(response.Warnings, warning)
+			}
+			pinned[image] = resolved
+		}
+		if resolved != image {
+			patches = append(patches, jsonPatchOperation{
+				Op:    "replace",
+				Path:  path,
+				Value: resolved,
+			})
+		}
+	}
+	basePath := podSpecPath(kind)
+	for idx, container := range podSpec.InitContainers {
+		pin(fmt.Sprintf("%s/initContainers/%d/image", basePath, idx), container.Image)
+	}
+	for idx, container := range podSpec.Containers {
+		pin(fmt.Sprintf("%s/containers/%d/image", basePath, idx), container.Image)
+	}
+	for idx, container := range podSpec.EphemeralContainers {
+		pin(fmt.Sprintf("%s/ephemeralContainers/%d/image", basePath, idx), container.Image)
+	}
+	metrics.ReportRequest(ctx, metrics.HandlerAdmissionMutate, len(pinned), time.Since(start))
+
+	if len(patches) > 0 {
+		patch, err := json.Marshal(patches)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON patch: %w", err)
+		}
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+	return nil
+}
+
+// jsonPatchOperation is an operation of a JSONPatch as defined in RFC 6902.
+type jsonPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// pinImage resolves the image to its digest. If KeepTagOnMutation is set, the
+// tag is kept in the form of "repo:tag@digest". The image is returned as is
+// along with a warning if it cannot be resolved. Images already referring to a
+// digest are not changed.
+func (s *server) pinImage(ctx context.Context, image string) (string, string) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return image, fmt.Sprintf("failed to pin image %s to a digest: %v", image, err)
+	}
+	if _, err = ref.Digest(); err == nil {
+		return image, ""
+	}
+
+	item := s.resolveReference(ctx, image)
+	if item.Error != "" {
+		return image, fmt.Sprintf("failed to pin image %s to a digest: %s", image, item.Error)
+	}
+	resolved, ok := item.Value.(string)
+	if !ok {
+		return image, fmt.Sprintf("failed to pin image %s to a digest: unexpected resolved reference %v", image, item.Value)
+	}
+	if !s.KeepTagOnMutation {
+		return resolved, ""
+	}
+	resolvedRef, err := registry.ParseReference(resolved)
+	if err != nil {
+		return image, fmt.Sprintf("failed to pin image %s to a digest: %v", image, err)
+	}
+	return fmt.Sprintf("%s/%s:%s@%s", ref.Registry, ref.Repository, ref.Reference, resolvedRef.Reference), ""
+}
+
+// podSpecPath returns the JSON pointer to the pod spec of a workload of the
+// given kind.
+func podSpecPath(kind string) string {
+	switch kind {
+	case "Pod":
+		return "/spec"
+	case "CronJob":
+		return "/spec/jobTemplate/spec/template/spec"
+	default:
+		return "/spec/template/spec"
+	}
+}
+
+// reviewWorkload decodes the AdmissionReview in the request and responds to
+// it. Unless the workload is deleted or has no pod spec, the response is
+// populated by review. The request is allowed by default.
+func reviewWorkload(w http.ResponseWriter, r *http.Request, review func(kind string, podSpec *corev1.PodSpec, response *admissionv1.AdmissionResponse) error) error {
+	admissionReview, err := decodeAdmissionReview(r)
+	if err != nil {
+		return sendJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+	}
+	request := admissionReview.Request
+	response := &admissionv1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+	admissionReview.Request = nil
+	admissionReview.Response = response
+
+	if request.Operation == admissionv1.Delete {
+		return sendJSON(w, http.StatusOK, admissionReview)
+	}
+	podSpec, err := extractPodSpec(request.Kind.Kind, request.Object.Raw)
+	if err != nil {
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+		return sendJSON(w, http.StatusOK, admissionReview)
+	}
+	if podSpec != nil {
+		if err = review(request.Kind.Kind, podSpec, response); err != nil {
+			return err
+		}
+	}
+	return sendJSON(w, http.StatusOK, admissionReview)
+}
+
+// decodeAdmissionReview decodes the AdmissionReview in the request body.
+func decodeAdmissionReview(r *http.Request) (*admissionv1.AdmissionReview, error) {
+	defer r.Body.Close()
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		return nil, fmt.Errorf("failed to decode admission review: %w", err)
+	}
+	if review.Request == nil {
+		return nil, fmt.Errorf("admission review contains no request")
+	}
+	return &review, nil
+}
+
+// extractPodSpec returns the pod spec of a workload of the given kind. It
+// returns nil if the kind does not contain a pod spec.
+func extractPodSpec(kind string, raw []byte) (*corev1.PodSpec, error) {
+	switch kind {
+	case "Pod":
+		var pod corev1.Pod
+		if err := json.Unmarshal(raw, &pod); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &pod.Spec, nil
+	case "Deployment":
+		var deployment appsv1.Deployment
+		if err := json.Unmarshal(raw, &deployment); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &deployment.Spec.Template.Spec, nil
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := json.Unmarshal(raw, &statefulSet); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &statefulSet.Spec.Template.Spec, nil
+	case "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := json.Unmarshal(raw, &daemonSet); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &daemonSet.Spec.Template.Spec, nil
+	case "Job":
+		var job batchv1.Job
+		if err := json.Unmarshal(raw, &job); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &job.Spec.Template.Spec, nil
+	case "CronJob":
+		var cronJob batchv1.CronJob
+		if err := json.Unmarshal(raw, &cronJob); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", kind, err)
+		}
+		return &cronJob.Spec.JobTemplate.Spec.Template.Spec, nil
+	default:
+		return nil, nil
+	}
+}
+
+// podImages returns the distinct images of the containers, init containers
+// and ephemeral containers in the pod spec.
+func podImages(spec *corev1.PodSpec) []string {
+	var images []string
+	seen := make(map[string]struct{})
+	add := func(image string) {
+		if image == "" {
+			return
+		}
+		if _, ok := seen[image]; ok {
+			return
+		}
+		seen[image] = struct{}{}
+		images = append(images, image)
+	}
+	for _, container := range spec.InitContainers {
+		add(container.Image)
+	}
+	for _, container := range spec.Containers {
+		add(container.Image)
+	}
+	for _, container := range spec.EphemeralContainers {
+		add(container.Image)
+	}
+	return images
+}
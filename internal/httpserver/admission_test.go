@@ -0,0 +1,327 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/executor"
+	"golang.org/x/sync/singleflight"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newAdmissionReview(t *testing.T, kind string, operation admissionv1.Operation, object string) string {
+	t.Helper()
+	review := map[string]any{
+		"apiVersion": "admission.k8s.io/v1",
+		"kind":       "AdmissionReview",
+		"request": map[string]any{
+			"uid":       "test-uid",
+			"kind":      map[string]string{"group": "", "version": "v1", "kind": kind},
+			"operation": operation,
+			"object":    json.RawMessage(object),
+		},
+	}
+	raw, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("failed to marshal admission review: %v", err)
+	}
+	return string(raw)
+}
+
+func TestValidateAdmission(t *testing.T) {
+	exemptExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	verificationFailed := false
+
+	tests := []struct {
+		name             string
+		requestBody      string
+		expectedCode     int
+		expectedAllowed  bool
+		expectedMessage  string
+		expectedWarnings []string
+	}{
+		{
+			name:         "invalid request body",
+			requestBody:  `{invalid-json}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing request",
+			requestBody:  `{"apiVersion": "admission.k8s.io/v1", "kind": "AdmissionReview"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:            "delete operation",
+			requestBody:     newAdmissionReview(t, "Pod", admissionv1.Delete, `{}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: true,
+		},
+		{
+			name:            "kind without pod spec",
+			requestBody:     newAdmissionReview(t, "ConfigMap", admissionv1.Create, `{"data": {"key": "value"}}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: true,
+		},
+		{
+			name:            "malformed object",
+			requestBody:     newAdmissionReview(t, "Pod", admissionv1.Create, `{"spec": "invalid"}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: false,
+			expectedMessage: "failed to decode Pod",
+		},
+		{
+			name:            "all images allowed",
+			requestBody:     newAdmissionReview(t, "Deployment", admissionv1.Create, `{"spec": {"template": {"spec": {"containers": [{"name": "app", "image": "example.com/app:v1"}], "initContainers": [{"name": "init", "image": "example.com/init:v1"}]}}}}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: true,
+		},
+		{
+			name:            "image failed validation",
+			requestBody:     newAdmissionReview(t, "Pod", admissionv1.Create, `{"spec": {"containers": [{"name": "app", "image": "example.com/app:v1"}, {"name": "sidecar", "image": "other.com/sidecar:v1"}]}}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: false,
+			expectedMessage: "Ratify denied the request: image other.com/sidecar:v1: failed to match executor",
+		},
+		{
+			name:            "image failed verification",
+			requestBody:     newAdmissionReview(t, "CronJob", admissionv1.Update, `{"spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"name": "job", "image": "cached.com/denied:v1"}]}}}}}}`),
+			expectedCode:    http.StatusOK,
+			expectedAllowed: false,
+			expectedMessage: "Ratify denied the request: image cached.com/denied:v1 failed verification",
+		},
+		{
+			name:             "warn mode",
+			requestBody:      newAdmissionReview(t, "Job", admissionv1.Create, `{"spec": {"template": {"spec": {"containers": [{"name": "job", "image": "cached.com/warned:v1"}]}}}}`),
+			expectedCode:     http.StatusOK,
+			expectedAllowed:  true,
+			expectedWarnings: []string{"image cached.com/warned:v1 failed verification and would be denied if the executor was not in warn mode"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return exemptExecutor
+				},
+				verifyCache: &mockResultCache{entries: map[string]*result{
+					generationKey(exemptExecutor, verifyKey("cached.com/denied:v1"), "cached.com/denied:v1"): {
+						Succeeded: false,
+					},
+					generationKey(exemptExecutor, verifyKey("cached.com/warned:v1"), "cached.com/warned:v1"): {
+						Succeeded:             true,
+						EnforcementMode:       "warn",
+						VerificationSucceeded: &verificationFailed,
+					},
+				}},
+				validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+				sfGroup:         new(singleflight.Group),
+			}
+			req := httptest.NewRequest(http.MethodPost, "/ratify/admission/v1/validate", strings.NewReader(test.requestBody))
+			w := httptest.NewRecorder()
+			if err := server.validateAdmission(context.Background(), w, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != test.expectedCode {
+				t.Fatalf("expected status code %d, got %d", test.expectedCode, w.Code)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var review admissionv1.AdmissionReview
+			if err := json.NewDecoder(w.Body).Decode(&review); err != nil {
+				t.Fatalf("failed to decode admission review: %v", err)
+			}
+			if review.Request != nil || review.Response == nil {
+				t.Fatalf("expected only the response to be set, got: %+v", review)
+			}
+			if review.APIVersion != "admission.k8s.io/v1" || review.Kind != "AdmissionReview" {
+				t.Errorf("unexpected type meta: %+v", review.TypeMeta)
+			}
+			response := review.Response
+			if response.UID != "test-uid" {
+				t.Errorf("expected UID test-uid, got %s", response.UID)
+			}
+			if response.Allowed != test.expectedAllowed {
+				t.Errorf("expected allowed: %v, got: %v", test.expectedAllowed, response.Allowed)
+			}
+			if test.expectedMessage != "" && (response.Result == nil || !strings.HasPrefix(response.Result.Message, test.expectedMessage)) {
+				t.Errorf("expected message with prefix %q, got: %+v", test.expectedMessage, response.Result)
+			}
+			if !reflect.DeepEqual(response.Warnings, test.expectedWarnings) {
+				t.Errorf("expected warnings: %v, got: %v", test.expectedWarnings, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestPodImages(t *testing.T) {
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Image: "example.com/init:v1"},
+		},
+		Containers: []corev1.Container{
+			{Image: "example.com/app:v1"},
+			{Image: "example.com/init:v1"},
+		},
+		EphemeralContainers: []corev1.EphemeralContainer{
+			{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Image: "example.com/debug:v1"}},
+		},
+	}
+	expected := []string{"example.com/init:v1", "example.com/app:v1", "example.com/debug:v1"}
+	if images := podImages(spec); !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected images: %v, got: %v", expected, images)
+	}
+}
+
+func TestExtractPodSpec(t *testing.T) {
+	tests := []struct {
+		kind   string
+		object string
+	}{
+		{kind: "Pod", object: `{"spec": {"containers": [{"image": "example.com/app:v1"}]}}`},
+		{kind: "Deployment", object: `{"spec": {"template": {"spec": {"containers": [{"image": "example.com/app:v1"}]}}}}`},
+		{kind: "StatefulSet", object: `{"spec": {"template": {"spec": {"containers": [{"image": "example.com/app:v1"}]}}}}`},
+		{kind: "DaemonSet", object: `{"spec": {"template": {"spec": {"containers": [{"image": "example.com/app:v1"}]}}}}`},
+		{kind: "Job", object: `{"spec": {"template": {"spec": {"containers": [{"image": "example.com/app:v1"}]}}}}`},
+		{kind: "CronJob", object: `{"spec": {"jobTemplate": {"spec": {"template": {"spec": {"containers": [{"image": "example.com/app:v1"}]}}}}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			spec, err := extractPodSpec(test.kind, []byte(test.object))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if images := podImages(spec); !reflect.DeepEqual(images, []string{"example.com/app:v1"}) {
+				t.Errorf("unexpected images: %v", images)
+			}
+		})
+	}
+}
+
+func TestMutateAdmission(t *testing.T) {
+	const (
+		appDigest  = "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+		initDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	)
+	deployment := `{"spec": {"template": {"spec": {
+		"initContainers": [{"name": "init", "image": "example.com/init:v1"}],
+		"containers": [
+			{"name": "app", "image": "example.com/app:v1"},
+			{"name": "pinned", "image": "example.com/app@` + appDigest + `"},
+			{"name": "unknown", "image": "other.com/app:v1"},
+			{"name": "copy", "image": "example.com/app:v1"}
+		]
+	}}}}`
+	pod := `{"spec": {"ephemeralContainers": [{"name": "debug", "image": "example.com/app:v1"}]}}`
+
+	tests := []struct {
+		name             string
+		requestBody      string
+		keepTag          bool
+		expectedPatch    []jsonPatchOperation
+		expectedWarnings []string
+	}{
+		{
+			name:        "pin images to digests",
+			requestBody: newAdmissionReview(t, "Deployment", admissionv1.Create, deployment),
+			expectedPatch: []jsonPatchOperation{
+				{Op: "replace", Path: "/spec/template/spec/initContainers/0/image", Value: "example.com/init@" + initDigest},
+				{Op: "replace", Path: "/spec/template/spec/containers/0/image", Value: "example.com/app@" + appDigest},
+				{Op: "replace", Path: "/spec/template/spec/containers/3/image", Value: "example.com/app@" + appDigest},
+			},
+			expectedWarnings: []string{"failed to pin image other.com/app:v1 to a digest: failed to match executor for artifact \"other.com/app:v1\": no executor configured for the artifact \"other.com/app:v1\""},
+		},
+		{
+			name:        "keep tags",
+			requestBody: newAdmissionReview(t, "Pod", admissionv1.Update, pod),
+			keepTag:     true,
+			expectedPatch: []jsonPatchOperation{
+				{Op: "replace", Path: "/spec/ephemeralContainers/0/image", Value: "example.com/app:v1@" + appDigest},
+			},
+		},
+		{
+			name:        "nothing to pin",
+			requestBody: newAdmissionReview(t, "ConfigMap", admissionv1.Create, `{}`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return &executor.ScopedExecutor{}
+				},
+				mutateCache: &mockCache{entries: map[string]string{
+					"mutate_example.com/app:v1_gen=0":  "example.com/app@" + appDigest,
+					"mutate_example.com/init:v1_gen=0": "example.com/init@" + initDigest,
+				}},
+				sfGroup: new(singleflight.Group),
+				ServerOptions: ServerOptions{
+					KeepTagOnMutation: test.keepTag,
+				},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/ratify/admission/v1/mutate", strings.NewReader(test.requestBody))
+			w := httptest.NewRecorder()
+			if err := server.mutateAdmission(context.Background(), w, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var review admissionv1.AdmissionReview
+			if err := json.NewDecoder(w.Body).Decode(&review); err != nil {
+				t.Fatalf("failed to decode admission review: %v", err)
+			}
+			response := review.Response
+			if response == nil || !response.Allowed {
+				t.Fatalf("expected the request to be allowed, got: %+v", response)
+			}
+			var patch []jsonPatchOperation
+			if response.Patch != nil {
+				if err := json.Unmarshal(response.Patch, &patch); err != nil {
+					t.Fatalf("failed to decode patch: %v", err)
+				}
+				if response.PatchType == nil || *response.PatchType != admissionv1.PatchTypeJSONPatch {
+					t.Errorf("expected JSONPatch patch type, got: %v", response.PatchType)
+				}
+			}
+			if !reflect.DeepEqual(patch, test.expectedPatch) {
+				t.Errorf("expected patch: %+v, got: %+v", test.expectedPatch, patch)
+			}
+			if !reflect.DeepEqual(response.Warnings, test.expectedWarnings) {
+				t.Errorf("expected warnings: %v, got: %v", test.expectedWarnings, response.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/factory"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+)
+
+const defaultCacheTTL = 5 * time.Second
+
+// CacheOptions configures a cache of the server.
+type CacheOptions struct {
+	// Disabled indicates whether the cache is turned off. If set, every
+	// request is served without looking up or filling the cache.
+	// Optional.
+	Disabled bool
+
+	// Type is the type of the cache, one of "ristretto", "inmemory" and
+	// "redis". A Redis cache is shared by the replicas of the server and
+	// falls back to a ristretto cache while Redis is unreachable. Default is
+	// "ristretto" if not specified.
+	// Optional.
+	Type string
+
+	// TTL is the duration successful results are cached for. Default is 5
+	// seconds if not specified.
+	// Optional.
+	TTL time.Duration
+
+	// FailureTTL is the duration failed results and errors are cached for.
+	// If not specified, failed results are cached for TTL and errors are not
+	// cached. Errors are only cached by the Gatekeeper provider endpoints.
+	// Optional.
+	FailureTTL time.Duration
+
+	// MaxCost is the maximum number of entries kept in the cache. Default is
+	// the default maximum cost of the ristretto cache if not specified.
+	// Optional.
+	MaxCost int64
+
+	// StaleWhileRevalidate is the grace period after the TTL during which
+	// the last successful result of a digest is served while it is
+	// revalidated in the background. Stale results are not served after the
+	// grace period. Only used by the verify cache.
+	// Optional.
+	StaleWhileRevalidate time.Duration
+}
+
+// resultTTL returns the duration a result is cached for. Zero stands for the
+// default TTL of the cache.
+func (o CacheOptions) resultTTL(succeeded bool) time.Duration {
+	if !succeeded && o.FailureTTL > 0 {
+		return o.FailureTTL
+	}
+	return o.TTL
+}
+
+// newCache creates the cache of the given name configured by the options. The
+// Redis options are only used by a Redis cache.
+func newCache[T any](name string, opts CacheOptions, redisOpts redis.Options) (cache.Cache[T], error) {
+	if opts.Disabled {
+		return noopCache[T]{}, nil
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	return factory.NewCache[T](factory.Options{
+		Type:    opts.Type,
+		Name:    name,
+		TTL:     ttl,
+		MaxCost: opts.MaxCost,
+		Redis:   redisOpts,
+	})
+}
+
+// newStaleCache creates the cache of the given name configured by the options,
+// which serves stale entries within the StaleWhileRevalidate grace period if
+// set.
+func newStaleCache[T any](name string, opts CacheOptions, redisOpts redis.Options) (cache.Cache[T], error) {
+	if opts.Disabled || opts.StaleWhileRevalidate <= 0 {
+		return newCache[T](name, opts, redisOpts)
+	}
+	underlying, err := newCache[cache.StaleEntry[T]](name, opts, redisOpts)
+	if err != nil {
+		return nil, err
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	return cache.NewStaleCache(underlying, ttl, opts.StaleWhileRevalidate)
+}
+
+// staleGetter is implemented by the caches serving stale entries, i.e.
+// [cache.StaleCache].
+type staleGetter[T any] interface {
+	GetStale(ctx context.Context, key string) (T, bool, error)
+}
+
+// getStale returns the value of the key and whether it is stale. Stale values
+// are only returned by caches serving them.
+func getStale[T any](ctx context.Context, c cache.Cache[T], key string) (T, bool, error) {
+	if staleCache, ok := c.(staleGetter[T]); ok {
+		return staleCache.GetStale(ctx, key)
+	}
+	value, err := c.Get(ctx, key)
+	return value, false, err
+}
+
+// newErrorCache creates the cache of errors, which is only needed if any of
+// the options caches errors. It is configured by the first options caching
+// errors, so that errors are shared by the replicas like the results of a
+// Redis cache. Each error is cached for the failure TTL of its options.
+func newErrorCache(redisOpts redis.Options, opts ...CacheOptions) (cache.Cache[string], error) {
+	for _, opt := range opts {
+		if !opt.Disabled && opt.FailureTTL > 0 {
+			opt.TTL = opt.FailureTTL
+			return newCache[string](cacheError, opt, redisOpts)
+		}
+	}
+	return noopCache[string]{}, nil
+}
+
+// cachedError returns the cached error message of the key, if any.
+func (s *server) cachedError(ctx context.Context, key string) (string, bool) {
+	if s.errorCache == nil {
+		return "", false
+	}
+	msg, err := s.errorCache.Get(ctx, key)
+	return msg, err == nil && msg != ""
+}
+
+// cacheError caches the error of the key for the failure TTL of the options.
+// Errors are not cached if the failure TTL is not set, nor are the errors of
+// cancelled or timed out requests, as they are specific to the request.
+func (s *server) cacheError(ctx context.Context, key string, err error, opts CacheOptions) {
+	if s.errorCache == nil || opts.Disabled || opts.FailureTTL <= 0 ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if err := s.errorCache.Set(ctx, key, err.Error(), opts.FailureTTL); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to cache error of %s: %v", key, err)
+	}
+}
+
+// noopCache is the cache of a disabled cache, which never holds any entry.
+type noopCache[T any] struct{}
+
+// Get always returns [cache.ErrNotFound].
+func (noopCache[T]) Get(context.Context, string) (T, error) {
+	var zero T
+	return zero, cache.ErrNotFound
+}
+
+// Set discards the value.
+func (noopCache[T]) Set(context.Context, string, T, time.Duration) error {
+	return nil
+}
+
+// Delete does nothing.
+func (noopCache[T]) Delete(context.Context, string) error {
+	return nil
+}
+
+// DeletePrefix does nothing.
+func (noopCache[T]) DeletePrefix(context.Context, string) (int, error) {
+	return 0, nil
+}
+
+// Stats returns empty statistics.
+func (noopCache[T]) Stats(context.Context) (cache.Stats, error) {
+	return cache.Stats{}, nil
+}
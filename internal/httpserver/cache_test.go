@@ -0,0 +1,158 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
+)
+
+func TestNewCache(t *testing.T) {
+	disabled, err := newCache[string]("test", CacheOptions{Disabled: true, TTL: time.Minute}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create disabled cache: %v", err)
+	}
+	ctx := context.Background()
+	if err := disabled.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("failed to set disabled cache: %v", err)
+	}
+	if _, err := disabled.Get(ctx, "key"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("expected a disabled cache to never hold entries, got: %v", err)
+	}
+
+	if _, err := newCache[string]("test", CacheOptions{TTL: -time.Second}, redis.Options{}); err == nil {
+		t.Errorf("expected error for negative TTL")
+	}
+	if _, err := newCache[string]("test", CacheOptions{MaxCost: -1}, redis.Options{}); err == nil {
+		t.Errorf("expected error for negative max cost")
+	}
+	enabled, err := newCache[string]("test", CacheOptions{MaxCost: 10}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if err := enabled.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("failed to set cache: %v", err)
+	}
+	if value, err := enabled.Get(ctx, "key"); err != nil || value != "value" {
+		t.Errorf("expected cached value, got: %q, %v", value, err)
+	}
+
+	inMemory, err := newCache[string]("test", CacheOptions{Type: "inmemory", MaxCost: 10}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create in-memory cache: %v", err)
+	}
+	if _, ok := inMemory.(*inmemory.Cache[string]); !ok {
+		t.Errorf("expected an in-memory cache, got: %T", inMemory)
+	}
+	if _, err := newCache[string]("test", CacheOptions{Type: "redis"}, redis.Options{}); err == nil {
+		t.Errorf("expected error for a redis cache without address")
+	}
+	if _, err := newCache[string]("test", CacheOptions{Type: "unknown"}, redis.Options{}); err == nil {
+		t.Errorf("expected error for an unsupported cache type")
+	}
+}
+
+func TestNewStaleCache(t *testing.T) {
+	fresh, err := newStaleCache[string]("test", CacheOptions{}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if _, ok := fresh.(staleGetter[string]); ok {
+		t.Errorf("expected a cache without grace period not to serve stale entries")
+	}
+	disabled, err := newStaleCache[string]("test", CacheOptions{Disabled: true, StaleWhileRevalidate: time.Minute}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	if _, ok := disabled.(noopCache[string]); !ok {
+		t.Errorf("expected a disabled cache, got: %T", disabled)
+	}
+
+	stale, err := newStaleCache[string]("test", CacheOptions{TTL: time.Millisecond, StaleWhileRevalidate: time.Minute}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	ctx := context.Background()
+	if err := stale.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("failed to set cache: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if value, isStale, err := getStale(ctx, stale, "key"); err != nil || !isStale || value != "value" {
+		t.Errorf("expected stale value, got: %q, stale %t, %v", value, isStale, err)
+	}
+}
+
+func TestNewErrorCache(t *testing.T) {
+	noop, err := newErrorCache(redis.Options{}, CacheOptions{TTL: time.Minute}, CacheOptions{Disabled: true, FailureTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create error cache: %v", err)
+	}
+	if _, ok := noop.(noopCache[string]); !ok {
+		t.Errorf("expected no error cache if no options cache errors, got: %T", noop)
+	}
+
+	// The error cache is configured by the first options caching errors.
+	inMemory, err := newErrorCache(redis.Options{}, CacheOptions{}, CacheOptions{Type: "inmemory", FailureTTL: time.Minute, MaxCost: 10})
+	if err != nil {
+		t.Fatalf("failed to create error cache: %v", err)
+	}
+	if _, ok := inMemory.(*inmemory.Cache[string]); !ok {
+		t.Errorf("expected an in-memory error cache, got: %T", inMemory)
+	}
+	if _, err := newErrorCache(redis.Options{}, CacheOptions{Type: "redis", FailureTTL: time.Minute}); err == nil {
+		t.Errorf("expected error for a redis error cache without address")
+	}
+}
+
+func TestCacheOptions_ResultTTL(t *testing.T) {
+	opts := CacheOptions{TTL: time.Minute, FailureTTL: time.Second}
+	if ttl := opts.resultTTL(true); ttl != time.Minute {
+		t.Errorf("expected TTL of a successful result to be %v, got: %v", time.Minute, ttl)
+	}
+	if ttl := opts.resultTTL(false); ttl != time.Second {
+		t.Errorf("expected TTL of a failed result to be %v, got: %v", time.Second, ttl)
+	}
+	opts.FailureTTL = 0
+	if ttl := opts.resultTTL(false); ttl != time.Minute {
+		t.Errorf("expected failed results to be cached for TTL by default, got: %v", ttl)
+	}
+}
+
+func TestCacheError(t *testing.T) {
+	errorCache := &mockCache{entries: make(map[string]string)}
+	s := &server{errorCache: errorCache}
+	ctx := context.Background()
+
+	s.cacheError(ctx, "no-ttl", errors.New("failed"), CacheOptions{})
+	s.cacheError(ctx, "disabled", errors.New("failed"), CacheOptions{Disabled: true, FailureTTL: time.Second})
+	s.cacheError(ctx, "timeout", context.DeadlineExceeded, CacheOptions{FailureTTL: time.Second})
+	s.cacheError(ctx, "cached", errors.New("failed"), CacheOptions{FailureTTL: time.Second})
+	if len(errorCache.entries) != 1 || errorCache.entries["cached"] != "failed" {
+		t.Errorf("expected only the error with a failure TTL to be cached, got: %v", errorCache.entries)
+	}
+	if msg, ok := s.cachedError(ctx, "cached"); !ok || msg != "failed" {
+		t.Errorf("expected cached error, got: %q, %v", msg, ok)
+	}
+	if _, ok := (&server{}).cachedError(ctx, "cached"); ok {
+		t.Errorf("expected no cached error without an error cache")
+	}
+}
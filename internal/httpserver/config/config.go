@@ -46,6 +46,11 @@ type Watcher struct {
 	watcher            *fsnotify.Watcher
 	executor           atomic.Pointer[executor.ScopedExecutor]
 	executorConfigPath string
+
+	// reloadErr is the error of the last reload. It is nil if the last reload
+	// succeeded or no reload happened yet.
+	reloadErrMutex sync.RWMutex
+	reloadErr      error
 }
 
 // NewWatcher creates a new Watcher instance.
@@ -82,7 +87,9 @@ func (w *Watcher) loadExecutor() error {
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
-	w.executor.Store(e)
+	if previous := w.executor.Swap(e); previous != nil {
+		previous.Close()
+	}
 	return nil
 }
 
@@ -92,6 +99,28 @@ func (w *Watcher) GetExecutor() *executor.ScopedExecutor {
 	return w.executor.Load()
 }
 
+// ConfigPath returns the path of the watched configuration file.
+func (w *Watcher) ConfigPath() string {
+	return w.executorConfigPath
+}
+
+// LastReloadError returns the error of the last reload of the configuration
+// file. The executor loaded before is kept serving if a reload fails. It
+// returns nil if the last reload succeeded or no reload happened yet.
+// It is safe to call this method concurrently.
+func (w *Watcher) LastReloadError() error {
+	w.reloadErrMutex.RLock()
+	defer w.reloadErrMutex.RUnlock()
+	return w.reloadErr
+}
+
+// setReloadError records the error of the last reload.
+func (w *Watcher) setReloadError(err error) {
+	w.reloadErrMutex.Lock()
+	defer w.reloadErrMutex.Unlock()
+	w.reloadErr = err
+}
+
 // Start begins watching the executor configuration file for changes.
 func (w *Watcher) Start() error {
 	logrus.Infof("Starting executor configuration watcher at %s", w.executorConfigPath)
@@ -113,9 +142,11 @@ func (w *Watcher) Start() error {
 							logrus.Errorf("error re-watching file: %v", err)
 						}
 					}
-					if err := w.loadExecutor(); err != nil {
+					err := w.loadExecutor()
+					if err != nil {
 						logrus.Errorf("failed to reload config: %v", err)
 					}
+					w.setReloadError(err)
 				}
 			case err, ok := <-w.watcher.Errors:
 				// If the watcher is closed, exit the loop.
@@ -134,6 +165,9 @@ func (w *Watcher) Stop() {
 	if err := w.watcher.Close(); err != nil {
 		logrus.Errorf("failed to close watcher: %v", err)
 	}
+	if e := w.executor.Load(); e != nil {
+		e.Close()
+	}
 }
 
 func getConfigurationFile(configFilePath string) string {
@@ -194,3 +194,31 @@ func TestGetExecutor(t *testing.T) {
 		assert.NotNil(t, executor)
 	})
 }
+
+func TestLastReloadError(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(configPath, []byte(validConfig), 0600)
+	assert.NoError(t, err)
+
+	watcher, err := NewWatcher(configPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, configPath, watcher.ConfigPath())
+	assert.NoError(t, watcher.LastReloadError())
+
+	assert.NoError(t, watcher.Start())
+	defer watcher.Stop()
+
+	executor := watcher.GetExecutor()
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"executors":`), 0600))
+	assert.Eventually(t, func() bool {
+		return watcher.LastReloadError() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Same(t, executor, watcher.GetExecutor())
+
+	assert.NoError(t, os.WriteFile(configPath, []byte(validConfig), 0600))
+	assert.Eventually(t, func() bool {
+		return watcher.LastReloadError() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -22,14 +22,33 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	e "github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
 	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
-	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2/registry"
 )
 
+var logOpt = logger.Option{ComponentType: logger.Server}
+
+// errorCodeTimeout prefixes the error of an artifact whose validation did not
+// complete within its deadline, so that policies can tell a timeout apart
+// from a failed verification.
+const errorCodeTimeout = "timeout"
+
+// errValidationTimeout is the error of an artifact whose validation did not
+// complete within its deadline. The validation continues in the background
+// and fills the cache for the next request.
+var errValidationTimeout = fmt.Errorf("%s: validation did not complete in time and continues in the background: %w", errorCodeTimeout, context.DeadlineExceeded)
+
 // verify handles the verification request from Gatekeeper.
 func (s *server) verify(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	start := time.Now()
 	defer r.Body.Close()
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -41,48 +60,348 @@ func (s *server) verify(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return fmt.Errorf("failed to unmarshal request body to provider request: %w", err)
 	}
 
-	results := make([]externaldata.Item, len(providerRequest.Request.Keys))
-	for idx, artifact := range providerRequest.Request.Keys {
+	results := s.verifyArtifacts(ctx, providerRequest.Request.Keys)
+	metrics.ReportRequest(ctx, metrics.HandlerVerify, len(results), time.Since(start))
+	return sendResponse(results, w, http.StatusOK, false)
+}
+
+// verifyArtifacts validates the artifacts with the current executor. Tagged
+// artifacts are pinned to the digests they resolve to, so that results are
+// cached by digest. Cached results and errors are served first and the
+// remaining artifacts are validated in parallel, each within its own
+// deadline, which covers the pinning of the artifact. Each returned item holds
+// either the rendered [result] or an error.
+func (s *server) verifyArtifacts(ctx context.Context, artifacts []string) []externaldata.Item {
+	executor := s.getExecutor()
+	pinnedArtifacts := s.pinArtifacts(ctx, executor, artifacts)
+
+	results := make([]externaldata.Item, len(artifacts))
+	var missedIndexes []int
+	var missedArtifacts []string
+	// missedDeadlines holds the earliest deadline of the pinned artifacts
+	// missing from the cache, if any was derived while pinning them.
+	missedDeadlines := make(map[string]time.Time)
+	for idx, artifact := range artifacts {
 		results[idx] = externaldata.Item{
 			Key: artifact,
 		}
-		key := verifyKey(artifact)
+		pinned := pinnedArtifacts[idx]
+		if pinned.err != nil {
+			results[idx].Error = pinned.err.Error()
+			continue
+		}
 
 		// Fetch the cache value first.
-		result, err := s.verifyCache.Get(ctx, key)
-		if err == nil && result != nil {
+		key := generationKey(executor, verifyKey(pinned.artifact), pinned.artifact)
+		result, stale, err := getStale(ctx, s.verifyCache, key)
+		hit := err == nil && result != nil
+		if hit && stale {
+			// Only the last successful result of an immutable digest is
+			// served stale, while it is revalidated in the background.
+			if hit = result.Succeeded && isDigestReference(pinned.artifact); hit {
+				s.revalidate(ctx, executor, pinned.artifact)
+			}
+		}
+		metrics.ReportCacheLookup(ctx, metrics.CacheVerify, hit)
+		if hit {
+			_, span := tracing.StartSpan(ctx, "ratify.verify", tracing.AttributeArtifact.String(artifact), tracing.AttributeCached.Bool(true))
+			span.SetAttributes(tracing.AttributeSucceeded.Bool(result.Succeeded))
+			span.End()
 			results[idx].Value = result
 			continue
 		}
-
-		// Cache is missed, block multiple goroutines from validating the same
-		// artifact.
-		val, err, _ := s.sfGroup.Do(key, func() (any, error) {
-			executor := s.getExecutor()
-			if executor == nil {
-				return nil, errors.New("no valid executor configured")
+		if msg, ok := s.cachedError(ctx, key); ok {
+			results[idx].Error = msg
+			continue
+		}
+		missedIndexes = append(missedIndexes, idx)
+		missedArtifacts = append(missedArtifacts, pinned.artifact)
+		if !pinned.deadline.IsZero() {
+			if existing, ok := missedDeadlines[pinned.artifact]; !ok || pinned.deadline.Before(existing) {
+				missedDeadlines[pinned.artifact] = pinned.deadline
 			}
-			result, err := executor.ValidateArtifact(ctx, artifact)
+		}
+	}
+
+	if executor == nil {
+		for _, idx := range missedIndexes {
+			results[idx].Error = "no valid executor configured"
+		}
+		return results
+	}
+
+	// Validate the artifacts missing from the cache in parallel.
+	artifactResults := executor.ValidateArtifacts(ctx, missedArtifacts, e.ValidateArtifactsOptions{
+		MaxConcurrency: s.VerifyConcurrency,
+		Validate: func(ctx context.Context, artifact string) (result *e.ValidationResult, err error) {
+			ctx, span := tracing.StartSpan(ctx, "ratify.verify", tracing.AttributeArtifact.String(artifact), tracing.AttributeCached.Bool(false))
+			defer func() { tracing.EndSpan(span, err) }()
+
+			outcome, err := s.validateWithDeadline(ctx, executor, artifact, missedDeadlines[artifact])
 			if err != nil {
 				return nil, err
 			}
-			renderedResult := convertResult(result)
-			if err = s.verifyCache.Set(ctx, key, renderedResult, 0); err != nil {
-				logrus.Warnf("failed to set verify cache for image %s: %v", artifact, err)
+			span.SetAttributes(tracing.AttributeSucceeded.Bool(outcome.result.Succeeded))
+			return outcome.result, nil
+		},
+	})
+	validatedResults := make([]*e.ArtifactResult, 0, len(artifactResults))
+	for idx, artifactResult := range artifactResults {
+		item := &results[missedIndexes[idx]]
+		if errors.Is(artifactResult.Err, errValidationTimeout) {
+			item.Error = artifactResult.Err.Error()
+			continue
+		}
+		validatedResults = append(validatedResults, artifactResult)
+		if artifactResult.Err != nil {
+			item.Error = artifactResult.Err.Error()
+			s.cacheError(ctx, generationKey(executor, verifyKey(artifactResult.Artifact), artifactResult.Artifact), artifactResult.Err, s.VerifyCache)
+			continue
+		}
+		item.Value = convertResult(artifactResult.Result)
+	}
+	// Only artifacts validated by this request are compared, so that each
+	// artifact is validated by the shadow executor at most once per cache TTL.
+	s.startShadowComparison(ctx, validatedResults)
+
+	for _, item := range results {
+		if rendered, ok := item.Value.(*result); ok {
+			s.reportWouldBeDenial(ctx, item.Key, rendered)
+		}
+	}
+	return results
+}
+
+// revalidate validates the artifact in the background to refresh its stale
+// result in the verify cache. Concurrent revalidations of the same artifact
+// are deduplicated by the singleflight group of the validation.
+func (s *server) revalidate(ctx context.Context, executor *e.ScopedExecutor, artifact string) {
+	ctx, cancel := s.backgroundContext(ctx)
+	s.backgroundValidations.Add(1)
+	go func() {
+		defer s.backgroundValidations.Done()
+		defer cancel()
+		if _, err := s.validateArtifact(ctx, executor, artifact, e.ValidateOptions{}); err != nil {
+			logger.GetLogger(ctx, logOpt).Warnf("failed to revalidate artifact %s, serving its stale result: %v", artifact, err)
+		}
+	}()
+}
+
+// backgroundContext returns a context detached from the request for the
+// validations outliving it, bounded by the verification timeout.
+func (s *server) backgroundContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := s.VerifyTimeout
+	if timeout <= 0 {
+		timeout = defaultVerifyTimeout
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), timeout)
+}
+
+// isDigestReference reports whether the artifact is a reference to a digest.
+func isDigestReference(artifact string) bool {
+	ref, err := registry.ParseReference(artifact)
+	if err != nil {
+		return false
+	}
+	_, err = ref.Digest()
+	return err == nil
+}
+
+// pinnedArtifact is an artifact of a verification request pinned to the
+// digest it resolves to.
+type pinnedArtifact struct {
+	// artifact is the artifact pinned to its digest, or as requested if it is
+	// already pinned or exempt from validation.
+	artifact string
+
+	// deadline is the deadline of the artifact, VerifyItemTimeout after its
+	// resolve started. It is zero if the artifact was not resolved or if
+	// VerifyItemTimeout is not set.
+	deadline time.Time
+
+	// err is the error of resolving the artifact, if it failed.
+	err error
+}
+
+// pinArtifacts pins the tagged artifacts to the digests they currently resolve
+// to in parallel, so that a repointed tag never serves a stale result from the
+// verify cache. An artifact is kept as is if it is already pinned, or if it
+// cannot be parsed, matches no executor or is exempt from validation, as its
+// result does not depend on its digest then. An artifact failing to resolve
+// is reported with the error instead of being looked up by tag, which could
+// serve the result of the digest the tag pointed to before.
+//
+// Resolutions are cached in the mutate cache for its TTL, so that cache hits
+// of the verify cache do not wait for the registry. The deadline of each
+// resolved artifact bounds both its resolve and its validation, so that a slow
+// registry delays the other artifacts by at most one item deadline.
+func (s *server) pinArtifacts(ctx context.Context, executor *e.ScopedExecutor, artifacts []string) []pinnedArtifact {
+	pinned := make([]pinnedArtifact, len(artifacts))
+	for idx, artifact := range artifacts {
+		pinned[idx].artifact = artifact
+	}
+	if executor == nil || s.VerifyCache.Disabled {
+		return pinned
+	}
+
+	maxConcurrency := s.VerifyConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = e.DefaultMaxConcurrency
+	}
+	var group errgroup.Group
+	group.SetLimit(maxConcurrency)
+	for idx, artifact := range artifacts {
+		ref, err := registry.ParseReference(artifact)
+		if err != nil {
+			continue
+		}
+		if _, err = ref.Digest(); err == nil {
+			continue
+		}
+		// Artifacts not matching any executor fail their validation
+		// regardless of their digest.
+		if explanation, err := executor.Explain(artifact); err != nil || explanation.Exempt {
+			continue
+		}
+		group.Go(func() error {
+			ctx := ctx
+			if s.VerifyItemTimeout > 0 {
+				pinned[idx].deadline = time.Now().Add(s.VerifyItemTimeout)
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, pinned[idx].deadline)
+				defer cancel()
+			}
+			key := generationKey(executor, resolveKey(artifact), artifact)
+			if s.mutateCache != nil {
+				resolvedRef, err := s.mutateCache.Get(ctx, key)
+				hit := err == nil && resolvedRef != ""
+				metrics.ReportCacheLookup(ctx, metrics.CacheMutate, hit)
+				if hit {
+					pinned[idx].artifact = resolvedRef
+					return nil
+				}
+			}
+			desc, err := executor.Resolve(ctx, artifact)
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					pinned[idx].err = fmt.Errorf("%s: failed to resolve artifact %s in time: %w", errorCodeTimeout, artifact, err)
+				} else {
+					pinned[idx].err = fmt.Errorf("failed to resolve artifact %s: %w", artifact, err)
+				}
+				return nil
+			}
+			ref.Reference = desc.Digest.String()
+			pinned[idx].artifact = ref.String()
+			if s.mutateCache != nil {
+				if err = s.mutateCache.Set(ctx, key, pinned[idx].artifact, s.MutateCache.TTL); err != nil {
+					logger.GetLogger(ctx, logOpt).Warnf("failed to set mutate cache for the resolution of image %s: %v", artifact, err)
+				}
 			}
-			return renderedResult, nil
+			return nil
 		})
+	}
+	_ = group.Wait()
+	return pinned
+}
+
+// validateWithDeadline validates the artifact with the default options and
+// waits for the outcome until the deadline of the artifact, capped by the
+// deadline of the request. The deadline is the one derived when the artifact
+// was pinned, if not zero, or VerifyItemTimeout after its validation started
+// otherwise. The validation is detached from the request, so that an artifact
+// timing out keeps being validated in the background for up to VerifyTimeout
+// and its outcome is cached for the next request, e.g. the retry of the
+// admission.
+func (s *server) validateWithDeadline(ctx context.Context, executor *e.ScopedExecutor, artifact string, deadline time.Time) (*validationOutcome, error) {
+	type validation struct {
+		outcome *validationOutcome
+		err     error
+	}
+	backgroundCtx, cancelBackground := s.backgroundContext(ctx)
+	done := make(chan validation, 1)
+	s.backgroundValidations.Add(1)
+	go func() {
+		defer s.backgroundValidations.Done()
+		defer cancelBackground()
+		outcome, err := s.validateArtifact(backgroundCtx, executor, artifact, e.ValidateOptions{})
+		done <- validation{outcome: outcome, err: err}
+	}()
+
+	if deadline.IsZero() && s.VerifyItemTimeout > 0 {
+		deadline = time.Now().Add(s.VerifyItemTimeout)
+	}
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	select {
+	case v := <-done:
+		return v.outcome, v.err
+	case <-ctx.Done():
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ctx.Err()
+		}
+		logger.GetLogger(ctx, logOpt).Infof("validation of artifact %s did not complete in time, continuing in the background", artifact)
+		return nil, errValidationTimeout
+	}
+}
+
+// validationOutcome is the outcome of validating an artifact shared by the
+// Gatekeeper provider and the artifact validation API.
+type validationOutcome struct {
+	result     *e.ValidationResult
+	validation *artifactValidation
+}
+
+// validateArtifact validates the artifact with the options and caches the
+// outcome. Concurrent validations of the same artifact with the same options
+// are deduplicated. Validations with the default options fill the caches of
+// both the Gatekeeper provider and the artifact validation API.
+func (s *server) validateArtifact(ctx context.Context, executor *e.ScopedExecutor, artifact string, opts e.ValidateOptions) (*validationOutcome, error) {
+	// Block multiple goroutines from validating the same artifact.
+	key := generationKey(executor, validateKey(artifact, opts), artifact)
+	val, err, shared := s.sfGroup.Do(key, func() (any, error) {
+		start := time.Now()
+		result, err := executor.ValidateArtifactWithOptions(ctx, artifact, opts)
 		if err != nil {
-			results[idx].Error = err.Error()
+			return nil, err
+		}
+		outcome := &validationOutcome{
+			result:     result,
+			validation: convertArtifactValidation(artifact, result, time.Since(start)),
+		}
+		if isDefaultValidateOptions(opts) {
+			if err = s.verifyCache.Set(ctx, key, convertResult(result), s.VerifyCache.resultTTL(result.Succeeded)); err != nil {
+				logger.GetLogger(ctx, logOpt).Warnf("failed to set verify cache for image %s: %v", artifact, err)
+			}
 		}
-		results[idx].Value = val
+		if err = s.validationCache.Set(ctx, key, outcome.validation, s.ValidationCache.resultTTL(result.Succeeded)); err != nil {
+			logger.GetLogger(ctx, logOpt).Warnf("failed to set validation cache for image %s: %v", artifact, err)
+		}
+		return outcome, nil
+	})
+	metrics.ReportSingleflight(ctx, metrics.OperationValidate, shared)
+	if err != nil {
+		return nil, err
 	}
+	return val.(*validationOutcome), nil
+}
 
-	return sendResponse(results, w, http.StatusOK, false)
+// reportWouldBeDenial logs and counts the artifact if it is allowed only
+// because its executor is in warn mode.
+func (s *server) reportWouldBeDenial(ctx context.Context, artifact string, rendered *result) {
+	if rendered.EnforcementMode != string(e.EnforcementModeWarn) || rendered.VerificationSucceeded == nil || *rendered.VerificationSucceeded {
+		return
+	}
+	s.warnDenials.Add(1)
+	metrics.ReportWarnDenial(ctx)
+	logger.GetLogger(ctx, logOpt).Warnf("artifact %s failed verification and would be denied if the executor was not in warn mode", artifact)
 }
 
 // mutate handles the mutation request from Gatekeeper.
 func (s *server) mutate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	start := time.Now()
 	defer r.Body.Close()
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -97,15 +416,26 @@ func (s *server) mutate(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	for idx, key := range providerRequest.Request.Keys {
 		results[idx] = s.resolveReference(ctx, key)
 	}
+	metrics.ReportRequest(ctx, metrics.HandlerMutate, len(results), time.Since(start))
 
 	return sendResponse(results, w, http.StatusOK, true)
 }
 
+// resolveReference resolves the reference to a reference pinned to the digest
+// of the artifact. Each resolution is recorded as a root span.
 func (s *server) resolveReference(ctx context.Context, reference string) externaldata.Item {
 	item := externaldata.Item{
 		Key:   reference,
 		Value: reference,
 	}
+	ctx, span := tracing.StartSpan(ctx, "ratify.mutate", tracing.AttributeArtifact.String(reference))
+	defer func() {
+		var err error
+		if item.Error != "" {
+			err = errors.New(item.Error)
+		}
+		tracing.EndSpan(span, err)
+	}()
 
 	ref, err := registry.ParseReference(reference)
 	if err != nil {
@@ -118,17 +448,24 @@ func (s *server) resolveReference(ctx context.Context, reference string) externa
 	}
 
 	// Fetch the cache value first.
-	key := mutateKey(reference)
+	executor := s.getExecutor()
+	key := generationKey(executor, mutateKey(reference), reference)
 	result, err := s.mutateCache.Get(ctx, key)
-	if err == nil && result != "" {
+	hit := err == nil && result != ""
+	metrics.ReportCacheLookup(ctx, metrics.CacheMutate, hit)
+	span.SetAttributes(tracing.AttributeCached.Bool(hit))
+	if hit {
 		item.Value = result
 		return item
 	}
+	if msg, ok := s.cachedError(ctx, key); ok {
+		item.Error = msg
+		return item
+	}
 
 	// Cache is missed, block multiple goroutines from resolving the same
 	// reference.
-	val, err, _ := s.sfGroup.Do(key, func() (any, error) {
-		executor := s.getExecutor()
+	val, err, shared := s.sfGroup.Do(key, func() (any, error) {
 		if executor == nil {
 			return "", errors.New("no valid executor configured")
 		}
@@ -138,20 +475,110 @@ func (s *server) resolveReference(ctx context.Context, reference string) externa
 		}
 		ref.Reference = desc.Digest.String()
 		resolvedRef := ref.String()
+		if s.VerifyThenPin {
+			if err = s.verifyPinnedReference(ctx, executor, resolvedRef); err != nil {
+				return "", err
+			}
+		}
 
-		if err = s.mutateCache.Set(ctx, key, resolvedRef, 0); err != nil {
-			logrus.Warnf("failed to set mutate cache for image %s: %v", reference, err)
+		if err = s.mutateCache.Set(ctx, key, resolvedRef, s.MutateCache.TTL); err != nil {
+			logger.GetLogger(ctx, logOpt).Warnf("failed to set mutate cache for image %s: %v", reference, err)
 		}
 		return resolvedRef, nil
 	})
+	metrics.ReportSingleflight(ctx, metrics.OperationResolve, shared)
 	if err != nil {
 		item.Error = err.Error()
+		s.cacheError(ctx, key, err, s.MutateCache)
 	} else {
 		item.Value = val
 	}
 	return item
 }
 
+// verifyPinnedReference validates the artifact referenced by digest before a
+// reference is pinned to it. The validation result is shared with the verify
+// cache, so that the validation of the pinned reference at admission time is a
+// cache hit.
+func (s *server) verifyPinnedReference(ctx context.Context, executor *e.ScopedExecutor, pinnedRef string) error {
+	rendered, err := s.verifyCache.Get(ctx, generationKey(executor, verifyKey(pinnedRef), pinnedRef))
+	hit := err == nil && rendered != nil
+	metrics.ReportCacheLookup(ctx, metrics.CacheVerify, hit)
+	if !hit {
+		outcome, err := s.validateArtifact(ctx, executor, pinnedRef, e.ValidateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to verify %s before pinning: %w", pinnedRef, err)
+		}
+		rendered = convertResult(outcome.result)
+	}
+	if !rendered.Succeeded {
+		return fmt.Errorf("%s failed verification and is not pinned", pinnedRef)
+	}
+	return nil
+}
+
+// explain describes how the artifact in the "reference" query parameter is
+// routed by the executor without validating it.
+func (s *server) explain(w http.ResponseWriter, r *http.Request) error {
+	reference := r.URL.Query().Get("reference")
+	if reference == "" {
+		return sendJSON(w, http.StatusBadRequest, errorResponse{Error: "query parameter \"reference\" is required"})
+	}
+	executor := s.getExecutor()
+	if executor == nil {
+		return sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "no valid executor configured"})
+	}
+	explanation, err := executor.Explain(reference)
+	if err != nil {
+		return sendJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+	}
+	return sendJSON(w, http.StatusOK, explanation)
+}
+
+// validateArtifacts handles the artifact validation API. It validates the
+// subject in the request body and returns the complete validation result.
+func (s *server) validateArtifacts(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+	var request validateRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return sendJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("failed to decode request body: %v", err)})
+	}
+	if request.Subject == "" {
+		return sendJSON(w, http.StatusBadRequest, errorResponse{Error: "field \"subject\" is required"})
+	}
+
+	executor := s.getExecutor()
+	if executor == nil {
+		return sendJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "no valid executor configured"})
+	}
+
+	// Fetch the cache value first.
+	cached, err := s.validationCache.Get(ctx, generationKey(executor, validateKey(request.Subject, request.Options), request.Subject))
+	hit := err == nil && cached != nil
+	metrics.ReportCacheLookup(ctx, metrics.CacheValidation, hit)
+	if hit {
+		validation := *cached
+		validation.Cached = true
+		return sendJSON(w, http.StatusOK, &validation)
+	}
+	outcome, err := s.validateArtifact(ctx, executor, request.Subject, request.Options)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return sendJSON(w, http.StatusGatewayTimeout, errorResponse{Error: err.Error()})
+		}
+		return sendJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+	}
+	return sendJSON(w, http.StatusOK, outcome.validation)
+}
+
+func sendJSON(w http.ResponseWriter, respCode int, body any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(respCode)
+	return json.NewEncoder(w).Encode(body)
+}
+
 func sendResponse(results []externaldata.Item, w http.ResponseWriter, respCode int, isMutation bool) error {
 	response := externaldata.ProviderResponse{
 		APIVersion: "externaldata.gatekeeper.sh/v1beta1",
@@ -169,6 +596,15 @@ func sendResponse(results []externaldata.Item, w http.ResponseWriter, respCode i
 	return json.NewEncoder(w).Encode(response)
 }
 
+const (
+	// optionsKeySuffix starts the validation options in the key of a
+	// validation with non-default options.
+	optionsKeySuffix = "_platforms="
+
+	// generationKeySuffix starts the cache generation in a key.
+	generationKeySuffix = "_gen="
+)
+
 func mutateKey(key string) string {
 	return fmt.Sprintf("%s_%s", mutatePath, key)
 }
@@ -176,3 +612,35 @@ func mutateKey(key string) string {
 func verifyKey(key string) string {
 	return fmt.Sprintf("%s_%s", verifyPath, key)
 }
+
+// resolveKey returns the key to cache the digest reference a tagged artifact
+// of a verification request resolves to. It differs from the mutate key, as
+// the mutate cache only holds the references pinned once verified if
+// VerifyThenPin is set.
+func resolveKey(key string) string {
+	return fmt.Sprintf("%s_%s", resolvePath, key)
+}
+
+// validateKey returns the key to cache and deduplicate the validation of the
+// artifact with the options. It equals the verify key for the default
+// options, so that both APIs share the validation.
+func validateKey(artifact string, opts e.ValidateOptions) string {
+	if isDefaultValidateOptions(opts) {
+		return verifyKey(artifact)
+	}
+	return fmt.Sprintf("%s%s%s_referenceTypes=%s_maxDepth=%d", verifyKey(artifact), optionsKeySuffix, strings.Join(opts.Platforms, ","), strings.Join(opts.ReferenceTypes, ","), max(opts.MaxDepth, 0))
+}
+
+// generationKey folds the cache generation of the artifact into the key, so
+// that results cached before the executor unit matched by the artifact was
+// reconfigured or before its scope was invalidated are never served.
+func generationKey(executor *e.ScopedExecutor, key, artifact string) string {
+	if executor == nil {
+		return key
+	}
+	return key + generationKeySuffix + executor.CacheGeneration(artifact)
+}
+
+func isDefaultValidateOptions(opts e.ValidateOptions) bool {
+	return len(opts.Platforms) == 0 && len(opts.ReferenceTypes) == 0 && opts.MaxDepth <= 0
+}
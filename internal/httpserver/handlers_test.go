@@ -23,11 +23,24 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
 	"github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/store"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+	"github.com/notaryproject/ratify/v2/internal/verifier"
 	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -52,6 +65,21 @@ func (c *mockCache) Delete(_ context.Context, key string) error {
 	return nil
 }
 
+func (c *mockCache) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	var deleted int
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *mockCache) Stats(_ context.Context) (cache.Stats, error) {
+	return cache.Stats{Entries: len(c.entries)}, nil
+}
+
 type mockResultCache struct {
 	entries map[string]*result
 }
@@ -73,13 +101,65 @@ func (c *mockResultCache) Delete(_ context.Context, key string) error {
 	return nil
 }
 
+func (c *mockResultCache) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	var deleted int
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *mockResultCache) Stats(_ context.Context) (cache.Stats, error) {
+	return cache.Stats{Entries: len(c.entries)}, nil
+}
+
+type mockValidationCache struct {
+	entries map[string]*artifactValidation
+}
+
+func (c *mockValidationCache) Get(_ context.Context, key string) (*artifactValidation, error) {
+	if val, ok := c.entries[key]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("key not found")
+}
+
+func (c *mockValidationCache) Set(_ context.Context, key string, value *artifactValidation, _ time.Duration) error {
+	c.entries[key] = value
+	return nil
+}
+
+func (c *mockValidationCache) Delete(_ context.Context, key string) error {
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *mockValidationCache) DeletePrefix(_ context.Context, prefix string) (int, error) {
+	var deleted int
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *mockValidationCache) Stats(_ context.Context) (cache.Stats, error) {
+	return cache.Stats{Entries: len(c.entries)}, nil
+}
+
 func TestVerify(t *testing.T) {
 	server := &server{
 		getExecutor: func() *executor.ScopedExecutor {
 			return &executor.ScopedExecutor{}
 		},
-		verifyCache: &mockResultCache{entries: make(map[string]*result)},
-		sfGroup:     new(singleflight.Group),
+		verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
 	}
 
 	tests := []struct {
@@ -146,6 +226,37 @@ func TestVerify(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Multiple keys in request order",
+			requestBody: `{
+				"request": {
+					"keys": ["artifact1", "artifact2", "artifact3"]
+				}
+			}`,
+			getExecutorFunc: func() *executor.ScopedExecutor {
+				return &executor.ScopedExecutor{}
+			},
+			cacheEntries: map[string]*result{
+				"verify_artifact2_gen=0": {
+					Succeeded: true,
+				},
+			},
+			expectedError: false,
+			expectedItems: []externaldata.Item{
+				{
+					Key:   "artifact1",
+					Error: "failed to match executor for artifact \"artifact1\": failed to parse artifact reference \"artifact1\": invalid reference: missing registry or repository",
+				},
+				{
+					Key:   "artifact2",
+					Value: map[string]interface{}{"succeeded": true, "artifactReports": nil},
+				},
+				{
+					Key:   "artifact3",
+					Error: "failed to match executor for artifact \"artifact3\": failed to parse artifact reference \"artifact3\": invalid reference: missing registry or repository",
+				},
+			},
+		},
 		{
 			name:          "Invalid JSON",
 			requestBody:   `{invalid-json}`,
@@ -175,7 +286,7 @@ func TestVerify(t *testing.T) {
 					t.Fatalf("failed to decode response: %v", err)
 				}
 
-				if !reflect.DeepEqual(response.Response.Items[0], test.expectedItems[0]) {
+				if !reflect.DeepEqual(response.Response.Items, test.expectedItems) {
 					t.Errorf("expected items: %v, got: %v", test.expectedItems, response.Response.Items)
 				}
 			}
@@ -183,6 +294,98 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerify_WarnMode(t *testing.T) {
+	verificationFailed := false
+	verificationPassed := true
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return &executor.ScopedExecutor{}
+		},
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			"verify_denied_gen=0": {
+				Succeeded:             true,
+				EnforcementMode:       "warn",
+				VerificationSucceeded: &verificationFailed,
+			},
+			"verify_passed_gen=0": {
+				Succeeded:             true,
+				EnforcementMode:       "warn",
+				VerificationSucceeded: &verificationPassed,
+			},
+			"verify_audited_gen=0": {
+				Succeeded:             true,
+				EnforcementMode:       "audit",
+				VerificationSucceeded: &verificationFailed,
+			},
+		}},
+		sfGroup: new(singleflight.Group),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(`{"request": {"keys": ["denied", "passed", "audited"]}}`))
+	w := httptest.NewRecorder()
+	if err := server.verify(context.Background(), w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response externaldata.ProviderResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := map[string]interface{}{"succeeded": true, "enforcementMode": "warn", "verificationSucceeded": false, "artifactReports": nil}
+	if !reflect.DeepEqual(response.Response.Items[0].Value, expected) {
+		t.Errorf("expected value: %v, got: %v", expected, response.Response.Items[0].Value)
+	}
+	if got := server.warnDenials.Load(); got != 1 {
+		t.Errorf("expected 1 would-be denial in warn mode, got: %d", got)
+	}
+}
+
+func TestVerify_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	if _, err := tracing.Init(context.Background(), tracing.Options{}); err != nil {
+		t.Fatalf("failed to initialize tracing: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return &executor.ScopedExecutor{}
+		},
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			"verify_cached_gen=0": {Succeeded: true},
+		}},
+		sfGroup: new(singleflight.Group),
+	}
+	handler := tracing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := server.verify(r.Context(), w, r); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(`{"request": {"keys": ["cached", "uncached"]}}`))
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %s is not part of the incoming trace", span.Name())
+		}
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	if len(spans["ratify.verify"]) != 2 {
+		t.Fatalf("expected 2 verify spans, got: %d", len(spans["ratify.verify"]))
+	}
+	if len(spans["executor.match"]) != 1 {
+		t.Fatalf("expected 1 executor match span, got: %d", len(spans["executor.match"]))
+	}
+	if spans["executor.match"][0].Parent().SpanID() != spans["ratify.verify"][1].SpanContext().SpanID() {
+		t.Errorf("expected executor match span to be a child of the uncached verify span")
+	}
+	if spans["ratify.verify"][0].Status().Code != codes.Unset || spans["ratify.verify"][1].Status().Code != codes.Error {
+		t.Errorf("expected only the uncached verify span to fail")
+	}
+}
+
 func TestMutate(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -235,7 +438,7 @@ func TestMutate(t *testing.T) {
 				}
 			}`,
 			cacheEntries: map[string]string{
-				"mutate_testrepo/testimage:v1": "testrepo/testimage@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88",
+				"mutate_testrepo/testimage:v1_gen=0": "testrepo/testimage@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88",
 			},
 			expectedError: false,
 			expectedItems: []externaldata.Item{
@@ -298,3 +501,879 @@ func TestMutate(t *testing.T) {
 		})
 	}
 }
+
+func TestExplain(t *testing.T) {
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes: []string{"registry.example.com/team/*"},
+				Verifiers: []verifier.NewOptions{
+					{
+						Name: mockVerifierName,
+						Type: mockVerifierType,
+					},
+				},
+				Stores: []store.NewOptions{
+					{
+						Type: mockStoreType,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create scoped executor: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		getExecutorFunc func() *executor.ScopedExecutor
+		expectedCode    int
+		expectedBody    string
+	}{
+		{
+			name:         "Matched reference",
+			query:        "?reference=registry.example.com/team/app:v1",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"scope":"registry.example.com/team/*","matchKind":"repository","enforcementMode":"enforce","verifiers":["mock-verifier-name"],"store":{"type":"mock-store-type","scope":"registry.example.com/team/*","matchKind":"repository"}}`,
+		},
+		{
+			name:         "Missing reference",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"query parameter \"reference\" is required"}`,
+		},
+		{
+			name:         "Unmatched reference",
+			query:        "?reference=unknown.com/app:v1",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":"no executor configured for the artifact \"unknown.com/app:v1\""}`,
+		},
+		{
+			name:  "No executor",
+			query: "?reference=registry.example.com/team/app:v1",
+			getExecutorFunc: func() *executor.ScopedExecutor {
+				return nil
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"error":"no valid executor configured"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/explain"+test.query, nil)
+			w := httptest.NewRecorder()
+
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return scopedExecutor
+				},
+			}
+			if test.getExecutorFunc != nil {
+				server.getExecutor = test.getExecutorFunc
+			}
+			if err := server.explain(w, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != test.expectedCode {
+				t.Errorf("expected status code: %d, got: %d", test.expectedCode, w.Code)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != test.expectedBody {
+				t.Errorf("expected body: %s, got: %s", test.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestValidateArtifacts(t *testing.T) {
+	exemptExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		requestBody        string
+		executor           *executor.ScopedExecutor
+		cacheEntries       map[string]*artifactValidation
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "invalid JSON",
+			requestBody:        `{invalid-json}`,
+			executor:           exemptExecutor,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown field",
+			requestBody:        `{"subject": "example.com/app:v1", "maxDepth": 3}`,
+			executor:           exemptExecutor,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "missing subject",
+			requestBody:        `{"options": {}}`,
+			executor:           exemptExecutor,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"field \"subject\" is required"}`,
+		},
+		{
+			name:               "no executor",
+			requestBody:        `{"subject": "example.com/app:v1"}`,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"error":"no valid executor configured"}`,
+		},
+		{
+			name:               "validation error",
+			requestBody:        `{"subject": "other.com/app:v1"}`,
+			executor:           exemptExecutor,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       `{"error":"failed to match executor for artifact \"other.com/app:v1\": no executor configured for the artifact \"other.com/app:v1\""}`,
+		},
+		{
+			name:               "exempt artifact",
+			requestBody:        `{"subject": "example.com/app:v1"}`,
+			executor:           exemptExecutor,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"subject":"example.com/app:v1","succeeded":true,"verificationSucceeded":true,"exemption":{"scope":"example.com","reason":"artifact matches scope \"example.com\" of an exempt executor"},"durationMs":0,"cached":false}`,
+		},
+		{
+			name:        "cache hit",
+			requestBody: `{"subject": "example.com/app:v1", "options": {"platforms": ["linux/amd64"]}}`,
+			executor:    &executor.ScopedExecutor{},
+			cacheEntries: map[string]*artifactValidation{
+				"verify_example.com/app:v1_platforms=linux/amd64_referenceTypes=_maxDepth=0_gen=0": {
+					Subject:   "example.com/app:v1",
+					Succeeded: true,
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"subject":"example.com/app:v1","succeeded":true,"verificationSucceeded":false,"durationMs":0,"cached":true}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cacheEntries := test.cacheEntries
+			if cacheEntries == nil {
+				cacheEntries = make(map[string]*artifactValidation)
+			}
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return test.executor
+				},
+				verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+				validationCache: &mockValidationCache{entries: cacheEntries},
+				sfGroup:         new(singleflight.Group),
+			}
+			req := httptest.NewRequest(http.MethodPost, "/ratify/v2/artifacts:validate", strings.NewReader(test.requestBody))
+			w := httptest.NewRecorder()
+			if err := server.validateArtifacts(context.Background(), w, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != test.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", test.expectedStatusCode, w.Code)
+			}
+			if test.expectedBody != "" && strings.TrimSpace(w.Body.String()) != test.expectedBody {
+				t.Errorf("expected body %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidateArtifacts_SharedCache(t *testing.T) {
+	exemptExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	verifyCache := &mockResultCache{entries: make(map[string]*result)}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return exemptExecutor
+		},
+		verifyCache:     verifyCache,
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/ratify/v2/artifacts:validate", strings.NewReader(`{"subject": "example.com/app:v1"}`))
+	if err := server.validateArtifacts(context.Background(), httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := verifyCache.entries[generationKey(exemptExecutor, verifyKey("example.com/app:v1"), "example.com/app:v1")]; !ok {
+		t.Errorf("expected the Gatekeeper verify cache to be filled")
+	}
+
+	// Validations with options are not shared with the Gatekeeper provider.
+	req = httptest.NewRequest(http.MethodPost, "/ratify/v2/artifacts:validate", strings.NewReader(`{"subject": "example.com/other:v1", "options": {"referenceTypes": ["application/vnd.cncf.notary.signature"]}}`))
+	if err := server.validateArtifacts(context.Background(), httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(verifyCache.entries) != 1 {
+		t.Errorf("expected only the validation with default options to fill the verify cache, got: %v", verifyCache.entries)
+	}
+}
+
+// referrerTreeStore serves a subject referred by a signature, itself referred
+// by an SBOM.
+type referrerTreeStore struct {
+	mockStore
+}
+
+const (
+	treeSubjectDigest   = "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	treeSignatureDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	treeSBOMDigest      = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+)
+
+func (m *referrerTreeStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: treeSubjectDigest}, nil
+}
+
+func (m *referrerTreeStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	switch ref {
+	case "tree.example.com/app@" + treeSubjectDigest:
+		return fn([]ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageManifest, Digest: treeSignatureDigest}})
+	case "tree.example.com/app@" + treeSignatureDigest:
+		return fn([]ocispec.Descriptor{{MediaType: ocispec.MediaTypeImageManifest, Digest: treeSBOMDigest}})
+	}
+	return nil
+}
+
+// slowVerifier takes slowVerifyDelay to verify an artifact.
+type slowVerifier struct {
+	mockVerifier
+}
+
+const slowVerifyDelay = 10 * time.Millisecond
+
+func (v *slowVerifier) Verify(_ context.Context, _ *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	time.Sleep(slowVerifyDelay)
+	return &ratify.VerificationResult{Verifier: v}, nil
+}
+
+func TestValidateArtifacts_MaxDepthAndTimings(t *testing.T) {
+	store.Register("mock-referrer-tree-store", func(store.NewOptions) (ratify.Store, error) {
+		return &referrerTreeStore{}, nil
+	})
+	verifier.Register("mock-slow-verifier", func(verifier.NewOptions, []string) (ratify.Verifier, error) {
+		return &slowVerifier{}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"tree.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: "mock-slow-verifier"}},
+				Stores:    []store.NewOptions{{Type: "mock-referrer-tree-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		expectedDepth int
+	}{
+		{
+			name:          "all referrers",
+			requestBody:   `{"subject": "tree.example.com/app:v1"}`,
+			expectedDepth: 2,
+		},
+		{
+			name:          "max depth",
+			requestBody:   `{"subject": "tree.example.com/app:v1", "options": {"maxDepth": 1}}`,
+			expectedDepth: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/ratify/v2/artifacts:validate", strings.NewReader(test.requestBody))
+			w := httptest.NewRecorder()
+			if err := server.validateArtifacts(context.Background(), w, req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var validation artifactValidation
+			if err := json.Unmarshal(w.Body.Bytes(), &validation); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			depth := 0
+			for reports := validation.ArtifactReports; len(reports) > 0; reports = reports[0].ArtifactReports {
+				depth++
+				report := reports[0]
+				if len(report.Results) != 1 {
+					t.Fatalf("expected one verifier result at depth %d, got: %+v", depth, report.Results)
+				}
+				if got := report.Results[0].DurationMilliseconds; got < slowVerifyDelay.Milliseconds() {
+					t.Errorf("expected verifier duration of at least %v at depth %d, got %dms", slowVerifyDelay, depth, got)
+				}
+				if report.DurationMilliseconds < report.Results[0].DurationMilliseconds {
+					t.Errorf("expected node duration to include the verifier duration at depth %d, got %dms", depth, report.DurationMilliseconds)
+				}
+			}
+			if depth != test.expectedDepth {
+				t.Errorf("expected reports of depth %d, got %d", test.expectedDepth, depth)
+			}
+		})
+	}
+}
+
+func TestMutate_VerifyThenPin(t *testing.T) {
+	const (
+		reference = "example.com/app:v1"
+		pinnedRef = "example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	store.Register("mock-digest-store", func(store.NewOptions) (ratify.Store, error) {
+		return &mockStore{resolveMap: map[string]ocispec.Descriptor{
+			reference: {Digest: "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"},
+		}}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-digest-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		verifyEntries map[string]*result
+		expectedItem  externaldata.Item
+	}{
+		{
+			name: "verified digest is pinned",
+			verifyEntries: map[string]*result{
+				generationKey(scopedExecutor, verifyKey(pinnedRef), pinnedRef): {Succeeded: true},
+			},
+			expectedItem: externaldata.Item{Key: reference, Value: pinnedRef},
+		},
+		{
+			// Without a policy enforcer, the validation never succeeds.
+			name:          "unverified digest is not pinned",
+			verifyEntries: map[string]*result{},
+			expectedItem: externaldata.Item{
+				Key:   reference,
+				Value: reference,
+				Error: pinnedRef + " failed verification and is not pinned",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mutateCache := &mockCache{entries: make(map[string]string)}
+			verifyCache := &mockResultCache{entries: test.verifyEntries}
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return scopedExecutor
+				},
+				mutateCache:     mutateCache,
+				verifyCache:     verifyCache,
+				validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+				sfGroup:         new(singleflight.Group),
+				ServerOptions: ServerOptions{
+					VerifyThenPin: true,
+				},
+			}
+
+			item := server.resolveReference(context.Background(), reference)
+			if !reflect.DeepEqual(item, test.expectedItem) {
+				t.Errorf("expected item: %+v, got: %+v", test.expectedItem, item)
+			}
+			if _, ok := verifyCache.entries[generationKey(scopedExecutor, verifyKey(pinnedRef), pinnedRef)]; !ok {
+				t.Errorf("expected the verification result of the pinned reference to be cached")
+			}
+			if _, cached := mutateCache.entries[generationKey(scopedExecutor, mutateKey(reference), reference)]; cached != (test.expectedItem.Error == "") {
+				t.Errorf("expected only pinned references to be cached, got: %v", mutateCache.entries)
+			}
+		})
+	}
+}
+
+func TestVerify_CachedByDigest(t *testing.T) {
+	const (
+		reference = "repointed.example.com/app:v1"
+		pinnedRef = "repointed.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	store.Register("mock-repointed-store", func(store.NewOptions) (ratify.Store, error) {
+		return &mockStore{resolveMap: map[string]ocispec.Descriptor{
+			reference: {Digest: "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"},
+		}}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"repointed.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-repointed-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	// The result cached for the tag before it was repointed is never served.
+	verifyCache := &mockResultCache{entries: map[string]*result{
+		generationKey(scopedExecutor, verifyKey(reference), reference): {Succeeded: true},
+	}}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     verifyCache,
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	items := server.verifyArtifacts(context.Background(), []string{reference})
+	if items[0].Key != reference {
+		t.Errorf("expected item key %s, got: %s", reference, items[0].Key)
+	}
+	// Without a policy enforcer, the validation never succeeds.
+	if rendered, ok := items[0].Value.(*result); !ok || rendered.Succeeded {
+		t.Errorf("expected a failed verification result, got: %+v", items[0])
+	}
+	if _, ok := verifyCache.entries[generationKey(scopedExecutor, verifyKey(pinnedRef), pinnedRef)]; !ok {
+		t.Errorf("expected the verification result to be cached by digest, got: %v", verifyCache.entries)
+	}
+}
+
+func TestVerify_ResolveFailure(t *testing.T) {
+	const reference = "unreachable.example.com/app:v1"
+	store.Register("mock-unreachable-store", func(store.NewOptions) (ratify.Store, error) {
+		return &mockStore{returnResolveErr: true}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"unreachable.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-unreachable-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	// A result was cached for the tag before the registry became unreachable.
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			generationKey(scopedExecutor, verifyKey(reference), reference): {Succeeded: true},
+		}},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	items := server.verifyArtifacts(context.Background(), []string{reference})
+	if items[0].Value != nil || !strings.HasPrefix(items[0].Error, "failed to resolve artifact "+reference) {
+		t.Errorf("expected a resolve error instead of the result cached by tag, got: %+v", items[0])
+	}
+}
+
+// countingResolveStore counts the references it resolves.
+type countingResolveStore struct {
+	mockStore
+	resolves atomic.Int32
+}
+
+func (m *countingResolveStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	m.resolves.Add(1)
+	return ocispec.Descriptor{Digest: "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"}, nil
+}
+
+func TestVerify_CachedResolution(t *testing.T) {
+	const (
+		reference = "resolved.example.com/app:v1"
+		pinnedRef = "resolved.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	countingStore := &countingResolveStore{}
+	store.Register("mock-counting-resolve-store", func(store.NewOptions) (ratify.Store, error) {
+		return countingStore, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"resolved.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-counting-resolve-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	mutateCache := &mockCache{entries: make(map[string]string)}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		mutateCache: mutateCache,
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			generationKey(scopedExecutor, verifyKey(pinnedRef), pinnedRef): {Succeeded: true},
+		}},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	for range 2 {
+		items := server.verifyArtifacts(context.Background(), []string{reference})
+		if rendered, ok := items[0].Value.(*result); !ok || !rendered.Succeeded {
+			t.Fatalf("expected the result cached by digest, got: %+v", items[0])
+		}
+	}
+	if got := countingStore.resolves.Load(); got != 1 {
+		t.Errorf("expected the tag to be resolved once, got %d resolves", got)
+	}
+	if got := mutateCache.entries[generationKey(scopedExecutor, resolveKey(reference), reference)]; got != pinnedRef {
+		t.Errorf("expected the resolution to be cached, got: %v", mutateCache.entries)
+	}
+	if _, ok := mutateCache.entries[generationKey(scopedExecutor, mutateKey(reference), reference)]; ok {
+		t.Errorf("expected the resolution not to be cached as a pinned reference")
+	}
+}
+
+func TestMutate_CachedError(t *testing.T) {
+	const reference = "testrepo/testimage:v1"
+	errorCache := &mockCache{entries: make(map[string]string)}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return &executor.ScopedExecutor{}
+		},
+		mutateCache: &mockCache{entries: make(map[string]string)},
+		errorCache:  errorCache,
+		sfGroup:     new(singleflight.Group),
+		ServerOptions: ServerOptions{
+			MutateCache: CacheOptions{FailureTTL: time.Second},
+		},
+	}
+
+	item := server.resolveReference(context.Background(), reference)
+	if item.Error == "" {
+		t.Fatalf("expected an error, got: %+v", item)
+	}
+	key := generationKey(&executor.ScopedExecutor{}, mutateKey(reference), reference)
+	if errorCache.entries[key] != item.Error {
+		t.Fatalf("expected the error to be cached, got: %v", errorCache.entries)
+	}
+
+	errorCache.entries[key] = "cached error"
+	if item = server.resolveReference(context.Background(), reference); item.Error != "cached error" {
+		t.Errorf("expected the cached error, got: %+v", item)
+	}
+}
+
+// slowStore blocks listing the referrers until it is released.
+type slowStore struct {
+	mockStore
+	release chan struct{}
+}
+
+func (m *slowStore) ListReferrers(ctx context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	select {
+	case <-m.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestVerify_ItemTimeout(t *testing.T) {
+	const (
+		fastArtifact = "fast.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+		slowArtifact = "slow.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	release := make(chan struct{})
+	store.Register("mock-slow-store", func(store.NewOptions) (ratify.Store, error) {
+		return &slowStore{release: release}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"fast.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: mockStoreType}},
+			},
+			{
+				Scopes:    []string{"slow.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-slow-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	verifyCache := &mockResultCache{entries: make(map[string]*result)}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     verifyCache,
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+		ServerOptions: ServerOptions{
+			VerifyTimeout:     time.Minute,
+			VerifyItemTimeout: 50 * time.Millisecond,
+		},
+	}
+
+	items := server.verifyArtifacts(context.Background(), []string{fastArtifact, slowArtifact})
+	if _, ok := items[0].Value.(*result); !ok || items[0].Error != "" {
+		t.Errorf("expected the fast artifact to return its result, got: %+v", items[0])
+	}
+	if !strings.HasPrefix(items[1].Error, errorCodeTimeout+":") {
+		t.Errorf("expected a timeout error for the slow artifact, got: %+v", items[1])
+	}
+
+	// The timed out validation completes in the background and fills the
+	// cache for the next request.
+	close(release)
+	server.backgroundValidations.Wait()
+	if _, ok := verifyCache.entries[generationKey(scopedExecutor, verifyKey(slowArtifact), slowArtifact)]; !ok {
+		t.Fatalf("expected the background validation to be cached, got: %v", verifyCache.entries)
+	}
+	items = server.verifyArtifacts(context.Background(), []string{slowArtifact})
+	if _, ok := items[0].Value.(*result); !ok || items[0].Error != "" {
+		t.Errorf("expected the cached result of the slow artifact, got: %+v", items[0])
+	}
+}
+
+// stalledResolveStore blocks resolving references until the context is done.
+type stalledResolveStore struct {
+	mockStore
+}
+
+func (m *stalledResolveStore) Resolve(ctx context.Context, _ string) (ocispec.Descriptor, error) {
+	<-ctx.Done()
+	return ocispec.Descriptor{}, ctx.Err()
+}
+
+func TestVerify_StalledResolve(t *testing.T) {
+	const (
+		fastArtifact    = "fast-pin.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+		stalledArtifact = "stalled.example.com/app:v1"
+	)
+	store.Register("mock-stalled-resolve-store", func(store.NewOptions) (ratify.Store, error) {
+		return &stalledResolveStore{}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"fast-pin.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: mockStoreType}},
+			},
+			{
+				Scopes:    []string{"stalled.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-stalled-resolve-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+		ServerOptions: ServerOptions{
+			VerifyTimeout:     time.Second,
+			VerifyItemTimeout: 50 * time.Millisecond,
+		},
+	}
+
+	// The stalled resolve of the tagged artifact neither uses up the request
+	// deadline nor fails the other artifacts.
+	ctx, cancel := context.WithTimeout(context.Background(), server.VerifyTimeout)
+	defer cancel()
+	items := server.verifyArtifacts(ctx, []string{fastArtifact, stalledArtifact, fastArtifact})
+	if ctx.Err() != nil {
+		t.Fatalf("expected the request to complete before its deadline")
+	}
+	for _, idx := range []int{0, 2} {
+		if _, ok := items[idx].Value.(*result); !ok || items[idx].Error != "" {
+			t.Errorf("expected the fast artifact to return its result, got: %+v", items[idx])
+		}
+	}
+	if _, ok := items[1].Value.(*result); ok {
+		t.Errorf("expected no result for the stalled artifact, got: %+v", items[1])
+	}
+	server.backgroundValidations.Wait()
+}
+
+// delayedStore takes delay to resolve a reference and to list its referrers.
+type delayedStore struct {
+	mockStore
+	delay time.Duration
+}
+
+func (m *delayedStore) Resolve(ctx context.Context, _ string) (ocispec.Descriptor, error) {
+	select {
+	case <-time.After(m.delay):
+		return ocispec.Descriptor{Digest: "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"}, nil
+	case <-ctx.Done():
+		return ocispec.Descriptor{}, ctx.Err()
+	}
+}
+
+func (m *delayedStore) ListReferrers(ctx context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	select {
+	case <-time.After(m.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestVerify_ResolveSharesItemDeadline(t *testing.T) {
+	const (
+		itemTimeout = 100 * time.Millisecond
+		artifact    = "delayed.example.com/app:v1"
+	)
+	store.Register("mock-delayed-store", func(store.NewOptions) (ratify.Store, error) {
+		return &delayedStore{delay: itemTimeout * 3 / 5}, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"delayed.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: "mock-delayed-store"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+		ServerOptions: ServerOptions{
+			VerifyTimeout:     time.Minute,
+			VerifyItemTimeout: itemTimeout,
+		},
+	}
+
+	// The resolve and the validation each complete within the item timeout,
+	// but not together.
+	start := time.Now()
+	items := server.verifyArtifacts(context.Background(), []string{artifact})
+	if !strings.HasPrefix(items[0].Error, errorCodeTimeout+":") {
+		t.Errorf("expected a timeout error for the artifact, got: %+v", items[0])
+	}
+	if elapsed := time.Since(start); elapsed >= itemTimeout*3/2 {
+		t.Errorf("expected the artifact to time out within its item deadline, took %v", elapsed)
+	}
+	server.backgroundValidations.Wait()
+}
+
+func TestVerify_StaleWhileRevalidate(t *testing.T) {
+	const (
+		passedArtifact = "stale.example.com/app@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+		failedArtifact = "stale.example.com/app@sha256:598138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	scopedExecutor, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:    []string{"stale.example.com"},
+				Verifiers: []verifier.NewOptions{{Name: mockVerifierName, Type: mockVerifierType}},
+				Stores:    []store.NewOptions{{Type: mockStoreType}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	verifyCache, err := newStaleCache[*result](metrics.CacheVerify, CacheOptions{StaleWhileRevalidate: time.Minute}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	validationCache, err := newCache[*artifactValidation](metrics.CacheValidation, CacheOptions{}, redis.Options{})
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		verifyCache:     verifyCache,
+		validationCache: validationCache,
+		sfGroup:         new(singleflight.Group),
+	}
+	ctx := context.Background()
+	passedKey := generationKey(scopedExecutor, verifyKey(passedArtifact), passedArtifact)
+	failedKey := generationKey(scopedExecutor, verifyKey(failedArtifact), failedArtifact)
+	if err := verifyCache.Set(ctx, passedKey, &result{Succeeded: true}, time.Millisecond); err != nil {
+		t.Fatalf("failed to set cache: %v", err)
+	}
+	if err := verifyCache.Set(ctx, failedKey, &result{Reason: "stale"}, time.Millisecond); err != nil {
+		t.Fatalf("failed to set cache: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	items := server.verifyArtifacts(ctx, []string{passedArtifact, failedArtifact})
+	// The stale successful result is served while revalidated.
+	if rendered, ok := items[0].Value.(*result); !ok || !rendered.Succeeded {
+		t.Errorf("expected the stale successful result, got: %+v", items[0])
+	}
+	// Stale failed results are not served.
+	if rendered, ok := items[1].Value.(*result); !ok || rendered.Reason == "stale" {
+		t.Errorf("expected the failed artifact to be validated again, got: %+v", items[1])
+	}
+
+	// Without a policy enforcer, the revalidation fails and replaces the
+	// stale result.
+	server.backgroundValidations.Wait()
+	if rendered, err := verifyCache.Get(ctx, passedKey); err != nil || rendered.Succeeded {
+		t.Errorf("expected the revalidated result to be cached, got: %+v, %v", rendered, err)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	e "github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
+)
+
+// healthz handles the liveness probe. The server is alive as long as it
+// serves requests.
+func (s *server) healthz(w http.ResponseWriter, _ *http.Request) error {
+	return sendJSON(w, http.StatusOK, readiness{Ready: true})
+}
+
+// readyz handles the readiness probe. The server is ready once an executor is
+// configured, the TLS configuration is loaded and no key provider failed to
+// initialize.
+func (s *server) readyz(w http.ResponseWriter, _ *http.Request) error {
+	reasons := s.notReadyReasons(keyProviderErrors(s.configErrors()))
+	if len(reasons) > 0 {
+		return sendJSON(w, http.StatusServiceUnavailable, readiness{Reasons: reasons})
+	}
+	return sendJSON(w, http.StatusOK, readiness{Ready: true})
+}
+
+// status handles the status request. It reports the readiness of the server,
+// the scopes of the loaded executors, the errors of loading the executor
+// configuration and the key providers that failed to initialize.
+func (s *server) status(w http.ResponseWriter, _ *http.Request) error {
+	errs := s.configErrors()
+	status := serverStatus{
+		Scopes: []e.ScopeInfo{},
+	}
+	status.KeyProviderErrors = keyProviderErrors(errs)
+	status.NotReadyReasons = s.notReadyReasons(status.KeyProviderErrors)
+	status.Ready = len(status.NotReadyReasons) == 0
+	if executor := s.getExecutor(); executor != nil {
+		status.Scopes = executor.Scopes()
+	}
+	if s.getShadowExecutor != nil {
+		if shadow := s.getShadowExecutor(); shadow != nil {
+			status.ShadowScopes = shadow.Scopes()
+		}
+	}
+	for source, err := range errs {
+		if status.ConfigErrors == nil {
+			status.ConfigErrors = make(map[string]string, len(errs))
+		}
+		status.ConfigErrors[source] = err.Error()
+	}
+	return sendJSON(w, http.StatusOK, status)
+}
+
+// notReadyReasons returns the reasons why the server is not ready to serve
+// requests given the errors of the key providers that failed to initialize,
+// keyed by their configuration source. It returns nil if the server is ready.
+func (s *server) notReadyReasons(keyProviderErrors map[string]string) []string {
+	var reasons []string
+	if s.getExecutor() == nil {
+		reasons = append(reasons, "no valid executor configured")
+	}
+	if !s.tlsReady.Load() {
+		reasons = append(reasons, "TLS configuration is not loaded")
+	}
+
+	sources := make([]string, 0, len(keyProviderErrors))
+	for source := range keyProviderErrors {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", source, keyProviderErrors[source]))
+	}
+	return reasons
+}
+
+// keyProviderErrors returns the errors of the key providers that failed to
+// initialize keyed by the configuration source they are configured in. It
+// returns nil if no key provider failed.
+func keyProviderErrors(configErrors map[string]error) map[string]string {
+	var errs map[string]string
+	for source, err := range configErrors {
+		var initErr *keyprovider.InitError
+		if errors.As(err, &initErr) {
+			if errs == nil {
+				errs = make(map[string]string)
+			}
+			errs[source] = initErr.Error()
+		}
+	}
+	return errs
+}
+
+// configErrors returns the errors of loading the executor configuration keyed
+// by the configuration source, i.e. the configuration file or the Executor
+// resource.
+func (s *server) configErrors() map[string]error {
+	if s.getConfigErrors == nil {
+		return nil
+	}
+	return s.getConfigErrors()
+}
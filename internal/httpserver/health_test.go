@@ -0,0 +1,205 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
+)
+
+func TestReadyz(t *testing.T) {
+	exempt, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	keyProviderErr := fmt.Errorf("failed to create executor: %w", &keyprovider.InitError{
+		Name: "azurekeyvault",
+		Err:  errors.New("vault unreachable"),
+	})
+
+	tests := []struct {
+		name          string
+		executor      *executor.ScopedExecutor
+		tlsReady      bool
+		configErrors  map[string]error
+		expectedCode  int
+		expectedReady readiness
+	}{
+		{
+			name:          "ready",
+			executor:      exempt,
+			tlsReady:      true,
+			expectedCode:  http.StatusOK,
+			expectedReady: readiness{Ready: true},
+		},
+		{
+			name:     "not ready",
+			executor: nil,
+			tlsReady: false,
+			configErrors: map[string]error{
+				"config.json": keyProviderErr,
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedReady: readiness{
+				Reasons: []string{
+					"no valid executor configured",
+					"TLS configuration is not loaded",
+					"config.json: failed to initialize key provider azurekeyvault: vault unreachable",
+				},
+			},
+		},
+		{
+			name:     "key provider of another executor failed",
+			executor: exempt,
+			tlsReady: true,
+			configErrors: map[string]error{
+				"default/broken": keyProviderErr,
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedReady: readiness{
+				Reasons: []string{
+					"default/broken: failed to initialize key provider azurekeyvault: vault unreachable",
+				},
+			},
+		},
+		{
+			name:     "config error not caused by key provider",
+			executor: exempt,
+			tlsReady: true,
+			configErrors: map[string]error{
+				"config.json": errors.New("failed to unmarshal configuration"),
+			},
+			expectedCode:  http.StatusOK,
+			expectedReady: readiness{Ready: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return test.executor
+				},
+				getConfigErrors: func() map[string]error {
+					return test.configErrors
+				},
+			}
+			server.tlsReady.Store(test.tlsReady)
+
+			w := httptest.NewRecorder()
+			if err := server.readyz(w, httptest.NewRequest(http.MethodGet, readyzPath, nil)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != test.expectedCode {
+				t.Errorf("expected status code %d, got %d", test.expectedCode, w.Code)
+			}
+			var got readiness
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.expectedReady) {
+				t.Errorf("expected readiness %+v, got %+v", test.expectedReady, got)
+			}
+
+			// The server is alive regardless of its readiness.
+			w = httptest.NewRecorder()
+			if err := server.healthz(w, httptest.NewRequest(http.MethodGet, healthzPath, nil)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("expected liveness status code %d, got %d", http.StatusOK, w.Code)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	active, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return active
+		},
+		getShadowExecutor: func() *executor.ScopedExecutor {
+			return nil
+		},
+		getConfigErrors: func() map[string]error {
+			return map[string]error{
+				"broken": errors.New("failed to create executor"),
+				"default/vault": fmt.Errorf("failed to create executor: %w", &keyprovider.InitError{
+					Name: "azurekeyvault",
+					Err:  errors.New("vault unreachable"),
+				}),
+			}
+		},
+	}
+	server.tlsReady.Store(true)
+
+	w := httptest.NewRecorder()
+	if err := server.status(w, httptest.NewRequest(http.MethodGet, statusPath, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var got serverStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := serverStatus{
+		NotReadyReasons: []string{
+			"default/vault: failed to initialize key provider azurekeyvault: vault unreachable",
+		},
+		Scopes: []executor.ScopeInfo{
+			{Scope: "example.com", Exempt: true},
+		},
+		ConfigErrors: map[string]string{
+			"broken":        "failed to create executor",
+			"default/vault": "failed to create executor: failed to initialize key provider azurekeyvault: vault unreachable",
+		},
+		KeyProviderErrors: map[string]string{
+			"default/vault": "failed to initialize key provider azurekeyvault: vault unreachable",
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected status %+v, got %+v", expected, got)
+	}
+}
@@ -17,11 +17,60 @@ package httpserver
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/store/registrystore"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
+// errorResponse is the response body of a failed request to a non-Gatekeeper
+// endpoint.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// readiness is the response body of the liveness and readiness probes.
+type readiness struct {
+	Ready   bool     `json:"ready"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// serverStatus is the response body of the status endpoint.
+type serverStatus struct {
+	Ready           bool     `json:"ready"`
+	NotReadyReasons []string `json:"notReadyReasons,omitempty"`
+
+	// Scopes are the scopes of the active executor.
+	Scopes []executor.ScopeInfo `json:"scopes"`
+
+	// ShadowScopes are the scopes of the shadow executor, if any.
+	ShadowScopes []executor.ScopeInfo `json:"shadowScopes,omitempty"`
+
+	// ConfigErrors holds the last error of loading each configuration source,
+	// i.e. the configuration file or the Executor resource, that failed.
+	ConfigErrors map[string]string `json:"configErrors,omitempty"`
+
+	// KeyProviderErrors holds the error of each configuration source whose
+	// key provider failed to initialize, which makes the server not ready.
+	KeyProviderErrors map[string]string `json:"keyProviderErrors,omitempty"`
+}
+
+// cacheStatistics is the response body of the cache statistics request.
+type cacheStatistics struct {
+	// Caches holds the statistics of each cache by name.
+	Caches map[string]cache.Stats `json:"caches"`
+}
+
+// cachePurge is the response body of the cache purge request.
+type cachePurge struct {
+	// Deleted holds the number of entries removed from each cache by name.
+	Deleted map[string]int `json:"deleted"`
+}
+
 // verificationResult is a rendered view of [ratify.VerificationResult].
 type verificationResult struct {
 	VerifierName string `json:"verifierName"`
@@ -30,21 +79,38 @@ type verificationResult struct {
 	ErrorReason  string `json:"errorReason,omitempty"`
 }
 
-// validationReport is a rendered view of [ratify.ValidationReport].
+// validationReport is a rendered view of [ratify.ValidationReport]. Endpoint
+// is the registry endpoint that served the artifact when served by a mirror.
 type validationReport struct {
 	Subject         string                `json:"subject"`
 	Artifact        string                `json:"artifact"`
+	Endpoint        string                `json:"endpoint,omitempty"`
 	Results         []*verificationResult `json:"results,omitempty"`
 	ArtifactReports []*validationReport   `json:"artifactReports,omitempty"`
 }
 
-// result is a rendered view of [ratify.ValidationResult].
-type result struct {
+// platformReport is a rendered view of [executor.PlatformResult].
+type platformReport struct {
+	Platform        string              `json:"platform"`
+	Subject         string              `json:"subject"`
 	Succeeded       bool                `json:"succeeded"`
 	ArtifactReports []*validationReport `json:"artifactReports"`
 }
 
-func convertResult(src *ratify.ValidationResult) *result {
+// result is a rendered view of [executor.ValidationResult]. In warn and audit
+// enforcement modes, Succeeded reports whether the artifact is allowed while
+// VerificationSucceeded reports the actual validation outcome.
+type result struct {
+	Succeeded             bool                `json:"succeeded"`
+	EnforcementMode       string              `json:"enforcementMode,omitempty"`
+	VerificationSucceeded *bool               `json:"verificationSucceeded,omitempty"`
+	Skipped               bool                `json:"skipped,omitempty"`
+	Reason                string              `json:"reason,omitempty"`
+	ArtifactReports       []*validationReport `json:"artifactReports"`
+	PlatformReports       []*platformReport   `json:"platformReports,omitempty"`
+}
+
+func convertResult(src *executor.ValidationResult) *result {
 	if src == nil {
 		return nil
 	}
@@ -52,10 +118,37 @@ func convertResult(src *ratify.ValidationResult) *result {
 		Succeeded:       src.Succeeded,
 		ArtifactReports: convertValidationReports(src.ArtifactReports),
 	}
+	if src.EnforcementMode == executor.EnforcementModeWarn || src.EnforcementMode == executor.EnforcementModeAudit {
+		result.EnforcementMode = string(src.EnforcementMode)
+		verificationSucceeded := src.VerificationSucceeded
+		result.VerificationSucceeded = &verificationSucceeded
+	}
+	if src.Exemption != nil {
+		result.Skipped = true
+		result.Reason = src.Exemption.Reason
+	}
+	if len(src.PlatformResults) > 0 {
+		result.PlatformReports = make([]*platformReport, len(src.PlatformResults))
+		for idx, platformResult := range src.PlatformResults {
+			result.PlatformReports[idx] = convertPlatformResult(platformResult)
+		}
+	}
 
 	return result
 }
 
+func convertPlatformResult(src *executor.PlatformResult) *platformReport {
+	if src == nil {
+		return nil
+	}
+	return &platformReport{
+		Platform:        src.Platform,
+		Subject:         src.Subject,
+		Succeeded:       src.Succeeded,
+		ArtifactReports: convertValidationReports(src.ArtifactReports),
+	}
+}
+
 func convertValidationReports(src []*ratify.ValidationReport) []*validationReport {
 	if src == nil {
 		return nil
@@ -74,6 +167,7 @@ func convertValidationReport(src *ratify.ValidationReport) *validationReport {
 	report := &validationReport{
 		Subject:         src.Subject,
 		Artifact:        src.Artifact.Digest.String(),
+		Endpoint:        src.Artifact.Annotations[registrystore.AnnotationEndpoint],
 		ArtifactReports: convertValidationReports(src.ArtifactReports),
 	}
 
@@ -110,3 +204,150 @@ func convertVerificationResult(src *ratify.VerificationResult) *verificationResu
 	}
 	return result
 }
+
+// validateRequest is the request body of the artifact validation API.
+type validateRequest struct {
+	// Subject is the reference of the artifact to validate. Required.
+	Subject string `json:"subject"`
+
+	// Options overrides the configuration of the matched executor for this
+	// validation only. Optional.
+	Options executor.ValidateOptions `json:"options"`
+}
+
+// artifactValidation is the response body of the artifact validation API. It
+// is a complete view of [executor.ValidationResult].
+type artifactValidation struct {
+	Subject               string                `json:"subject"`
+	Succeeded             bool                  `json:"succeeded"`
+	VerificationSucceeded bool                  `json:"verificationSucceeded"`
+	EnforcementMode       string                `json:"enforcementMode,omitempty"`
+	Exemption             *exemption            `json:"exemption,omitempty"`
+	ArtifactReports       []*artifactReport     `json:"artifactReports,omitempty"`
+	PlatformResults       []*platformValidation `json:"platformResults,omitempty"`
+	// DurationMilliseconds is the time taken to validate the artifact. A
+	// cached result reports the duration of the original validation.
+	DurationMilliseconds int64 `json:"durationMs"`
+	Cached               bool  `json:"cached"`
+}
+
+// exemption is a complete view of [executor.Exemption].
+type exemption struct {
+	Scope  string `json:"scope"`
+	Reason string `json:"reason"`
+}
+
+// artifactReport is a complete view of [ratify.ValidationReport].
+type artifactReport struct {
+	Subject         string             `json:"subject"`
+	Artifact        ocispec.Descriptor `json:"artifact"`
+	Results         []*verifierResult  `json:"results,omitempty"`
+	ArtifactReports []*artifactReport  `json:"artifactReports,omitempty"`
+	// DurationMilliseconds is the time taken to verify the artifact against
+	// its subject and to list its referrers, excluding the time taken by its
+	// artifact reports.
+	DurationMilliseconds int64 `json:"durationMs"`
+}
+
+// verifierResult is a complete view of [ratify.VerificationResult].
+type verifierResult struct {
+	VerifierName string          `json:"verifierName"`
+	VerifierType string          `json:"verifierType"`
+	Succeeded    bool            `json:"succeeded"`
+	Description  string          `json:"description,omitempty"`
+	Detail       json.RawMessage `json:"detail,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	// DurationMilliseconds is the time taken by the verifier.
+	DurationMilliseconds int64 `json:"durationMs"`
+}
+
+// platformValidation is a complete view of [executor.PlatformResult].
+type platformValidation struct {
+	Platform        string            `json:"platform"`
+	Subject         string            `json:"subject"`
+	Succeeded       bool              `json:"succeeded"`
+	ArtifactReports []*artifactReport `json:"artifactReports,omitempty"`
+}
+
+func convertArtifactValidation(subject string, src *executor.ValidationResult, duration time.Duration) *artifactValidation {
+	if src == nil {
+		return nil
+	}
+	validation := &artifactValidation{
+		Subject:               subject,
+		Succeeded:             src.Succeeded,
+		VerificationSucceeded: src.VerificationSucceeded,
+		EnforcementMode:       string(src.EnforcementMode),
+		ArtifactReports:       convertArtifactReports(src.ArtifactReports, src.Timings),
+		DurationMilliseconds:  duration.Milliseconds(),
+	}
+	if src.Exemption != nil {
+		validation.VerificationSucceeded = true
+		validation.Exemption = &exemption{
+			Scope:  src.Exemption.Scope,
+			Reason: src.Exemption.Reason,
+		}
+	}
+	if len(src.PlatformResults) > 0 {
+		validation.PlatformResults = make([]*platformValidation, len(src.PlatformResults))
+		for idx, platformResult := range src.PlatformResults {
+			validation.PlatformResults[idx] = &platformValidation{
+				Platform:        platformResult.Platform,
+				Subject:         platformResult.Subject,
+				Succeeded:       platformResult.Succeeded,
+				ArtifactReports: convertArtifactReports(platformResult.ArtifactReports, src.Timings),
+			}
+		}
+	}
+	return validation
+}
+
+func convertArtifactReports(src []*ratify.ValidationReport, timings *executor.Timings) []*artifactReport {
+	if src == nil {
+		return nil
+	}
+	reports := make([]*artifactReport, 0, len(src))
+	for _, report := range src {
+		if report == nil {
+			continue
+		}
+		converted := &artifactReport{
+			Subject:              report.Subject,
+			Artifact:             report.Artifact,
+			ArtifactReports:      convertArtifactReports(report.ArtifactReports, timings),
+			DurationMilliseconds: timings.Node(report).Milliseconds(),
+		}
+		for _, result := range report.Results {
+			if result == nil {
+				continue
+			}
+			converted.Results = append(converted.Results, convertVerifierResult(result, report, timings))
+		}
+		reports = append(reports, converted)
+	}
+	return reports
+}
+
+func convertVerifierResult(src *ratify.VerificationResult, report *ratify.ValidationReport, timings *executor.Timings) *verifierResult {
+	result := &verifierResult{
+		Succeeded:   src.Err == nil,
+		Description: src.Description,
+	}
+	if src.Verifier != nil {
+		result.VerifierName = src.Verifier.Name()
+		result.VerifierType = src.Verifier.Type()
+		result.DurationMilliseconds = timings.Verifier(report, result.VerifierName).Milliseconds()
+	}
+	if src.Err != nil {
+		result.Error = src.Err.Error()
+	}
+	if src.Detail != nil {
+		detail, err := json.Marshal(src.Detail)
+		if err != nil {
+			logrus.Errorf("failed to marshal detail: %v", err)
+		} else {
+			result.Detail = detail
+		}
+	}
+	return result
+}
@@ -20,9 +20,13 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/store/registrystore"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -52,7 +56,7 @@ func (m *mockVerifier) Verify(_ context.Context, _ *ratify.VerifyOptions) (*rati
 func TestConvertResult(t *testing.T) {
 	tests := []struct {
 		name     string
-		src      *ratify.ValidationResult
+		src      *executor.ValidationResult
 		expected *result
 	}{
 		{
@@ -60,9 +64,81 @@ func TestConvertResult(t *testing.T) {
 			src:      nil,
 			expected: nil,
 		},
+		{
+			name: "exempt artifact",
+			src: &executor.ValidationResult{
+				Succeeded: true,
+				Exemption: &executor.Exemption{
+					Scope:  "registry.example.com/vendor/*",
+					Reason: "artifact matches excluded scope",
+				},
+			},
+			expected: &result{
+				Succeeded: true,
+				Skipped:   true,
+				Reason:    "artifact matches excluded scope",
+			},
+		},
+		{
+			name: "platform results",
+			src: &executor.ValidationResult{
+				PlatformResults: []*executor.PlatformResult{
+					nil,
+					{
+						Platform:  "linux/amd64",
+						Subject:   subject1,
+						Succeeded: true,
+						ArtifactReports: []*ratify.ValidationReport{
+							{
+								Subject: subject1,
+							},
+						},
+					},
+				},
+			},
+			expected: &result{
+				PlatformReports: []*platformReport{
+					nil,
+					{
+						Platform:  "linux/amd64",
+						Subject:   subject1,
+						Succeeded: true,
+						ArtifactReports: []*validationReport{
+							{
+								Subject: subject1,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "warn mode",
+			src: &executor.ValidationResult{
+				Succeeded:             true,
+				VerificationSucceeded: false,
+				EnforcementMode:       executor.EnforcementModeWarn,
+			},
+			expected: &result{
+				Succeeded:             true,
+				EnforcementMode:       "warn",
+				VerificationSucceeded: new(bool),
+			},
+		},
+		{
+			name: "enforce mode",
+			src: &executor.ValidationResult{
+				Succeeded:             false,
+				VerificationSucceeded: false,
+				EnforcementMode:       executor.EnforcementModeEnforce,
+			},
+			expected: &result{
+				Succeeded: false,
+			},
+		},
 		{
 			name: "nil ArtifactReports",
-			src: &ratify.ValidationResult{
+			src: &executor.ValidationResult{
 				ArtifactReports: nil,
 			},
 			expected: &result{
@@ -71,7 +147,7 @@ func TestConvertResult(t *testing.T) {
 		},
 		{
 			name: "nonempty ArtifactReports",
-			src: &ratify.ValidationResult{
+			src: &executor.ValidationResult{
 				ArtifactReports: []*ratify.ValidationReport{
 					nil,
 					{
@@ -106,7 +182,7 @@ func TestConvertResult(t *testing.T) {
 		},
 		{
 			name: "nonempty ArtifactReports with invalid detail",
-			src: &ratify.ValidationResult{
+			src: &executor.ValidationResult{
 				ArtifactReports: []*ratify.ValidationReport{
 					nil,
 					{
@@ -135,6 +211,29 @@ func TestConvertResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "ArtifactReports served by a mirror",
+			src: &executor.ValidationResult{
+				ArtifactReports: []*ratify.ValidationReport{
+					{
+						Subject: subject1,
+						Artifact: ocispec.Descriptor{
+							Digest:      digest.FromString("artifact"),
+							Annotations: map[string]string{registrystore.AnnotationEndpoint: "mirror.example.com"},
+						},
+					},
+				},
+			},
+			expected: &result{
+				ArtifactReports: []*validationReport{
+					{
+						Subject:  subject1,
+						Artifact: digest.FromString("artifact").String(),
+						Endpoint: "mirror.example.com",
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -146,3 +245,61 @@ func TestConvertResult(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertArtifactValidation(t *testing.T) {
+	if got := convertArtifactValidation(subject1, nil, 0); got != nil {
+		t.Errorf("expected nil for nil source, got: %+v", got)
+	}
+
+	artifact := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.cncf.notary.signature",
+		Digest:       "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		Size:         10,
+	}
+	src := &executor.ValidationResult{
+		Succeeded:             true,
+		VerificationSucceeded: false,
+		EnforcementMode:       executor.EnforcementModeAudit,
+		ArtifactReports: []*ratify.ValidationReport{
+			{
+				Subject:  subject1,
+				Artifact: artifact,
+				Results: []*ratify.VerificationResult{
+					{
+						Verifier:    &mockVerifier{},
+						Description: "verification failed",
+						Detail:      map[string]string{"issuer": "test"},
+						Err:         errors.New("invalid signature"),
+					},
+				},
+			},
+		},
+	}
+	expected := &artifactValidation{
+		Subject:               subject1,
+		Succeeded:             true,
+		VerificationSucceeded: false,
+		EnforcementMode:       "audit",
+		ArtifactReports: []*artifactReport{
+			{
+				Subject:  subject1,
+				Artifact: artifact,
+				Results: []*verifierResult{
+					{
+						VerifierName: mockVerifierName,
+						VerifierType: mockVerifierType,
+						Succeeded:    false,
+						Description:  "verification failed",
+						Detail:       []byte(`{"issuer":"test"}`),
+						Error:        "invalid signature",
+					},
+				},
+			},
+		},
+		DurationMilliseconds: 1500,
+	}
+	if got := convertArtifactValidation(subject1, src, 1500*time.Millisecond); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, got)
+	}
+}
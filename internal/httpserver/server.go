@@ -23,38 +23,65 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/notaryproject/ratify/v2/internal/cache"
-	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
+	"github.com/notaryproject/ratify/v2/internal/cache/redis"
 	"github.com/notaryproject/ratify/v2/internal/controller"
 	"github.com/notaryproject/ratify/v2/internal/executor"
 	"github.com/notaryproject/ratify/v2/internal/httpserver/config"
 	"github.com/notaryproject/ratify/v2/internal/httpserver/tlssecret"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/singleflight"
 )
 
 const (
-	serverRootURL        = "/ratify/gatekeeper/v2"
-	verifyPath           = "verify"
-	mutatePath           = "mutate"
-	defaultVerifyTimeout = 5 * time.Second
-	defaultMutateTimeout = 2 * time.Second
-	readTimeout          = 5 * time.Second
-	writeTimeout         = 5 * time.Second
-	idleTimeout          = 60 * time.Second
-	defaultCacheTTL      = 5 * time.Second
+	serverRootURL         = "/ratify/gatekeeper/v2"
+	apiRootURL            = "/ratify/v2"
+	admissionRootURL      = "/ratify/admission/v1"
+	verifyPath            = "verify"
+	mutatePath            = "mutate"
+	explainPath           = "explain"
+	validateArtifactsPath = "artifacts:validate"
+	validatePath          = "validate"
+	resolvePath           = "resolve"
+	healthzPath           = "/healthz"
+	readyzPath            = "/readyz"
+	statusPath            = "/status"
+	defaultVerifyTimeout  = 5 * time.Second
+	defaultMutateTimeout  = 2 * time.Second
+	readTimeout           = 5 * time.Second
+	writeTimeout          = 5 * time.Second
+	idleTimeout           = 60 * time.Second
 )
 
 type server struct {
-	getExecutor func() *executor.ScopedExecutor
-	router      *mux.Router
-	mutateCache cache.Cache[string]
-	verifyCache cache.Cache[*result]
-	sfGroup     *singleflight.Group
+	getExecutor         func() *executor.ScopedExecutor
+	getShadowExecutor   func() *executor.ScopedExecutor
+	getConfigErrors     func() map[string]error
+	tlsReady            atomic.Bool
+	router              *mux.Router
+	adminRouter         *mux.Router
+	admissionRouter     *mux.Router
+	healthRouter        *mux.Router
+	mutateCache         cache.Cache[string]
+	verifyCache         cache.Cache[*result]
+	validationCache     cache.Cache[*artifactValidation]
+	errorCache          cache.Cache[string]
+	sfGroup             *singleflight.Group
+	warnDenials         atomic.Uint64
+	shadowDisagreements atomic.Uint64
+	shadowComparisons   sync.WaitGroup
+	// backgroundValidations tracks the validations still running after
+	// their artifact timed out.
+	backgroundValidations sync.WaitGroup
 	ServerOptions
 }
 
@@ -90,6 +117,49 @@ type ServerOptions struct {
 	// Optional.
 	MutateTimeout time.Duration
 
+	// VerifyItemTimeout is the duration to wait for the validation of each
+	// artifact of a verification request, counted from the start of its
+	// validation and capped by VerifyTimeout. An artifact not validated in
+	// time is reported with the "timeout" error code, so that the other
+	// artifacts of the request still return their results, and its
+	// validation continues in the background to fill the cache for the next
+	// request. Artifacts are only bounded by VerifyTimeout if not specified.
+	// Optional.
+	VerifyItemTimeout time.Duration
+
+	// VerifyThenPin indicates whether references are only pinned to digests
+	// that pass the verification. If set, the mutation fails for a digest that
+	// fails the verification, and the verification result is cached for the
+	// subsequent verification of the pinned reference.
+	// Optional.
+	VerifyThenPin bool
+
+	// VerifyConcurrency is the maximum number of artifacts validated
+	// concurrently for a single verification request. Default is
+	// [executor.DefaultMaxConcurrency] if not specified.
+	// Optional.
+	VerifyConcurrency int
+
+	// VerifyCache configures the cache of the verification results, keyed by
+	// the digest each artifact resolves to.
+	// Optional.
+	VerifyCache CacheOptions
+
+	// MutateCache configures the cache of the references pinned by the
+	// mutation handler, which also holds the digests the tagged artifacts of
+	// verification requests resolve to.
+	// Optional.
+	MutateCache CacheOptions
+
+	// ValidationCache configures the cache of the artifact validation API.
+	// Optional.
+	ValidationCache CacheOptions
+
+	// Redis configures the connection to Redis, which is used by the caches
+	// of the "redis" type.
+	// Optional.
+	Redis redis.Options
+
 	// DisableMutation indicates whether to disable the mutation handler.
 	// If set to true, the mutation handler will not be registered.
 	// Optional.
@@ -101,6 +171,57 @@ type ServerOptions struct {
 	// Optional.
 	DisableCRDManager bool
 
+	// EnableAdmissionWebhook indicates whether to enable the native Kubernetes
+	// admission webhooks, which validate the images of workloads and pin them
+	// to their digests without Gatekeeper. The mutating admission webhook is
+	// not enabled if DisableMutation is set.
+	// Optional.
+	EnableAdmissionWebhook bool
+
+	// AdmissionAddress is the address where the admission webhooks listen for
+	// incoming requests, in the format "host:port" (e.g., ":6003"). The
+	// admission webhooks are served with TLS without requiring a client
+	// certificate, as the API server does not present one signed by the
+	// Gatekeeper CA. The admission webhooks are served at HTTPServerAddress if
+	// not provided, which is rejected if GatekeeperCACertFile is set.
+	// Optional.
+	AdmissionAddress string
+
+	// HealthAddress is the address where the liveness, readiness and status
+	// endpoints listen for incoming requests, in the format "host:port"
+	// (e.g., ":6004"). They are served without TLS, so that the kubelet can
+	// probe the server even if it requires a client certificate signed by the
+	// Gatekeeper CA. They are served at HTTPServerAddress if not provided.
+	// Optional.
+	HealthAddress string
+
+	// KeepTagOnMutation indicates whether the mutating admission webhook keeps
+	// the tag of the pinned images in the form of "repo:tag@digest" instead
+	// of "repo@digest".
+	// Optional.
+	KeepTagOnMutation bool
+
+	// ShadowConfigFile is the path to the configuration file of the shadow
+	// executor. The shadow executor validates the same artifacts as the
+	// active executor without deciding the outcome, and disagreements are
+	// logged. Only used if DisableCRDManager is true.
+	// Optional.
+	ShadowConfigFile string
+
+	// AdminAddress is the address where the admin endpoint listens for
+	// incoming requests, in the format "host:port" (e.g., ":8443"). The admin
+	// endpoint reports the statistics of the caches and purges them. It is
+	// only served with TLS to clients presenting a certificate signed by
+	// AdminClientCACertFile. The admin endpoint is disabled if not provided.
+	// Optional.
+	AdminAddress string
+
+	// AdminClientCACertFile is the path to the CA certificate file the client
+	// certificates of the admin endpoint are verified against. Required if
+	// AdminAddress is set.
+	// Optional.
+	AdminClientCACertFile string
+
 	// CertRotatorReady is a channel that signals when the certificate rotator
 	// is ready. If not provided, the server will run without rotating the TLS
 	// certificates.
@@ -111,47 +232,113 @@ type ServerOptions struct {
 // StartServer initializes and starts the Ratify server with provided options
 // and configuration file path.
 func StartServer(opts *ServerOptions, executorConfigPath string) error {
-	server, configWatcher, err := newServer(opts, executorConfigPath)
+	server, configWatchers, err := newServer(opts, executorConfigPath)
 	if err != nil {
 		logrus.Errorf("Failed to create server: %v", err)
 		return err
 	}
 
 	logrus.Infof("Starting server at port: %s", opts.HTTPServerAddress)
-	return server.Run(opts.CertRotatorReady, configWatcher)
+	return server.Run(opts.CertRotatorReady, configWatchers...)
 }
 
-func newServer(serverOpts *ServerOptions, executorConfigPath string) (*server, *config.Watcher, error) {
-	var configWatcher *config.Watcher
-	var getExecutorFunc func() *executor.ScopedExecutor
-	var err error
+func newServer(serverOpts *ServerOptions, executorConfigPath string) (*server, []*config.Watcher, error) {
+	var configWatchers []*config.Watcher
+	var getExecutorFunc, getShadowExecutorFunc func() *executor.ScopedExecutor
+	var getConfigErrorsFunc func() map[string]error
 
 	if serverOpts.DisableCRDManager {
-		configWatcher, err = config.NewWatcher(executorConfigPath)
+		configWatcher, err := config.NewWatcher(executorConfigPath)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create config watcher: %w", err)
 		}
+		configWatchers = append(configWatchers, configWatcher)
 		getExecutorFunc = configWatcher.GetExecutor
+
+		if serverOpts.ShadowConfigFile != "" {
+			shadowWatcher, err := config.NewWatcher(serverOpts.ShadowConfigFile)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create shadow config watcher: %w", err)
+			}
+			configWatchers = append(configWatchers, shadowWatcher)
+			getShadowExecutorFunc = shadowWatcher.GetExecutor
+		}
+		getConfigErrorsFunc = func() map[string]error {
+			var errs map[string]error
+			for _, configWatcher := range configWatchers {
+				if err := configWatcher.LastReloadError(); err != nil {
+					if errs == nil {
+						errs = make(map[string]error)
+					}
+					errs[configWatcher.ConfigPath()] = err
+				}
+			}
+			return errs
+		}
 	} else {
 		getExecutorFunc = controller.GlobalExecutorManager.GetExecutor
+		getShadowExecutorFunc = controller.GlobalExecutorManager.GetShadowExecutor
+		getConfigErrorsFunc = controller.GlobalExecutorManager.GetErrors
 	}
 
-	mutateCache, err := ristretto.NewCache[string](defaultCacheTTL)
+	mutateCache, err := newCache[string](metrics.CacheMutate, serverOpts.MutateCache, serverOpts.Redis)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create mutate cache: %w", err)
 	}
-	verifyCache, err := ristretto.NewCache[*result](defaultCacheTTL)
+	verifyCache, err := newStaleCache[*result](metrics.CacheVerify, serverOpts.VerifyCache, serverOpts.Redis)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create verify cache: %w", err)
 	}
+	validationCache, err := newCache[*artifactValidation](metrics.CacheValidation, serverOpts.ValidationCache, serverOpts.Redis)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create validation cache: %w", err)
+	}
+	errorCache, err := newErrorCache(serverOpts.Redis, serverOpts.VerifyCache, serverOpts.MutateCache)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create error cache: %w", err)
+	}
 
 	server := &server{
-		router:        mux.NewRouter(),
-		mutateCache:   mutateCache,
-		verifyCache:   verifyCache,
-		sfGroup:       new(singleflight.Group),
-		getExecutor:   getExecutorFunc,
-		ServerOptions: *serverOpts,
+		router:            mux.NewRouter(),
+		mutateCache:       mutateCache,
+		verifyCache:       verifyCache,
+		validationCache:   validationCache,
+		errorCache:        errorCache,
+		sfGroup:           new(singleflight.Group),
+		getExecutor:       getExecutorFunc,
+		getShadowExecutor: getShadowExecutorFunc,
+		getConfigErrors:   getConfigErrorsFunc,
+		ServerOptions:     *serverOpts,
+	}
+	// The TLS configuration is loaded before serving requests if TLS is
+	// enabled.
+	server.tlsReady.Store(!server.tlsEnabled())
+	if server.AdminAddress != "" {
+		if !server.tlsEnabled() || server.AdminClientCACertFile == "" {
+			return nil, nil, fmt.Errorf("admin endpoint requires TLS and a client CA certificate")
+		}
+		server.adminRouter = mux.NewRouter()
+		if err := server.registerAdminHandlers(); err != nil {
+			return nil, nil, fmt.Errorf("failed to register admin handlers: %w", err)
+		}
+	}
+	if server.EnableAdmissionWebhook {
+		switch {
+		case server.AdmissionAddress != "":
+			if !server.tlsEnabled() {
+				return nil, nil, fmt.Errorf("admission webhook endpoint requires TLS")
+			}
+			server.admissionRouter = mux.NewRouter()
+			server.admissionRouter.Use(tracing.Middleware, middlewareWithTraceID)
+		case server.GatekeeperCACertFile != "":
+			return nil, nil, fmt.Errorf("admission webhook requires an admission address when the Gatekeeper CA certificate is set, as the API server does not present a client certificate signed by it")
+		default:
+			server.admissionRouter = server.router
+		}
+	}
+	server.healthRouter = server.router
+	if server.HealthAddress != "" {
+		server.healthRouter = mux.NewRouter()
 	}
 	if server.VerifyTimeout == 0 {
 		server.VerifyTimeout = defaultVerifyTimeout
@@ -163,10 +350,13 @@ func newServer(serverOpts *ServerOptions, executorConfigPath string) (*server, *
 	if err := server.registerHandlers(); err != nil {
 		return nil, nil, fmt.Errorf("failed to register handlers: %w", err)
 	}
-	return server, configWatcher, nil
+	return server, configWatchers, nil
 }
 
 func (s *server) registerHandlers() error {
+	// Join the trace of the caller, if any, and assign a trace ID to the
+	// request for log correlation.
+	s.router.Use(tracing.Middleware, middlewareWithTraceID)
 	if err := s.registerVerifyHandler(); err != nil {
 		return err
 	}
@@ -176,9 +366,35 @@ func (s *server) registerHandlers() error {
 			return err
 		}
 	}
+	if err := s.registerExplainHandler(); err != nil {
+		return err
+	}
+	if s.EnableAdmissionWebhook {
+		if err := s.registerValidateAdmissionHandler(); err != nil {
+			return err
+		}
+		if !s.DisableMutation {
+			if err := s.registerMutateAdmissionHandler(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.registerValidateArtifactsHandler(); err != nil {
+		return err
+	}
+	s.registerHealthHandlers()
 	return nil
 }
 
+// registerHealthHandlers registers the liveness, readiness and status
+// handlers. They are not subject to the request timeouts as they do not
+// validate any artifact.
+func (s *server) registerHealthHandlers() {
+	s.healthRouter.Methods(http.MethodGet).Path(healthzPath).Handler(s.healthzHandler())
+	s.healthRouter.Methods(http.MethodGet).Path(readyzPath).Handler(s.readyzHandler())
+	s.healthRouter.Methods(http.MethodGet).Path(statusPath).Handler(s.statusHandler())
+}
+
 // TODO: implement mutate handler.
 func (s *server) registerMutateHandler() error {
 	mutateURL, err := url.JoinPath(serverRootURL, mutatePath)
@@ -198,6 +414,42 @@ func (s *server) registerVerifyHandler() error {
 	return nil
 }
 
+func (s *server) registerExplainHandler() error {
+	explainURL, err := url.JoinPath(serverRootURL, explainPath)
+	if err != nil {
+		return err
+	}
+	s.router.Methods(http.MethodGet).Path(explainURL).Handler(middlewareWithTimeout(s.explainHandler(), s.VerifyTimeout))
+	return nil
+}
+
+func (s *server) registerValidateArtifactsHandler() error {
+	validateURL, err := url.JoinPath(apiRootURL, validateArtifactsPath)
+	if err != nil {
+		return err
+	}
+	s.router.Methods(http.MethodPost).Path(validateURL).Handler(middlewareWithTimeout(s.validateArtifactsHandler(), s.VerifyTimeout))
+	return nil
+}
+
+func (s *server) registerValidateAdmissionHandler() error {
+	validateURL, err := url.JoinPath(admissionRootURL, validatePath)
+	if err != nil {
+		return err
+	}
+	s.admissionRouter.Methods(http.MethodPost).Path(validateURL).Handler(middlewareWithTimeout(s.validateAdmissionHandler(), s.VerifyTimeout))
+	return nil
+}
+
+func (s *server) registerMutateAdmissionHandler() error {
+	mutateURL, err := url.JoinPath(admissionRootURL, mutatePath)
+	if err != nil {
+		return err
+	}
+	s.admissionRouter.Methods(http.MethodPost).Path(mutateURL).Handler(middlewareWithTimeout(s.mutateAdmissionHandler(), s.MutateTimeout))
+	return nil
+}
+
 func (s *server) verifyHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_ = s.verify(r.Context(), w, r)
@@ -210,6 +462,59 @@ func (s *server) mutateHandler() http.HandlerFunc {
 	}
 }
 
+func (s *server) explainHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.explain(w, r)
+	}
+}
+
+func (s *server) validateArtifactsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.validateArtifacts(r.Context(), w, r)
+	}
+}
+
+func (s *server) validateAdmissionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.validateAdmission(r.Context(), w, r)
+	}
+}
+
+func (s *server) mutateAdmissionHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.mutateAdmission(r.Context(), w, r)
+	}
+}
+
+func (s *server) healthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.healthz(w, r)
+	}
+}
+
+func (s *server) readyzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.readyz(w, r)
+	}
+}
+
+func (s *server) statusHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = s.status(w, r)
+	}
+}
+
+// middlewareWithTraceID sets the trace ID of the request, taken from the
+// configured trace ID headers or generated, in the request context and returns
+// it in the same headers of the response.
+func middlewareWithTraceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := logger.InitContext(r.Context(), r)
+		logger.SetTraceIDHeader(ctx, w.Header())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func middlewareWithTimeout(next http.Handler, timeout time.Duration) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), timeout)
@@ -218,9 +523,14 @@ func middlewareWithTimeout(next http.Handler, timeout time.Duration) http.Handle
 	})
 }
 
+// tlsEnabled returns true if the server serves requests with TLS.
+func (s *server) tlsEnabled() bool {
+	return s.CertFile != "" && s.KeyFile != ""
+}
+
 // Run starts the HTTP server and listens for incoming requests.
 // It also handles graceful shutdown on receiving an interrupt signal.
-func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watcher) error {
+func (s *server) Run(certRotatorReady chan struct{}, configWatchers ...*config.Watcher) error {
 	srv := &http.Server{
 		Addr:         s.HTTPServerAddress,
 		Handler:      s.router,
@@ -228,10 +538,49 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 		ReadTimeout:  readTimeout,
 		IdleTimeout:  idleTimeout,
 	}
+	var adminSrv *http.Server
+	if s.adminRouter != nil {
+		adminSrv = &http.Server{
+			Addr:         s.AdminAddress,
+			Handler:      s.adminRouter,
+			WriteTimeout: writeTimeout,
+			ReadTimeout:  readTimeout,
+			IdleTimeout:  idleTimeout,
+		}
+	}
+	var admissionSrv *http.Server
+	if s.AdmissionAddress != "" && s.admissionRouter != nil {
+		admissionSrv = &http.Server{
+			Addr:         s.AdmissionAddress,
+			Handler:      s.admissionRouter,
+			WriteTimeout: writeTimeout,
+			ReadTimeout:  readTimeout,
+			IdleTimeout:  idleTimeout,
+		}
+	}
+	var healthSrv *http.Server
+	if s.HealthAddress != "" {
+		healthSrv = &http.Server{
+			Addr:         s.HealthAddress,
+			Handler:      s.healthRouter,
+			WriteTimeout: writeTimeout,
+			ReadTimeout:  readTimeout,
+			IdleTimeout:  idleTimeout,
+		}
+		// The health endpoints are served while waiting for the TLS
+		// certificates, reporting the server as not ready until they are
+		// loaded.
+		go func() {
+			logrus.Infof("starting health server at %s", s.HealthAddress)
+			if err := healthSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logrus.Errorf("failed to start health server: %v", err)
+			}
+		}()
+	}
 	go func() {
-		// Start the configuration watcher (if any) and ensure
-		// it is properly stopped when the server goroutine exits.
-		if configWatcher != nil {
+		// Start the configuration watchers (if any) and ensure
+		// they are properly stopped when the server goroutine exits.
+		for _, configWatcher := range configWatchers {
 			if err := configWatcher.Start(); err != nil {
 				logrus.WithError(err).Error("failed to start config watcher")
 				return
@@ -239,7 +588,7 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 			defer configWatcher.Stop()
 		}
 
-		if s.CertFile != "" && s.KeyFile != "" {
+		if s.tlsEnabled() {
 			logrus.Infof("starting server with TLS at %s", s.HTTPServerAddress)
 			if certRotatorReady != nil {
 				<-certRotatorReady
@@ -256,6 +605,13 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 				return
 			}
 			defer certWatcher.Stop()
+			s.tlsReady.Store(true)
+			if adminSrv != nil {
+				go s.serveAdmin(adminSrv)
+			}
+			if admissionSrv != nil {
+				go s.serveAdmission(admissionSrv)
+			}
 
 			// Use GetConfigForClient to dynamically load certificates.
 			srv.TLSConfig = &tls.Config{
@@ -280,9 +636,72 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.VerifyTimeout)
 	defer cancel()
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(ctx); err != nil {
+			logrus.Errorf("failed to shutdown admin server: %v", err)
+		}
+	}
+	if admissionSrv != nil {
+		if err := admissionSrv.Shutdown(ctx); err != nil {
+			logrus.Errorf("failed to shutdown admission server: %v", err)
+		}
+	}
+	if healthSrv != nil {
+		if err := healthSrv.Shutdown(ctx); err != nil {
+			logrus.Errorf("failed to shutdown health server: %v", err)
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		logrus.Errorf("failed to shutdown server: %v", err)
 		return err
 	}
 	return nil
 }
+
+// serveAdmin serves the admin endpoint with TLS, requiring the clients to
+// present a certificate signed by the admin client CA.
+func (s *server) serveAdmin(srv *http.Server) {
+	certWatcher, err := tlssecret.NewWatcher(s.AdminClientCACertFile, s.CertFile, s.KeyFile)
+	if err != nil {
+		logrus.Errorf("failed to create admin TLS secret watcher: %v", err)
+		return
+	}
+	if err = certWatcher.Start(); err != nil {
+		logrus.Errorf("failed to start admin TLS secret watcher: %v", err)
+		return
+	}
+	defer certWatcher.Stop()
+
+	logrus.Infof("starting admin server with TLS at %s", s.AdminAddress)
+	srv.TLSConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS13,
+		GetConfigForClient: certWatcher.GetConfigForClient,
+	}
+	if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		logrus.Errorf("failed to start admin server: %v", err)
+	}
+}
+
+// serveAdmission serves the admission webhooks with TLS, without requiring
+// the clients to present a certificate.
+func (s *server) serveAdmission(srv *http.Server) {
+	certWatcher, err := tlssecret.NewWatcher("", s.CertFile, s.KeyFile)
+	if err != nil {
+		logrus.Errorf("failed to create admission TLS secret watcher: %v", err)
+		return
+	}
+	if err = certWatcher.Start(); err != nil {
+		logrus.Errorf("failed to start admission TLS secret watcher: %v", err)
+		return
+	}
+	defer certWatcher.Stop()
+
+	logrus.Infof("starting admission server with TLS at %s", s.AdmissionAddress)
+	srv.TLSConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS13,
+		GetConfigForClient: certWatcher.GetConfigForClient,
+	}
+	if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+		logrus.Errorf("failed to start admission server: %v", err)
+	}
+}
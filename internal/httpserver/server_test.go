@@ -26,14 +26,18 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/logger"
 	"github.com/notaryproject/ratify/v2/internal/store"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -357,3 +361,169 @@ func createTLSCertAndKey(dir string) (string, string, error) {
 
 	return certPath, keyPath, nil
 }
+
+func TestNewServer_ShadowConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	raw, err := json.Marshal(&executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{registryPattern},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal executor options: %v", err)
+	}
+	configPath := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configPath, raw, 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	if _, _, err := newServer(&ServerOptions{
+		DisableCRDManager: true,
+		ShadowConfigFile:  invalidConfigPath,
+	}, configPath); err == nil {
+		t.Fatalf("expected error for invalid shadow config path")
+	}
+
+	server, configWatchers, err := newServer(&ServerOptions{
+		DisableCRDManager: true,
+		ShadowConfigFile:  configPath,
+	}, configPath)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if len(configWatchers) != 2 {
+		t.Errorf("expected 2 config watchers, got: %d", len(configWatchers))
+	}
+	if server.getShadowExecutor() == nil {
+		t.Errorf("expected shadow executor to be loaded")
+	}
+}
+
+func TestMiddlewareWithTraceID(t *testing.T) {
+	if err := logger.InitLogConfig(logger.Config{
+		RequestHeaders: map[string]interface{}{
+			logger.TraceIDHeaderName: []string{"X-Test-Trace-Id"},
+		},
+	}); err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+	var traceID string
+	handler := middlewareWithTraceID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceID = logger.GetTraceID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/verify", nil)
+	req.Header.Set("X-Test-Trace-Id", "test-trace-id")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if traceID != "test-trace-id" {
+		t.Errorf("expected trace ID from the request header, got: %q", traceID)
+	}
+	if got := w.Header().Get("X-Test-Trace-Id"); got != "test-trace-id" {
+		t.Errorf("expected trace ID in the response header, got: %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/verify", nil))
+	if traceID == "" || traceID == "test-trace-id" {
+		t.Errorf("expected a generated trace ID, got: %q", traceID)
+	}
+	if got := w.Header().Get("X-Test-Trace-Id"); got != traceID {
+		t.Errorf("expected generated trace ID %q in the response header, got: %q", traceID, got)
+	}
+}
+
+func TestNewServer_AdminEndpoint(t *testing.T) {
+	if _, _, err := newServer(&ServerOptions{
+		DisableCRDManager: true,
+		AdminAddress:      ":8443",
+	}, invalidConfigPath); err == nil {
+		t.Errorf("expected error for admin endpoint without TLS")
+	}
+	if _, _, err := newServer(&ServerOptions{
+		DisableCRDManager: true,
+		CertFile:          "cert.pem",
+		KeyFile:           "key.pem",
+		AdminAddress:      ":8443",
+	}, invalidConfigPath); err == nil {
+		t.Errorf("expected error for admin endpoint without client CA certificate")
+	}
+}
+
+func TestNewServer_AdmissionEndpoint(t *testing.T) {
+	if _, _, err := newServer(&ServerOptions{
+		EnableAdmissionWebhook: true,
+		CertFile:               "cert.pem",
+		KeyFile:                "key.pem",
+		GatekeeperCACertFile:   "ca.pem",
+	}, ""); err == nil {
+		t.Errorf("expected error for admission webhook sharing the Gatekeeper listener")
+	}
+	if _, _, err := newServer(&ServerOptions{
+		EnableAdmissionWebhook: true,
+		AdmissionAddress:       ":6003",
+	}, ""); err == nil {
+		t.Errorf("expected error for admission endpoint without TLS")
+	}
+
+	validateURL := admissionRootURL + "/" + validatePath
+	server, _, err := newServer(&ServerOptions{
+		EnableAdmissionWebhook: true,
+		CertFile:               "cert.pem",
+		KeyFile:                "key.pem",
+		GatekeeperCACertFile:   "ca.pem",
+		AdmissionAddress:       ":6003",
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	var match mux.RouteMatch
+	if server.router.Match(httptest.NewRequest(http.MethodPost, validateURL, nil), &match) {
+		t.Errorf("expected the admission webhook not to be served at the Gatekeeper listener")
+	}
+	if !server.admissionRouter.Match(httptest.NewRequest(http.MethodPost, validateURL, nil), &match) {
+		t.Errorf("expected the admission webhook to be served at the admission listener")
+	}
+
+	server, _, err = newServer(&ServerOptions{
+		EnableAdmissionWebhook: true,
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if !server.router.Match(httptest.NewRequest(http.MethodPost, validateURL, nil), &match) {
+		t.Errorf("expected the admission webhook to be served at the server listener")
+	}
+}
+
+func TestNewServer_HealthEndpoint(t *testing.T) {
+	server, _, err := newServer(&ServerOptions{
+		CertFile:             "cert.pem",
+		KeyFile:              "key.pem",
+		GatekeeperCACertFile: "ca.pem",
+		HealthAddress:        ":6004",
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	var match mux.RouteMatch
+	for _, path := range []string{healthzPath, readyzPath, statusPath} {
+		if server.router.Match(httptest.NewRequest(http.MethodGet, path, nil), &match) {
+			t.Errorf("expected %s not to be served at the Gatekeeper listener", path)
+		}
+		if !server.healthRouter.Match(httptest.NewRequest(http.MethodGet, path, nil), &match) {
+			t.Errorf("expected %s to be served at the health listener", path)
+		}
+	}
+
+	server, _, err = newServer(&ServerOptions{}, "")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if !server.router.Match(httptest.NewRequest(http.MethodGet, readyzPath, nil), &match) {
+		t.Errorf("expected the readiness endpoint to be served at the server listener")
+	}
+}
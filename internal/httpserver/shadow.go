@@ -0,0 +1,87 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	e "github.com/notaryproject/ratify/v2/internal/executor"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+)
+
+// startShadowComparison validates the artifacts with the shadow executor, if
+// any, in the background and compares the outcomes with the results of the
+// active executor. The comparison outlives the request, but is bounded by the
+// verify timeout.
+func (s *server) startShadowComparison(ctx context.Context, activeResults []*e.ArtifactResult) {
+	if s.getShadowExecutor == nil || len(activeResults) == 0 {
+		return
+	}
+	shadow := s.getShadowExecutor()
+	if shadow == nil {
+		return
+	}
+
+	s.shadowComparisons.Add(1)
+	go func() {
+		defer s.shadowComparisons.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.VerifyTimeout)
+		defer cancel()
+		s.compareShadow(ctx, shadow, activeResults)
+	}()
+}
+
+// compareShadow validates the artifacts with the shadow executor and reports
+// every artifact whose outcome differs from the active executor. The shadow
+// results are not cached.
+func (s *server) compareShadow(ctx context.Context, shadow *e.ScopedExecutor, activeResults []*e.ArtifactResult) {
+	artifacts := make([]string, len(activeResults))
+	for idx, activeResult := range activeResults {
+		artifacts[idx] = activeResult.Artifact
+	}
+	shadowResults := shadow.ValidateArtifacts(ctx, artifacts, e.ValidateArtifactsOptions{
+		MaxConcurrency: s.VerifyConcurrency,
+	})
+	for idx, shadowResult := range shadowResults {
+		activeResult := activeResults[idx]
+		if allowed(activeResult) == allowed(shadowResult) {
+			continue
+		}
+		s.shadowDisagreements.Add(1)
+		metrics.ReportShadowDisagreement(ctx)
+		logger.GetLogger(ctx, logOpt).Warnf("shadow executor disagrees on artifact %s: active result: %s, shadow result: %s", activeResult.Artifact, describeResult(activeResult), describeResult(shadowResult))
+	}
+}
+
+// allowed reports whether the artifact is allowed by the result.
+func allowed(artifactResult *e.ArtifactResult) bool {
+	return artifactResult.Err == nil && artifactResult.Result != nil && artifactResult.Result.Succeeded
+}
+
+// describeResult renders the result as reported to Gatekeeper.
+func describeResult(artifactResult *e.ArtifactResult) string {
+	if artifactResult.Err != nil {
+		return fmt.Sprintf("error: %v", artifactResult.Err)
+	}
+	rendered, err := json.Marshal(convertResult(artifactResult.Result))
+	if err != nil {
+		return fmt.Sprintf("failed to render result: %v", err)
+	}
+	return string(rendered)
+}
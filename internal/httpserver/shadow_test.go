@@ -0,0 +1,99 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/executor"
+	"golang.org/x/sync/singleflight"
+)
+
+func TestVerify_ShadowExecutor(t *testing.T) {
+	shadow, err := executor.NewScopedExecutor(executor.Options{
+		Executors: []executor.ScopedOptions{
+			{
+				Scopes:          []string{"example.com"},
+				EnforcementMode: executor.EnforcementModeExempt,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create shadow executor: %v", err)
+	}
+
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return &executor.ScopedExecutor{}
+		},
+		getShadowExecutor: func() *executor.ScopedExecutor {
+			return shadow
+		},
+		verifyCache: &mockResultCache{entries: map[string]*result{
+			"verify_example.com/cached:v1_gen=0": {
+				Succeeded: false,
+			},
+		}},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+		ServerOptions: ServerOptions{
+			VerifyTimeout: time.Second,
+		},
+	}
+
+	// The active executor fails to validate all artifacts. The shadow executor
+	// allows artifacts from example.com and fails on the others.
+	req := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(`{"request": {"keys": ["example.com/app:v1", "other.com/app:v1", "example.com/cached:v1"]}}`))
+	w := httptest.NewRecorder()
+	if err := server.verify(context.Background(), w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.shadowComparisons.Wait()
+
+	if got := server.shadowDisagreements.Load(); got != 1 {
+		t.Errorf("expected 1 disagreement, got: %d", got)
+	}
+}
+
+func TestVerify_NoShadowExecutor(t *testing.T) {
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return &executor.ScopedExecutor{}
+		},
+		getShadowExecutor: func() *executor.ScopedExecutor {
+			return nil
+		},
+		verifyCache:     &mockResultCache{entries: make(map[string]*result)},
+		validationCache: &mockValidationCache{entries: make(map[string]*artifactValidation)},
+		sfGroup:         new(singleflight.Group),
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(`{"request": {"keys": ["example.com/app:v1"]}}`))
+	w := httptest.NewRecorder()
+	if err := server.verify(context.Background(), w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.shadowComparisons.Wait()
+
+	if got := server.shadowDisagreements.Load(); got != 0 {
+		t.Errorf("expected no disagreement, got: %d", got)
+	}
+}
@@ -79,8 +79,9 @@ const (
 	Verifier componentType = "verifier"
 	// Plugin is the component type for plugin.
 	Plugin componentType = "plugin"
-
-	traceIDHeaderName = "traceIDHeaderName"
+	// TraceIDHeaderName is the key of the trace ID header names in
+	// [Config.RequestHeaders].
+	TraceIDHeaderName = "traceIDHeaderName"
 )
 
 // InitLogConfig initializes log configuration for the server.
@@ -141,8 +142,8 @@ func initTraceIDHeaders(headers map[string]interface{}) {
 	if headers == nil {
 		return
 	}
-	if _, ok := headers[traceIDHeaderName]; ok {
-		if names, ok := headers[traceIDHeaderName].([]string); ok {
+	if _, ok := headers[TraceIDHeaderName]; ok {
+		if names, ok := headers[TraceIDHeaderName].([]string); ok {
 			traceIDHeaderNames = append(traceIDHeaderNames, names...)
 		}
 	}
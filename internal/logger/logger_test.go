@@ -131,14 +131,14 @@ func TestInitTraceIDHeaders(t *testing.T) {
 		{
 			name: "headers do not contain traceIDHeader",
 			headers: map[string]interface{}{
-				traceIDHeaderName: "test",
+				TraceIDHeaderName: "test",
 			},
 			expectedNames: make([]string, 0),
 		},
 		{
 			name: "headers contain traceIDHeader",
 			headers: map[string]interface{}{
-				traceIDHeaderName: []string{traceIDName},
+				TraceIDHeaderName: []string{traceIDName},
 			},
 			expectedNames: []string{traceIDName},
 		},
@@ -194,7 +194,7 @@ func TestInitLogConfig(t *testing.T) {
 	config := Config{
 		Formatter: "text",
 		RequestHeaders: map[string]interface{}{
-			traceIDHeaderName: []string{traceIDName},
+			TraceIDHeaderName: []string{traceIDName},
 		},
 	}
 	if err := InitLogConfig(config); err != nil {
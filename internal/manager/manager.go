@@ -50,7 +50,7 @@ func init() {
 
 // StartManager creates a new Manager which is responsible for creating
 // Controllers.
-func StartManager(certRotatorReady chan struct{}, disableMutation bool, disableCRDManager bool) {
+func StartManager(certRotatorReady chan struct{}, disableMutation bool, disableCRDManager bool, enableAdmissionWebhook bool) {
 	ctrl.SetLogger(logrusr.New(logrus.StandardLogger()))
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
@@ -60,7 +60,7 @@ func StartManager(certRotatorReady chan struct{}, disableMutation bool, disableC
 		os.Exit(1)
 	}
 
-	setupCertRotator(certRotatorReady, mgr, disableMutation)
+	setupCertRotator(certRotatorReady, mgr, disableMutation, enableAdmissionWebhook)
 	setupCRDControllers(mgr, disableCRDManager)
 
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -69,7 +69,7 @@ func StartManager(certRotatorReady chan struct{}, disableMutation bool, disableC
 	}
 }
 
-func setupCertRotator(certRotatorReady chan struct{}, mgr ctrl.Manager, disableMutation bool) {
+func setupCertRotator(certRotatorReady chan struct{}, mgr ctrl.Manager, disableMutation bool, enableAdmissionWebhook bool) {
 	if certRotatorReady == nil {
 		setupLog.Info("cert rotator is disabled")
 		return
@@ -89,6 +89,18 @@ func setupCertRotator(certRotatorReady chan struct{}, mgr ctrl.Manager, disableM
 			Type: rotator.ExternalDataProvider,
 		})
 	}
+	if enableAdmissionWebhook {
+		webhooks = append(webhooks, rotator.WebhookInfo{
+			Name: "ratify-validating-webhook",
+			Type: rotator.Validating,
+		})
+		if !disableMutation {
+			webhooks = append(webhooks, rotator.WebhookInfo{
+				Name: "ratify-mutating-webhook",
+				Type: rotator.Mutating,
+			})
+		}
+	}
 
 	namespace := pod.Namespace()
 	serviceName := pod.ServiceName()
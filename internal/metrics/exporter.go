@@ -0,0 +1,64 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	metricsPath       = "/metrics"
+	readHeaderTimeout = 5 * time.Second
+)
+
+// StartPrometheusExporter initializes the instruments with a Prometheus
+// exporter and serves the metrics at "/metrics" on the given port in the
+// background.
+func StartPrometheusExporter(port int) error {
+	if port <= 0 || port > 65535 {
+		return fmt.Errorf("invalid metrics port %d", port)
+	}
+	reader, err := prometheus.New()
+	if err != nil {
+		return fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(views()...))
+	if err = initInstruments(provider); err != nil {
+		return fmt.Errorf("failed to create instruments: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	go func() {
+		logrus.Infof("serving metrics at %s%s", server.Addr, metricsPath)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("failed to serve metrics: %v", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the OpenTelemetry instruments of the Ratify server,
+// executors and stores. Reporting is a no-op until the instruments are
+// initialized by [StartPrometheusExporter].
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const scope = "github.com/notaryproject/ratify/v2"
+
+// Names of the instruments.
+const (
+	metricNameRequestDuration         = "ratify_request_duration"
+	metricNameRequestItemCount        = "ratify_request_item_count"
+	metricNameCacheCount              = "ratify_cache_count"
+	metricNameSingleflightCount       = "ratify_singleflight_count"
+	metricNameVerifierDuration        = "ratify_verifier_duration"
+	metricNameRegistryRequestCount    = "ratify_registry_request_count"
+	metricNameCredentialFetchDuration = "ratify_credential_fetch_duration"
+	metricNameWarnDenialCount         = "ratify_warn_denial_count"
+	metricNameShadowDisagreementCount = "ratify_shadow_disagreement_count"
+)
+
+// Handlers of the server reported by [ReportRequest].
+const (
+	HandlerVerify            = "verify"
+	HandlerMutate            = "mutate"
+	HandlerAdmissionValidate = "admission_validate"
+	HandlerAdmissionMutate   = "admission_mutate"
+)
+
+// Caches of the server reported by [ReportCacheLookup].
+const (
+	CacheVerify     = "verify"
+	CacheMutate     = "mutate"
+	CacheValidation = "validation"
+)
+
+// Operations deduplicated by singleflight reported by [ReportSingleflight].
+const (
+	OperationValidate = "validate"
+	OperationResolve  = "resolve"
+)
+
+var (
+	requestDuration         metric.Int64Histogram
+	requestItemCount        metric.Int64Counter
+	cacheCount              metric.Int64Counter
+	singleflightCount       metric.Int64Counter
+	verifierDuration        metric.Int64Histogram
+	registryRequestCount    metric.Int64Counter
+	credentialFetchDuration metric.Int64Histogram
+	warnDenialCount         metric.Int64Counter
+	shadowDisagreementCount metric.Int64Counter
+)
+
+// views defines the buckets of the histograms in milliseconds. The boundaries
+// are bounded by the default verification and mutation timeouts.
+func views() []sdkmetric.View {
+	histogram := func(name string, boundaries ...float64) sdkmetric.View {
+		return sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  name,
+				Scope: instrumentation.Scope{Name: scope},
+			},
+			sdkmetric.Stream{
+				Aggregation: sdkmetric.AggregationExplicitBucketHistogram{
+					Boundaries: boundaries,
+				},
+			},
+		)
+	}
+	return []sdkmetric.View{
+		histogram(metricNameRequestDuration, 0, 10, 30, 50, 100, 200, 300, 400, 500, 600, 800, 1000, 1200, 1600, 2000, 2600, 3200, 4000, 4900),
+		histogram(metricNameVerifierDuration, 0, 10, 50, 100, 200, 300, 400, 600, 800, 1100, 1500, 2000),
+		histogram(metricNameCredentialFetchDuration, 0, 10, 50, 100, 200, 300, 400, 500, 600, 800, 1000, 1200),
+	}
+}
+
+// initInstruments creates the instruments from the meter provider.
+func initInstruments(provider metric.MeterProvider) error {
+	meter := provider.Meter(scope)
+	var err error
+	if requestDuration, err = meter.Int64Histogram(metricNameRequestDuration, metric.WithUnit("ms"), metric.WithDescription("duration of the requests to the server in ms")); err != nil {
+		return err
+	}
+	if requestItemCount, err = meter.Int64Counter(metricNameRequestItemCount, metric.WithDescription("number of artifacts in the requests to the server")); err != nil {
+		return err
+	}
+	if cacheCount, err = meter.Int64Counter(metricNameCacheCount, metric.WithDescription("cache hit and miss count")); err != nil {
+		return err
+	}
+	if singleflightCount, err = meter.Int64Counter(metricNameSingleflightCount, metric.WithDescription("count of operations deduplicated by singleflight or not")); err != nil {
+		return err
+	}
+	if verifierDuration, err = meter.Int64Histogram(metricNameVerifierDuration, metric.WithUnit("ms"), metric.WithDescription("duration of a single verifier in ms")); err != nil {
+		return err
+	}
+	if registryRequestCount, err = meter.Int64Counter(metricNameRegistryRequestCount, metric.WithDescription("registry request count")); err != nil {
+		return err
+	}
+	if credentialFetchDuration, err = meter.Int64Histogram(metricNameCredentialFetchDuration, metric.WithUnit("ms"), metric.WithDescription("duration of fetching registry credentials in ms")); err != nil {
+		return err
+	}
+	if warnDenialCount, err = meter.Int64Counter(metricNameWarnDenialCount, metric.WithDescription("count of artifacts allowed only because their executor is in warn mode")); err != nil {
+		return err
+	}
+	shadowDisagreementCount, err = meter.Int64Counter(metricNameShadowDisagreementCount, metric.WithDescription("count of artifacts the shadow executor disagrees on with the active executor"))
+	return err
+}
+
+// ReportRequest reports a request to the server.
+// Attributes:
+// handler: the handler of the request, e.g. [HandlerVerify]
+func ReportRequest(ctx context.Context, handler string, items int, duration time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("handler", handler))
+	if requestDuration != nil {
+		requestDuration.Record(ctx, duration.Milliseconds(), attrs)
+	}
+	if requestItemCount != nil {
+		requestItemCount.Add(ctx, int64(items), attrs)
+	}
+}
+
+// ReportCacheLookup reports a cache hit or miss.
+// Attributes:
+// cache: the name of the cache, e.g. [CacheVerify]
+// hit: whether the value was found in the cache
+func ReportCacheLookup(ctx context.Context, cache string, hit bool) {
+	if cacheCount != nil {
+		cacheCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("cache", cache),
+			attribute.Bool("hit", hit)))
+	}
+}
+
+// ReportSingleflight reports an operation run through singleflight.
+// Attributes:
+// operation: the deduplicated operation, e.g. [OperationValidate]
+// shared: whether the result was shared with a concurrent caller
+func ReportSingleflight(ctx context.Context, operation string, shared bool) {
+	if singleflightCount != nil {
+		singleflightCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("operation", operation),
+			attribute.Bool("shared", shared)))
+	}
+}
+
+// ReportVerifierDuration reports the duration of a single verifier.
+// Attributes:
+// verifier: the name of the verifier
+// verifier_type: the type of the verifier
+// success: whether the verification succeeded
+// error: whether the verifier failed with an error
+func ReportVerifierDuration(ctx context.Context, duration time.Duration, verifierName, verifierType string, success, isError bool) {
+	if verifierDuration != nil {
+		verifierDuration.Record(ctx, duration.Milliseconds(), metric.WithAttributes(
+			attribute.String("verifier", verifierName),
+			attribute.String("verifier_type", verifierType),
+			attribute.Bool("success", success),
+			attribute.Bool("error", isError)))
+	}
+}
+
+// ReportRegistryRequest reports a request to a registry.
+// Attributes:
+// registry_host: the host name of the registry
+// status_code: the status code of the response, 0 if no response is received
+func ReportRegistryRequest(ctx context.Context, registryHost string, statusCode int) {
+	if registryRequestCount != nil {
+		registryRequestCount.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("registry_host", registryHost),
+			attribute.Int("status_code", statusCode)))
+	}
+}
+
+// ReportCredentialFetch reports fetching the credential of a registry from a
+// credential provider.
+// Attributes:
+// registry_host: the host name of the registry
+// success: whether the credential was fetched
+func ReportCredentialFetch(ctx context.Context, duration time.Duration, registryHost string, success bool) {
+	if credentialFetchDuration != nil {
+		credentialFetchDuration.Record(ctx, duration.Milliseconds(), metric.WithAttributes(
+			attribute.String("registry_host", registryHost),
+			attribute.Bool("success", success)))
+	}
+}
+
+// ReportWarnDenial reports an artifact allowed only because its executor is
+// in warn mode.
+func ReportWarnDenial(ctx context.Context) {
+	if warnDenialCount != nil {
+		warnDenialCount.Add(ctx, 1)
+	}
+}
+
+// ReportShadowDisagreement reports an artifact the shadow executor disagrees
+// on with the active executor.
+func ReportShadowDisagreement(ctx context.Context) {
+	if shadowDisagreementCount != nil {
+		shadowDisagreementCount.Add(ctx, 1)
+	}
+}
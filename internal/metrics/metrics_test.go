@@ -0,0 +1,162 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newTestReader initializes the instruments with a manual reader.
+func newTestReader(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(views()...))
+	if err := initInstruments(provider); err != nil {
+		t.Fatalf("failed to initialize instruments: %v", err)
+	}
+	return reader
+}
+
+// collect returns the metrics collected by the reader keyed by name.
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	collected := make(map[string]metricdata.Aggregation)
+	for _, scopeMetrics := range data.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			collected[m.Name] = m.Data
+		}
+	}
+	return collected
+}
+
+// sum returns the value of the counter with the attributes.
+func sum(t *testing.T, data metricdata.Aggregation, attrs ...attribute.KeyValue) int64 {
+	t.Helper()
+	counter, ok := data.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("expected int64 sum, got %T", data)
+	}
+	set := attribute.NewSet(attrs...)
+	for _, point := range counter.DataPoints {
+		if point.Attributes.Equals(&set) {
+			return point.Value
+		}
+	}
+	return 0
+}
+
+func TestReport_NotInitialized(_ *testing.T) {
+	// Reporting before the instruments are initialized must not panic.
+	ctx := context.Background()
+	ReportRequest(ctx, HandlerVerify, 1, time.Second)
+	ReportCacheLookup(ctx, CacheVerify, true)
+	ReportSingleflight(ctx, OperationValidate, false)
+	ReportVerifierDuration(ctx, time.Second, "verifier", "notation", true, false)
+	ReportRegistryRequest(ctx, "registry.example.com", http.StatusOK)
+	ReportCredentialFetch(ctx, time.Second, "registry.example.com", true)
+	ReportWarnDenial(ctx)
+	ReportShadowDisagreement(ctx)
+}
+
+func TestReport(t *testing.T) {
+	reader := newTestReader(t)
+	ctx := context.Background()
+
+	ReportRequest(ctx, HandlerVerify, 3, 20*time.Millisecond)
+	ReportRequest(ctx, HandlerVerify, 2, 40*time.Millisecond)
+	ReportCacheLookup(ctx, CacheVerify, true)
+	ReportCacheLookup(ctx, CacheVerify, false)
+	ReportCacheLookup(ctx, CacheVerify, false)
+	ReportSingleflight(ctx, OperationResolve, true)
+	ReportVerifierDuration(ctx, 100*time.Millisecond, "verifier", "notation", false, true)
+	ReportCredentialFetch(ctx, 10*time.Millisecond, "registry.example.com", true)
+	ReportWarnDenial(ctx)
+	ReportShadowDisagreement(ctx)
+	ReportShadowDisagreement(ctx)
+
+	collected := collect(t, reader)
+	handler := attribute.String("handler", HandlerVerify)
+	if got := sum(t, collected[metricNameRequestItemCount], handler); got != 5 {
+		t.Errorf("expected 5 items, got %d", got)
+	}
+	requests, ok := collected[metricNameRequestDuration].(metricdata.Histogram[int64])
+	if !ok || len(requests.DataPoints) != 1 || requests.DataPoints[0].Count != 2 || requests.DataPoints[0].Sum != 60 {
+		t.Errorf("unexpected request duration: %+v", collected[metricNameRequestDuration])
+	}
+	if got := sum(t, collected[metricNameCacheCount], attribute.String("cache", CacheVerify), attribute.Bool("hit", false)); got != 2 {
+		t.Errorf("expected 2 cache misses, got %d", got)
+	}
+	if got := sum(t, collected[metricNameSingleflightCount], attribute.String("operation", OperationResolve), attribute.Bool("shared", true)); got != 1 {
+		t.Errorf("expected 1 shared operation, got %d", got)
+	}
+	if _, ok := collected[metricNameVerifierDuration].(metricdata.Histogram[int64]); !ok {
+		t.Errorf("expected verifier duration histogram, got %T", collected[metricNameVerifierDuration])
+	}
+	if _, ok := collected[metricNameCredentialFetchDuration].(metricdata.Histogram[int64]); !ok {
+		t.Errorf("expected credential fetch duration histogram, got %T", collected[metricNameCredentialFetchDuration])
+	}
+	if got := sum(t, collected[metricNameWarnDenialCount]); got != 1 {
+		t.Errorf("expected 1 warn denial, got %d", got)
+	}
+	if got := sum(t, collected[metricNameShadowDisagreementCount]); got != 2 {
+		t.Errorf("expected 2 shadow disagreements, got %d", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	reader := newTestReader(t)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+	registryURL, err := url.Parse(registry.URL)
+	if err != nil {
+		t.Fatalf("failed to parse registry URL: %v", err)
+	}
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Get(registry.URL + "/v2/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	collected := collect(t, reader)
+	if got := sum(t, collected[metricNameRegistryRequestCount], attribute.String("registry_host", registryURL.Host), attribute.Int("status_code", http.StatusUnauthorized)); got != 1 {
+		t.Errorf("expected 1 registry request, got %d", got)
+	}
+}
+
+func TestStartPrometheusExporter_InvalidPort(t *testing.T) {
+	for _, port := range []int{-1, 0, 65536} {
+		if err := StartPrometheusExporter(port); err == nil {
+			t.Errorf("expected error for port %d", port)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "net/http"
+
+// transport is an [http.RoundTripper] reporting the requests to registries.
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport returns an [http.RoundTripper] that reports every request sent
+// through the base round tripper by [ReportRegistryRequest]. If base is nil,
+// [http.DefaultTransport] is used.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+// RoundTrip sends the request and reports it.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	ReportRegistryRequest(req.Context(), req.URL.Host, statusCode)
+	return resp, err
+}
@@ -52,7 +52,7 @@ func Register(policyType string, create func(NewOptions) (ratify.PolicyEnforcer,
 }
 
 // New creates a new [ratify.PolicyEnforcer] instance based on the provided
-// options.
+// options. The policy evaluations of the returned enforcer are traced.
 func New(opts NewOptions) (ratify.PolicyEnforcer, error) {
 	if opts.Type == "" {
 		return nil, errors.New("policy type is not provided in the policy options")
@@ -61,5 +61,9 @@ func New(opts NewOptions) (ratify.PolicyEnforcer, error) {
 	if !ok {
 		return nil, fmt.Errorf("policy factory of type %s is not registered", opts.Type)
 	}
-	return create(opts)
+	enforcer, err := create(opts)
+	if err != nil || enforcer == nil {
+		return enforcer, err
+	}
+	return &tracedPolicyEnforcer{PolicyEnforcer: enforcer}, nil
 }
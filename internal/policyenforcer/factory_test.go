@@ -108,7 +108,7 @@ func TestNew(t *testing.T) {
 		{
 			name: "registered type",
 			opts: testOptions,
-			want: testPolicyEnforcer,
+			want: &tracedPolicyEnforcer{PolicyEnforcer: testPolicyEnforcer},
 		},
 	}
 	for _, tt := range tests {
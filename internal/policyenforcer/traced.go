@@ -0,0 +1,57 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyenforcer
+
+import (
+	"context"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+)
+
+// tracedPolicyEnforcer wraps a [ratify.PolicyEnforcer] so that the evaluation
+// of each subject is recorded as a span.
+type tracedPolicyEnforcer struct {
+	ratify.PolicyEnforcer
+}
+
+// Evaluator returns the evaluator of the wrapped policy enforcer.
+func (p *tracedPolicyEnforcer) Evaluator(ctx context.Context, subjectDigest string) (ratify.Evaluator, error) {
+	evaluator, err := p.PolicyEnforcer.Evaluator(ctx, subjectDigest)
+	if err != nil || evaluator == nil {
+		return evaluator, err
+	}
+	return &tracedEvaluator{
+		Evaluator:     evaluator,
+		subjectDigest: subjectDigest,
+	}, nil
+}
+
+// tracedEvaluator wraps a [ratify.Evaluator] and records the final evaluation
+// as a span.
+type tracedEvaluator struct {
+	ratify.Evaluator
+	subjectDigest string
+}
+
+// Evaluate evaluates the policy with the wrapped evaluator.
+func (e *tracedEvaluator) Evaluate(ctx context.Context) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, "policy.Evaluate", tracing.AttributeSubjectDigest.String(e.subjectDigest))
+	succeeded, err := e.Evaluator.Evaluate(ctx)
+	span.SetAttributes(tracing.AttributeSucceeded.Bool(succeeded))
+	tracing.EndSpan(span, err)
+	return succeeded, err
+}
@@ -0,0 +1,273 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// singleLevelSuffix is the suffix of a repository scope that matches any
+	// repository exactly one path segment below the prefix.
+	singleLevelSuffix = "/*"
+
+	// multiLevelSuffix is the suffix of a repository scope that matches any
+	// repository at any depth below the prefix.
+	multiLevelSuffix = "/**"
+)
+
+// Kind is the kind of a scope matched by a [Matcher].
+type Kind string
+
+const (
+	// KindRepository is the kind of a repository scope, including repository
+	// prefixes such as "registry.example.com/namespace/*".
+	KindRepository Kind = "repository"
+
+	// KindRegistry is the kind of a specific registry scope such as
+	// "registry.example.com".
+	KindRegistry Kind = "registry"
+
+	// KindWildcard is the kind of a wildcard registry scope such as
+	// "*.example.com".
+	KindWildcard Kind = "wildcard"
+)
+
+// Match describes the scope matched by a reference.
+type Match struct {
+	// Scope is the matched scope as registered.
+	Scope string `json:"scope"`
+
+	// Kind is the kind of the matched scope.
+	Kind Kind `json:"kind"`
+}
+
+// Matcher maps scopes to values and finds the value registered for the most
+// specific scope matching a given artifact reference.
+//
+// The matcher supports the following scope patterns:
+//   - Wildcard registries: "*.example.com" matches any subdomain of example.com
+//   - Specific registries: "registry.example.com" matches only that registry
+//   - Repository paths: "registry.example.com/namespace/repo" matches a
+//     specific repository
+//   - Single level repository prefixes: "registry.example.com/namespace/*"
+//     matches any repository directly under registry.example.com/namespace,
+//     e.g. "registry.example.com/namespace/repo"
+//   - Multi level repository prefixes: "registry.example.com/namespace/**"
+//     matches any repository under registry.example.com/namespace at any
+//     depth, e.g. "registry.example.com/namespace/team/repo"
+//
+// Note: Top level domain wildcard is not supported. That is, "*" is not a
+// valid pattern. Wildcards in repository scopes are only allowed as the last
+// path segment and cannot be combined with wildcard registries.
+//
+// Scope matching follows a precedence order from most specific to least
+// specific:
+//  1. Exact repository match
+//  2. Repository prefix match, where the longest prefix wins and a single
+//     level prefix wins over a multi level prefix of the same length
+//  3. Exact registry match
+//  4. Wildcard registry match
+//
+// The zero value is an empty matcher ready to use. A Matcher is not safe for
+// concurrent registration, but it is safe for concurrent matching once all
+// scopes are registered.
+type Matcher[T any] struct {
+	wildcard    map[string]T
+	registry    map[string]T
+	repository  map[string]T
+	singleLevel map[string]T
+	multiLevel  map[string]T
+}
+
+// Register registers a value for the given scope. It returns an error if the
+// scope is invalid or if a value is already registered for the scope.
+func (m *Matcher[T]) Register(scope string, value T) error {
+	if scope == "" {
+		return fmt.Errorf("scope cannot be empty")
+	}
+	if strings.Contains(scope, "/") {
+		return m.registerRepository(scope, value)
+	}
+	return m.registerRegistry(scope, value)
+}
+
+// Match returns the value registered for the most specific scope matching the
+// registry and repository of the given reference. The tag or digest of the
+// reference is ignored.
+func (m *Matcher[T]) Match(ref registry.Reference) (T, bool) {
+	value, _, ok := m.MatchScope(ref)
+	return value, ok
+}
+
+// MatchScope is like [Matcher.Match] but also describes the matched scope.
+func (m *Matcher[T]) MatchScope(ref registry.Reference) (T, Match, bool) {
+	repo := ref.Registry + "/" + ref.Repository
+	if value, ok := m.repository[repo]; ok {
+		return value, Match{Scope: repo, Kind: KindRepository}, true
+	}
+
+	// Walk up the repository path so that longer prefixes take precedence.
+	parent := repo
+	immediate := true
+	for {
+		idx := strings.LastIndex(parent, "/")
+		if idx < 0 {
+			break
+		}
+		parent = parent[:idx]
+		if immediate {
+			if value, ok := m.singleLevel[parent]; ok {
+				return value, Match{Scope: parent + singleLevelSuffix, Kind: KindRepository}, true
+			}
+			immediate = false
+		}
+		if value, ok := m.multiLevel[parent]; ok {
+			return value, Match{Scope: parent + multiLevelSuffix, Kind: KindRepository}, true
+		}
+	}
+
+	if value, ok := m.registry[ref.Registry]; ok {
+		return value, Match{Scope: ref.Registry, Kind: KindRegistry}, true
+	}
+
+	if _, after, ok := strings.Cut(ref.Registry, "."); ok {
+		if value, ok := m.wildcard[after]; ok {
+			return value, Match{Scope: "*." + after, Kind: KindWildcard}, true
+		}
+	}
+
+	var zero T
+	return zero, Match{}, false
+}
+
+// registerRepository registers a value for a repository scope. The scope must
+// be a valid repository path without tags or digests, optionally ending with a
+// "/*" or "/**" path segment.
+func (m *Matcher[T]) registerRepository(scope string, value T) error {
+	switch {
+	case strings.HasSuffix(scope, multiLevelSuffix):
+		prefix := strings.TrimSuffix(scope, multiLevelSuffix)
+		if err := validateRepositoryPrefix(scope, prefix); err != nil {
+			return err
+		}
+		if _, ok := m.multiLevel[prefix]; ok {
+			return fmt.Errorf("duplicate repository scope %q detected", scope)
+		}
+		if m.multiLevel == nil {
+			m.multiLevel = make(map[string]T)
+		}
+		m.multiLevel[prefix] = value
+	case strings.HasSuffix(scope, singleLevelSuffix):
+		prefix := strings.TrimSuffix(scope, singleLevelSuffix)
+		if err := validateRepositoryPrefix(scope, prefix); err != nil {
+			return err
+		}
+		if _, ok := m.singleLevel[prefix]; ok {
+			return fmt.Errorf("duplicate repository scope %q detected", scope)
+		}
+		if m.singleLevel == nil {
+			m.singleLevel = make(map[string]T)
+		}
+		m.singleLevel[prefix] = value
+	default:
+		if strings.Contains(scope, "*") {
+			return fmt.Errorf("invalid scope %q: wildcard is only supported as the last path segment of a repository scope", scope)
+		}
+		ref, err := registry.ParseReference(scope)
+		if err != nil {
+			return fmt.Errorf("invalid scope %q: %w", scope, err)
+		}
+		if ref.Reference != "" {
+			return fmt.Errorf("invalid scope %q: scope cannot contain a tag or digest", scope)
+		}
+		if _, ok := m.repository[scope]; ok {
+			return fmt.Errorf("duplicate repository scope %q detected", scope)
+		}
+		if m.repository == nil {
+			m.repository = make(map[string]T)
+		}
+		m.repository[scope] = value
+	}
+	return nil
+}
+
+// registerRegistry registers a value for a registry scope. The scope can be a
+// specific registry (e.g., "registry.example.com") or a wildcard registry
+// (e.g., "*.example.com").
+func (m *Matcher[T]) registerRegistry(scope string, value T) error {
+	ref := registry.Reference{
+		Registry: scope,
+	}
+	if err := ref.ValidateRegistry(); err != nil {
+		return fmt.Errorf("invalid scope %q: %w", scope, err)
+	}
+
+	switch strings.Count(scope, "*") {
+	case 0:
+		if _, ok := m.registry[scope]; ok {
+			return fmt.Errorf("duplicate registry scope %q detected", scope)
+		}
+		if m.registry == nil {
+			m.registry = make(map[string]T)
+		}
+		m.registry[scope] = value
+	case 1:
+		if !strings.HasPrefix(scope, "*.") {
+			return fmt.Errorf("invalid scope %q: wildcard must be at the beginning of the scope", scope)
+		}
+		scope = scope[2:] // Remove "*." prefix
+		if _, ok := m.wildcard[scope]; ok {
+			return fmt.Errorf("duplicate wildcard scope %q detected", scope)
+		}
+		if m.wildcard == nil {
+			m.wildcard = make(map[string]T)
+		}
+		m.wildcard[scope] = value
+	default:
+		return fmt.Errorf("invalid scope %q: scope can only contain one wildcard", scope)
+	}
+	return nil
+}
+
+// validateRepositoryPrefix validates the prefix of a repository scope ending
+// with a wildcard path segment. The prefix is either a registry or a
+// repository path, and must not contain any wildcard.
+func validateRepositoryPrefix(scope, prefix string) error {
+	if strings.Contains(prefix, "*") {
+		return fmt.Errorf("invalid scope %q: wildcard is only supported as the last path segment of a repository scope", scope)
+	}
+	if !strings.Contains(prefix, "/") {
+		ref := registry.Reference{
+			Registry: prefix,
+		}
+		if err := ref.ValidateRegistry(); err != nil {
+			return fmt.Errorf("invalid scope %q: %w", scope, err)
+		}
+		return nil
+	}
+	ref, err := registry.ParseReference(prefix)
+	if err != nil {
+		return fmt.Errorf("invalid scope %q: %w", scope, err)
+	}
+	if ref.Reference != "" {
+		return fmt.Errorf("invalid scope %q: scope cannot contain a tag or digest", scope)
+	}
+	return nil
+}
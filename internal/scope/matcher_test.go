@@ -0,0 +1,294 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name        string
+		scope       string
+		errContains string
+	}{
+		{
+			name:        "empty scope",
+			scope:       "",
+			errContains: "scope cannot be empty",
+		},
+		{
+			name:        "global wildcard",
+			scope:       "*",
+			errContains: "wildcard must be at the beginning of the scope",
+		},
+		{
+			name:        "invalid registry",
+			scope:       ":invalid",
+			errContains: "invalid scope",
+		},
+		{
+			name:        "wildcard in the middle of registry",
+			scope:       "example.*.com",
+			errContains: "wildcard must be at the beginning of the scope",
+		},
+		{
+			name:        "multiple wildcards in registry",
+			scope:       "*.*.example.com",
+			errContains: "scope can only contain one wildcard",
+		},
+		{
+			name:        "repository with tag",
+			scope:       "registry.example.com/repo:latest",
+			errContains: "scope cannot contain a tag or digest",
+		},
+		{
+			name:        "repository with digest",
+			scope:       "registry.example.com/repo@sha256:a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2",
+			errContains: "scope cannot contain a tag or digest",
+		},
+		{
+			name:        "partial wildcard segment",
+			scope:       "registry.example.com/repo*",
+			errContains: "wildcard is only supported as the last path segment",
+		},
+		{
+			name:        "wildcard in the middle of repository",
+			scope:       "registry.example.com/*/repo",
+			errContains: "wildcard is only supported as the last path segment",
+		},
+		{
+			name:        "multiple wildcard segments",
+			scope:       "registry.example.com/*/**",
+			errContains: "wildcard is only supported as the last path segment",
+		},
+		{
+			name:        "wildcard registry with repository prefix",
+			scope:       "*.example.com/team/*",
+			errContains: "wildcard is only supported as the last path segment",
+		},
+		{
+			name:        "prefix without registry",
+			scope:       "/**",
+			errContains: "invalid scope",
+		},
+		{
+			name:        "prefix with invalid repository",
+			scope:       "registry.example.com/Team/*",
+			errContains: "invalid scope",
+		},
+		{
+			name:  "registry",
+			scope: "registry.example.com",
+		},
+		{
+			name:  "registry with port",
+			scope: "localhost:5000",
+		},
+		{
+			name:  "wildcard registry",
+			scope: "*.example.com",
+		},
+		{
+			name:  "repository",
+			scope: "registry.example.com/team/repo",
+		},
+		{
+			name:  "single level prefix",
+			scope: "registry.example.com/team/*",
+		},
+		{
+			name:  "multi level prefix",
+			scope: "registry.example.com/team/**",
+		},
+		{
+			name:  "registry level prefix",
+			scope: "registry.example.com/**",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Matcher[int]
+			err := m.Register(tt.scope, 1)
+			if tt.errContains == "" {
+				if err != nil {
+					t.Fatalf("Register() unexpected error = %v", err)
+				}
+				if err := m.Register(tt.scope, 2); err == nil || !strings.Contains(err.Error(), "duplicate") {
+					t.Errorf("Register() expected duplicate error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Register() error = %v, want error containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var m Matcher[string]
+	for _, scope := range []string{
+		"*.example.com",
+		"registry.example.com",
+		"registry.example.com/team/repo",
+		"registry.example.com/team/*",
+		"registry.example.com/team/**",
+		"registry.example.com/team/group/**",
+		"other.example.com/**",
+	} {
+		if err := m.Register(scope, scope); err != nil {
+			t.Fatalf("failed to register scope %q: %v", scope, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+	}{
+		{
+			name:      "exact repository",
+			reference: "registry.example.com/team/repo:v1",
+			want:      "registry.example.com/team/repo",
+		},
+		{
+			name:      "single level prefix wins over multi level prefix",
+			reference: "registry.example.com/team/app:v1",
+			want:      "registry.example.com/team/*",
+		},
+		{
+			name:      "multi level prefix",
+			reference: "registry.example.com/team/sub/app:v1",
+			want:      "registry.example.com/team/**",
+		},
+		{
+			name:      "longest multi level prefix",
+			reference: "registry.example.com/team/group/sub/app:v1",
+			want:      "registry.example.com/team/group/**",
+		},
+		{
+			name:      "longest prefix wins over single level prefix of ancestor",
+			reference: "registry.example.com/team/group/app:v1",
+			want:      "registry.example.com/team/group/**",
+		},
+		{
+			name:      "prefix does not match the prefix repository itself",
+			reference: "registry.example.com/team:v1",
+			want:      "registry.example.com",
+		},
+		{
+			name:      "prefix does not match sibling with common string prefix",
+			reference: "registry.example.com/teams/app:v1",
+			want:      "registry.example.com",
+		},
+		{
+			name:      "registry level prefix wins over wildcard registry",
+			reference: "other.example.com/app:v1",
+			want:      "other.example.com/**",
+		},
+		{
+			name:      "wildcard registry",
+			reference: "foo.example.com/app:v1",
+			want:      "*.example.com",
+		},
+		{
+			name:      "no match",
+			reference: "unknown.com/app:v1",
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := registry.ParseReference(tt.reference)
+			if err != nil {
+				t.Fatalf("failed to parse reference %q: %v", tt.reference, err)
+			}
+			got, ok := m.Match(ref)
+			if ok != (tt.want != "") {
+				t.Fatalf("Match() ok = %v, want %v", ok, tt.want != "")
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchScope(t *testing.T) {
+	var m Matcher[int]
+	for _, scope := range []string{
+		"*.example.com",
+		"registry.example.com",
+		"registry.example.com/team/repo",
+		"registry.example.com/team/*",
+		"registry.example.com/group/**",
+	} {
+		if err := m.Register(scope, 1); err != nil {
+			t.Fatalf("failed to register scope %q: %v", scope, err)
+		}
+	}
+
+	tests := []struct {
+		reference string
+		want      Match
+	}{
+		{
+			reference: "registry.example.com/team/repo:v1",
+			want:      Match{Scope: "registry.example.com/team/repo", Kind: KindRepository},
+		},
+		{
+			reference: "registry.example.com/team/app:v1",
+			want:      Match{Scope: "registry.example.com/team/*", Kind: KindRepository},
+		},
+		{
+			reference: "registry.example.com/group/sub/app:v1",
+			want:      Match{Scope: "registry.example.com/group/**", Kind: KindRepository},
+		},
+		{
+			reference: "registry.example.com/app:v1",
+			want:      Match{Scope: "registry.example.com", Kind: KindRegistry},
+		},
+		{
+			reference: "foo.example.com/app:v1",
+			want:      Match{Scope: "*.example.com", Kind: KindWildcard},
+		},
+		{
+			reference: "unknown.com/app:v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			ref, err := registry.ParseReference(tt.reference)
+			if err != nil {
+				t.Fatalf("failed to parse reference %q: %v", tt.reference, err)
+			}
+			_, got, ok := m.MatchScope(ref)
+			if ok != (tt.want != Match{}) {
+				t.Fatalf("MatchScope() ok = %v, want %v", ok, tt.want != Match{})
+			}
+			if got != tt.want {
+				t.Errorf("MatchScope() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -88,6 +88,12 @@ func createAzureIdentityProvider(opts credentialprovider.Options) (ratify.Regist
 	return credentialprovider.NewCachedProvider(azureProvider)
 }
 
+// CacheScope implements credentialprovider.ScopedSourceProvider interface.
+// Credentials are scoped by the identity they are retrieved with.
+func (p *IdentityProvider) CacheScope() string {
+	return "azure/" + p.tenantID + "/" + p.clientID
+}
+
 // GetWithTTL implements credentialprovider.CredentialSourceProvider interface.
 // It retrieves the registry credentials from Azure with TTL information.
 func (p *IdentityProvider) GetWithTTL(ctx context.Context, serverAddress string) (credentialprovider.CredentialWithTTL, error) {
@@ -17,11 +17,13 @@ package credentialprovider
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/cache"
-	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
+	"github.com/notaryproject/ratify/v2/internal/cache/factory"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
 )
 
 // CredentialWithTTL represents a credential response with its expiration time.
@@ -37,23 +39,68 @@ type CredentialSourceProvider interface {
 	GetWithTTL(ctx context.Context, serverAddress string) (CredentialWithTTL, error)
 }
 
+// ScopedSourceProvider is implemented by the CredentialSourceProviders whose
+// credentials depend on their configuration, e.g. the identity they
+// authenticate with. The cached credentials are keyed by the scope, so that
+// providers configured differently do not share credentials through a shared
+// cache.
+type ScopedSourceProvider interface {
+	CredentialSourceProvider
+
+	// CacheScope returns the scope of the credentials of the provider.
+	CacheScope() string
+}
+
+// credentialCacheName is the name of the cache of the credentials.
+const credentialCacheName = "credential"
+
+var (
+	cacheOptionsMu sync.RWMutex
+	cacheOptions   = factory.Options{
+		Type:    factory.TypeInMemory,
+		Name:    credentialCacheName,
+		MaxCost: 10,
+	}
+)
+
+// SetCacheOptions configures the cache of the credential providers created
+// afterwards. The credentials are kept in an in-memory cache of 10 entries per
+// provider by default.
+func SetCacheOptions(opts factory.Options) {
+	if opts.Name == "" {
+		opts.Name = credentialCacheName
+	}
+	cacheOptionsMu.Lock()
+	defer cacheOptionsMu.Unlock()
+	cacheOptions = opts
+}
+
 // CachedProvider wraps a CredentialSourceProvider and provides caching functionality.
 // It implements the ratify.RegistryCredentialGetter interface.
 type CachedProvider struct {
 	source CredentialSourceProvider
 	cache  cache.Cache[ratify.RegistryCredential]
+	scope  string
 }
 
 // NewCachedProvider creates a new cached credential provider that wraps the given source provider.
 func NewCachedProvider(source CredentialSourceProvider) (*CachedProvider, error) {
-	cache, err := inmemory.NewCache[ratify.RegistryCredential](10)
+	cacheOptionsMu.RLock()
+	opts := cacheOptions
+	cacheOptionsMu.RUnlock()
+	cache, err := factory.NewCache[ratify.RegistryCredential](opts)
 	if err != nil {
 		return nil, err
 	}
 
+	var scope string
+	if scoped, ok := source.(ScopedSourceProvider); ok {
+		scope = scoped.CacheScope() + "/"
+	}
 	return &CachedProvider{
 		source: source,
 		cache:  cache,
+		scope:  scope,
 	}, nil
 }
 
@@ -62,19 +109,22 @@ func NewCachedProvider(source CredentialSourceProvider) (*CachedProvider, error)
 // new credentials from the source provider and caches them.
 func (c *CachedProvider) Get(ctx context.Context, serverAddress string) (ratify.RegistryCredential, error) {
 	// Check if we have a cached credential
-	if credential, err := c.cache.Get(ctx, serverAddress); err == nil {
+	key := c.scope + serverAddress
+	if credential, err := c.cache.Get(ctx, key); err == nil {
 		return credential, nil
 	}
 
 	// Cache miss, fetch new credentials
+	start := time.Now()
 	credWithTTL, err := c.source.GetWithTTL(ctx, serverAddress)
+	metrics.ReportCredentialFetch(ctx, time.Since(start), serverAddress, err == nil)
 	if err != nil {
 		return ratify.RegistryCredential{}, err
 	}
 
 	if credWithTTL.TTL > 0 {
 		defer func() {
-			_ = c.cache.Set(ctx, serverAddress, credWithTTL.Credential, credWithTTL.TTL)
+			_ = c.cache.Set(ctx, key, credWithTTL.Credential, credWithTTL.TTL)
 		}()
 	}
 	return credWithTTL.Credential, nil
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache/factory"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
 )
 
 const testServerAddress = "registry.example.com"
@@ -346,3 +348,49 @@ func TestCachedProvider_Interface_Compliance(t *testing.T) {
 	// Verify that CachedProvider implements ratify.RegistryCredentialGetter interface
 	var _ ratify.RegistryCredentialGetter = provider
 }
+
+// scopedCredentialSourceProvider is a mock implementation of ScopedSourceProvider
+type scopedCredentialSourceProvider struct {
+	*mockCredentialSourceProvider
+	scope string
+}
+
+func (m *scopedCredentialSourceProvider) CacheScope() string {
+	return m.scope
+}
+
+func TestCachedProvider_Get_Scoped(t *testing.T) {
+	mockSource := &scopedCredentialSourceProvider{mockCredentialSourceProvider: newMockCredentialSourceProvider(), scope: "tenant"}
+	provider, err := NewCachedProvider(mockSource)
+	if err != nil {
+		t.Fatalf("Failed to create cached provider: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := provider.Get(ctx, testServerAddress); err != nil {
+		t.Fatalf("Failed to get credential: %v", err)
+	}
+	if _, err := provider.cache.Get(ctx, "tenant/"+testServerAddress); err != nil {
+		t.Errorf("Expected credential to be cached under the scoped key, got %v", err)
+	}
+}
+
+func TestSetCacheOptions(t *testing.T) {
+	t.Cleanup(func() {
+		SetCacheOptions(factory.Options{Type: factory.TypeInMemory, MaxCost: 10})
+	})
+
+	SetCacheOptions(factory.Options{Type: factory.TypeRistretto})
+	provider, err := NewCachedProvider(newMockCredentialSourceProvider())
+	if err != nil {
+		t.Fatalf("Failed to create cached provider: %v", err)
+	}
+	if _, ok := provider.cache.(*ristretto.Cache[ratify.RegistryCredential]); !ok {
+		t.Errorf("Expected a ristretto cache, got %T", provider.cache)
+	}
+
+	SetCacheOptions(factory.Options{Type: "unknown"})
+	if _, err := NewCachedProvider(newMockCredentialSourceProvider()); err == nil {
+		t.Error("Expected error for an unsupported cache type")
+	}
+}
@@ -50,7 +50,8 @@ func RegisterCredentialProviderFactory(providerType string, create func(Options)
 }
 
 // NewCredentialProvider creates a new credential provider from
-// CredentialProviderOptions.
+// CredentialProviderOptions. The credential lookups of the returned provider
+// are traced.
 func NewCredentialProvider(opts Options) (ratify.RegistryCredentialGetter, error) {
 	if opts == nil {
 		return nil, fmt.Errorf("credential provider options cannot be nil")
@@ -71,5 +72,12 @@ func NewCredentialProvider(opts Options) (ratify.RegistryCredentialGetter, error
 	if !ok {
 		return nil, fmt.Errorf("credential provider factory of type %s is not registered", providerTypeStr)
 	}
-	return create(opts)
+	provider, err := create(opts)
+	if err != nil || provider == nil {
+		return provider, err
+	}
+	return &tracedProvider{
+		RegistryCredentialGetter: provider,
+		providerType:             providerTypeStr,
+	}, nil
 }
@@ -0,0 +1,41 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentialprovider
+
+import (
+	"context"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+)
+
+// tracedProvider wraps a [ratify.RegistryCredentialGetter] and records every
+// credential lookup as a span.
+type tracedProvider struct {
+	ratify.RegistryCredentialGetter
+	providerType string
+}
+
+// Get returns the credential for the server address from the wrapped provider.
+func (p *tracedProvider) Get(ctx context.Context, serverAddress string) (ratify.RegistryCredential, error) {
+	ctx, span := tracing.StartSpan(ctx, "credential.Get",
+		tracing.AttributeProvider.String(p.providerType),
+		tracing.AttributeRegistry.String(serverAddress),
+	)
+	credential, err := p.RegistryCredentialGetter.Get(ctx, serverAddress)
+	tracing.EndSpan(span, err)
+	return credential, err
+}
@@ -0,0 +1,372 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/notaryproject/ratify/v2/internal/logger"
+)
+
+const (
+	// defaultDiskCacheMaxSizeBytes is the default maximum total size of the
+	// content cached on disk, which is 1 GiB.
+	defaultDiskCacheMaxSizeBytes = 1 << 30
+
+	// diskCacheBlobsDir is the directory of the cached content under the
+	// cache path, laid out as blobs/<algorithm>/<encoded digest>.
+	diskCacheBlobsDir = "blobs"
+
+	// diskCacheTempPattern is the pattern of the files being written, which
+	// are renamed once complete.
+	diskCacheTempPattern = ".tmp-*"
+)
+
+// DiskCacheOptions configures the cache of the manifests and blobs fetched by
+// a store on local disk. Manifests and blobs are content-addressed and
+// immutable, so that they are cached by digest and shared by the stores
+// caching to the same path, and kept across restarts.
+type DiskCacheOptions struct {
+	// Path is the directory the content is cached in. Required.
+	Path string `json:"path"`
+
+	// MaxSizeBytes is the maximum total size of the cached content. The
+	// least recently used content is evicted once the cache grows larger.
+	// The stores caching to the same path share the maximum size last
+	// configured. Default is 1 GiB if not specified. Optional.
+	MaxSizeBytes int64 `json:"maxSizeBytes,omitempty"`
+}
+
+// diskCachedStore is a [ratify.Store] fetching manifests and blobs from the
+// disk cache if cached, and from the wrapped store otherwise. Resolving
+// references and listing referrers always go to the wrapped store as tags and
+// referrers are mutable.
+type diskCachedStore struct {
+	ratify.Store
+	cache *diskCache
+}
+
+// newDiskCachedStore wraps the store with the disk cache configured by the
+// options.
+func newDiskCachedStore(store ratify.Store, opts DiskCacheOptions) (ratify.Store, error) {
+	cache, err := openDiskCache(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disk cache: %w", err)
+	}
+	return &diskCachedStore{
+		Store: store,
+		cache: cache,
+	}, nil
+}
+
+// FetchBlob returns the blob by the given reference.
+func (s *diskCachedStore) FetchBlob(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.cache.fetch(ctx, desc, func() ([]byte, error) {
+		return s.Store.FetchBlob(ctx, repo, desc)
+	})
+}
+
+// FetchManifest returns the referenced manifest as given by the descriptor.
+func (s *diskCachedStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.cache.fetch(ctx, desc, func() ([]byte, error) {
+		return s.Store.FetchManifest(ctx, repo, desc)
+	})
+}
+
+var (
+	// diskCaches holds the disk caches opened by path, so that the stores
+	// recreated on configuration changes share the index of their cache.
+	diskCaches   = make(map[string]*diskCache)
+	diskCachesMu sync.Mutex
+)
+
+// diskCache is a content-addressed cache on local disk evicting the least
+// recently used content beyond its maximum size. The recency of the content
+// is kept in the modification time of the files to survive restarts.
+type diskCache struct {
+	root string
+
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	// lru holds the *diskCacheEntry of the cached content, the most recently
+	// used first.
+	lru     *list.List
+	entries map[digest.Digest]*list.Element
+}
+
+// diskCacheEntry is the index entry of a cached file.
+type diskCacheEntry struct {
+	digest digest.Digest
+	size   int64
+}
+
+// openDiskCache returns the disk cache of the path, loading its index from
+// disk on first use. The maximum size of a cache already open is updated to
+// the options, with a warning as the stores sharing the path are then bound by
+// the maximum size last configured.
+func openDiskCache(opts DiskCacheOptions) (*diskCache, error) {
+	if opts.Path == "" {
+		return nil, errors.New("disk cache path is required")
+	}
+	if opts.MaxSizeBytes < 0 {
+		return nil, fmt.Errorf("invalid disk cache max size %d", opts.MaxSizeBytes)
+	}
+	maxSize := opts.MaxSizeBytes
+	if maxSize == 0 {
+		maxSize = defaultDiskCacheMaxSizeBytes
+	}
+	root, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	diskCachesMu.Lock()
+	defer diskCachesMu.Unlock()
+	if cache, ok := diskCaches[root]; ok {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		if cache.maxSize != maxSize {
+			logger.GetLogger(context.Background(), logOpt).Warnf("disk cache %s is configured with a maximum size of %d bytes, replacing the maximum size of %d bytes of the stores sharing it", root, maxSize, cache.maxSize)
+		}
+		cache.maxSize = maxSize
+		cache.evict()
+		return cache, nil
+	}
+	cache := &diskCache{
+		root:    root,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: make(map[digest.Digest]*list.Element),
+	}
+	if err := cache.load(); err != nil {
+		return nil, err
+	}
+	diskCaches[root] = cache
+	return cache, nil
+}
+
+// load indexes the content cached on disk by recency and removes the files
+// left incomplete.
+func (c *diskCache) load() error {
+	blobsDir := filepath.Join(c.root, diskCacheBlobsDir)
+	if err := os.MkdirAll(blobsDir, 0o700); err != nil {
+		return err
+	}
+	type cachedFile struct {
+		entry   *diskCacheEntry
+		modTime time.Time
+	}
+	var files []cachedFile
+	err := filepath.WalkDir(blobsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if matched, _ := filepath.Match(diskCacheTempPattern, d.Name()); matched {
+			return os.Remove(path)
+		}
+		rel, err := filepath.Rel(blobsDir, path)
+		if err != nil {
+			return err
+		}
+		algorithm, encoded, ok := strings.Cut(filepath.ToSlash(rel), "/")
+		if !ok {
+			return nil
+		}
+		dgst := digest.NewDigestFromEncoded(digest.Algorithm(algorithm), encoded)
+		if dgst.Validate() != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, cachedFile{
+			entry:   &diskCacheEntry{digest: dgst, size: info.Size()},
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	for _, file := range files {
+		c.entries[file.entry.digest] = c.lru.PushBack(file.entry)
+		c.size += file.entry.size
+	}
+	c.evict()
+	return nil
+}
+
+// fetch returns the content of the descriptor from the cache if cached and
+// intact, and from fetchContent otherwise. Fetched content is cached if it
+// matches the digest of the descriptor.
+func (c *diskCache) fetch(ctx context.Context, desc ocispec.Descriptor, fetchContent func() ([]byte, error)) ([]byte, error) {
+	if desc.Digest.Validate() != nil {
+		return fetchContent()
+	}
+	if content, ok := c.get(ctx, desc.Digest); ok {
+		return content, nil
+	}
+	content, err := fetchContent()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.put(desc.Digest, content); err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to cache %s on disk: %v", desc.Digest, err)
+	}
+	return content, nil
+}
+
+// get returns the cached content of the digest. Content not matching its
+// digest, e.g. corrupted on disk, is removed from the cache.
+func (c *diskCache) get(ctx context.Context, dgst digest.Digest) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[dgst]
+	if ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	path := c.path(dgst)
+	content, err := os.ReadFile(path)
+	if err == nil && dgst.Algorithm().FromBytes(content) == dgst {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return content, true
+	}
+	if err == nil {
+		logger.GetLogger(ctx, logOpt).Warnf("removing cached content not matching digest %s from disk cache", dgst)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		logger.GetLogger(ctx, logOpt).Warnf("failed to read %s from disk cache: %v", dgst, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(dgst)
+	return nil, false
+}
+
+// put caches the content of the digest, evicting the least recently used
+// content if the cache grows beyond its maximum size. Content larger than the
+// maximum size or not matching the digest is not cached.
+func (c *diskCache) put(dgst digest.Digest, content []byte) error {
+	if dgst.Algorithm().FromBytes(content) != dgst {
+		return fmt.Errorf("content does not match digest %s", dgst)
+	}
+	c.mu.Lock()
+	tooLarge := int64(len(content)) > c.maxSize
+	_, cached := c.entries[dgst]
+	c.mu.Unlock()
+	if tooLarge || cached {
+		return nil
+	}
+
+	path := c.path(dgst)
+	if err := writeFileAtomic(path, content); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[dgst]; ok {
+		return nil
+	}
+	entry := &diskCacheEntry{digest: dgst, size: int64(len(content))}
+	c.entries[dgst] = c.lru.PushFront(entry)
+	c.size += entry.size
+	c.evict()
+	return nil
+}
+
+// evict removes the least recently used content until the cache fits its
+// maximum size. The caller must hold c.mu.
+func (c *diskCache) evict() {
+	for c.size > c.maxSize {
+		elem := c.lru.Back()
+		if elem == nil {
+			return
+		}
+		c.remove(elem.Value.(*diskCacheEntry).digest)
+	}
+}
+
+// remove removes the content of the digest from the index and the disk. The
+// caller must hold c.mu.
+func (c *diskCache) remove(dgst digest.Digest) {
+	elem, ok := c.entries[dgst]
+	if !ok {
+		return
+	}
+	c.lru.Remove(elem)
+	delete(c.entries, dgst)
+	c.size -= elem.Value.(*diskCacheEntry).size
+	if err := os.Remove(c.path(dgst)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.GetLogger(context.Background(), logOpt).Warnf("failed to remove %s from disk cache: %v", dgst, err)
+	}
+}
+
+// path returns the path of the cached content of the digest, which must be
+// valid.
+func (c *diskCache) path(dgst digest.Digest) string {
+	return filepath.Join(c.root, diskCacheBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+}
+
+// writeFileAtomic writes the file through a temporary file renamed once
+// complete, so that no partially written file is read.
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, diskCacheTempPattern)
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+const testRepo = "registry.example.com/test"
+
+// contentStore is a store serving content by digest and counting fetches.
+type contentStore struct {
+	mockStore
+	mu       sync.Mutex
+	contents map[digest.Digest][]byte
+	fetches  int
+}
+
+func newContentStore(contents ...string) (*contentStore, []ocispec.Descriptor) {
+	s := &contentStore{contents: make(map[digest.Digest][]byte)}
+	var descs []ocispec.Descriptor
+	for _, content := range contents {
+		dgst := digest.FromString(content)
+		s.contents[dgst] = []byte(content)
+		descs = append(descs, ocispec.Descriptor{Digest: dgst, Size: int64(len(content))})
+	}
+	return s, descs
+}
+
+func (s *contentStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	content, ok := s.contents[desc.Digest]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return content, nil
+}
+
+func (s *contentStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.FetchBlob(ctx, repo, desc)
+}
+
+func (s *contentStore) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func newTestDiskCachedStore(t *testing.T, store ratify.Store, opts DiskCacheOptions) ratify.Store {
+	t.Helper()
+	cached, err := newDiskCachedStore(store, opts)
+	if err != nil {
+		t.Fatalf("failed to create disk cached store: %v", err)
+	}
+	return cached
+}
+
+// forgetDiskCache drops the index of the disk cache of the path as if the
+// process restarted.
+func forgetDiskCache(t *testing.T, path string) {
+	t.Helper()
+	root, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatalf("failed to get absolute path: %v", err)
+	}
+	diskCachesMu.Lock()
+	defer diskCachesMu.Unlock()
+	delete(diskCaches, root)
+}
+
+func TestNewDiskCachedStore(t *testing.T) {
+	if _, err := newDiskCachedStore(&mockStore{}, DiskCacheOptions{}); err == nil {
+		t.Errorf("expected error for missing path")
+	}
+	if _, err := newDiskCachedStore(&mockStore{}, DiskCacheOptions{Path: t.TempDir(), MaxSizeBytes: -1}); err == nil {
+		t.Errorf("expected error for negative max size")
+	}
+
+	Register(testType, func(NewOptions) (ratify.Store, error) {
+		return &mockStore{}, nil
+	})
+	defer delete(registry, testType)
+	store, err := newStore(NewOptions{Type: testType, DiskCache: &DiskCacheOptions{Path: t.TempDir()}})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, ok := store.(*diskCachedStore); !ok {
+		t.Errorf("expected a disk cached store, got %T", store)
+	}
+}
+
+func TestDiskCachedStore_Fetch(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	source, descs := newContentStore("manifest", "blob")
+	store := newTestDiskCachedStore(t, source, DiskCacheOptions{Path: path})
+
+	for i := 0; i < 2; i++ {
+		if manifest, err := store.FetchManifest(ctx, testRepo, descs[0]); err != nil || string(manifest) != "manifest" {
+			t.Fatalf("unexpected manifest: %q, %v", manifest, err)
+		}
+		if blob, err := store.FetchBlob(ctx, testRepo, descs[1]); err != nil || string(blob) != "blob" {
+			t.Fatalf("unexpected blob: %q, %v", blob, err)
+		}
+	}
+	if fetches := source.fetchCount(); fetches != 2 {
+		t.Errorf("expected cached content to be fetched once, got %d fetches", fetches)
+	}
+
+	// The cache is kept across restarts.
+	forgetDiskCache(t, path)
+	store = newTestDiskCachedStore(t, source, DiskCacheOptions{Path: path})
+	if blob, err := store.FetchBlob(ctx, "registry.example.com/other", descs[1]); err != nil || string(blob) != "blob" {
+		t.Fatalf("unexpected blob: %q, %v", blob, err)
+	}
+	if fetches := source.fetchCount(); fetches != 2 {
+		t.Errorf("expected content to be served from disk after restart, got %d fetches", fetches)
+	}
+
+	// Errors are not cached.
+	missing := ocispec.Descriptor{Digest: digest.FromString("missing")}
+	if _, err := store.FetchBlob(ctx, testRepo, missing); err == nil {
+		t.Errorf("expected error for missing blob")
+	}
+}
+
+func TestDiskCachedStore_Corrupted(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	source, descs := newContentStore("blob")
+	store := newTestDiskCachedStore(t, source, DiskCacheOptions{Path: path})
+
+	if _, err := store.FetchBlob(ctx, testRepo, descs[0]); err != nil {
+		t.Fatalf("failed to fetch blob: %v", err)
+	}
+	file := filepath.Join(path, diskCacheBlobsDir, descs[0].Digest.Algorithm().String(), descs[0].Digest.Encoded())
+	if err := os.WriteFile(file, []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("failed to tamper with the cached blob: %v", err)
+	}
+	if blob, err := store.FetchBlob(ctx, testRepo, descs[0]); err != nil || string(blob) != "blob" {
+		t.Fatalf("expected the blob to be refetched, got %q, %v", blob, err)
+	}
+	if fetches := source.fetchCount(); fetches != 2 {
+		t.Errorf("expected corrupted content to be refetched, got %d fetches", fetches)
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != "blob" {
+		t.Errorf("expected the cached blob to be restored, got %q, %v", content, err)
+	}
+}
+
+func TestDiskCachedStore_MismatchedContent(t *testing.T) {
+	ctx := context.Background()
+	source, descs := newContentStore("blob")
+	// The source serves content not matching the descriptor.
+	source.contents[descs[0].Digest] = []byte("other")
+	store := newTestDiskCachedStore(t, source, DiskCacheOptions{Path: t.TempDir()})
+
+	for i := 0; i < 2; i++ {
+		if _, err := store.FetchBlob(ctx, testRepo, descs[0]); err != nil {
+			t.Fatalf("failed to fetch blob: %v", err)
+		}
+	}
+	if fetches := source.fetchCount(); fetches != 2 {
+		t.Errorf("expected mismatched content not to be cached, got %d fetches", fetches)
+	}
+}
+
+func TestDiskCachedStore_Eviction(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	source, descs := newContentStore("aaaa", "bbbb", "cccc", "too large content")
+	store := newTestDiskCachedStore(t, source, DiskCacheOptions{Path: path, MaxSizeBytes: 10})
+
+	fetch := func(desc ocispec.Descriptor) {
+		t.Helper()
+		if _, err := store.FetchBlob(ctx, testRepo, desc); err != nil {
+			t.Fatalf("failed to fetch blob: %v", err)
+		}
+	}
+	fetch(descs[0])
+	fetch(descs[1])
+	fetch(descs[0]) // cached, aaaa is now the most recently used
+	fetch(descs[2]) // evicts bbbb
+	if fetches := source.fetchCount(); fetches != 3 {
+		t.Fatalf("expected 3 fetches, got %d", fetches)
+	}
+	fetch(descs[0])
+	fetch(descs[2])
+	if fetches := source.fetchCount(); fetches != 3 {
+		t.Errorf("expected the recently used content to be kept, got %d fetches", fetches)
+	}
+	fetch(descs[1])
+	if fetches := source.fetchCount(); fetches != 4 {
+		t.Errorf("expected the least recently used content to be evicted, got %d fetches", fetches)
+	}
+
+	fetch(descs[3])
+	fetch(descs[3])
+	if fetches := source.fetchCount(); fetches != 6 {
+		t.Errorf("expected content larger than the cache not to be cached, got %d fetches", fetches)
+	}
+
+	// Shrinking the cache evicts content on reopening.
+	store = newTestDiskCachedStore(t, source, DiskCacheOptions{Path: path, MaxSizeBytes: 4})
+	cache := store.(*diskCachedStore).cache
+	cache.mu.Lock()
+	size, entries := cache.size, len(cache.entries)
+	cache.mu.Unlock()
+	if size != 4 || entries != 1 {
+		t.Errorf("expected a single entry of 4 bytes, got %d entries of %d bytes", entries, size)
+	}
+}
+
+func TestOpenDiskCache_SharedPath(t *testing.T) {
+	hook := logtest.NewLocal(logrus.StandardLogger())
+	defer hook.Reset()
+	path := t.TempDir()
+
+	first, err := openDiskCache(DiskCacheOptions{Path: path, MaxSizeBytes: 100})
+	if err != nil {
+		t.Fatalf("failed to open disk cache: %v", err)
+	}
+	second, err := openDiskCache(DiskCacheOptions{Path: path, MaxSizeBytes: 100})
+	if err != nil {
+		t.Fatalf("failed to open disk cache: %v", err)
+	}
+	if first != second {
+		t.Fatalf("expected the stores caching to the same path to share the cache")
+	}
+	if len(hook.AllEntries()) != 0 {
+		t.Errorf("expected no warning for the same maximum size, got: %v", hook.AllEntries())
+	}
+
+	if _, err := openDiskCache(DiskCacheOptions{Path: path, MaxSizeBytes: 50}); err != nil {
+		t.Fatalf("failed to open disk cache: %v", err)
+	}
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.WarnLevel {
+		t.Errorf("expected a warning for a conflicting maximum size, got: %v", entry)
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	if first.maxSize != 50 {
+		t.Errorf("expected the maximum size last configured to apply, got %d", first.maxSize)
+	}
+}
@@ -31,6 +31,11 @@ type NewOptions struct {
 
 	// Parameters is additional parameters for the store. Optional.
 	Parameters any `json:"parameters,omitempty"`
+
+	// DiskCache configures the cache of the manifests and blobs fetched by the
+	// store on local disk. Content is not cached on disk if not specified.
+	// Optional.
+	DiskCache *DiskCacheOptions `json:"diskCache,omitempty"`
 }
 
 // registry saves the registered store factories.
@@ -53,8 +58,8 @@ func Register(storeType string, create func(NewOptions) (ratify.Store, error)) {
 	registry[storeType] = create
 }
 
-// New creates a new [ratify.StoreMux] instance where each store is registered
-// for its respective scopes.
+// New creates a new store multiplexer where each store is registered for its
+// respective scopes.
 func New(opts []NewOptions, globalScopes []string) (ratify.Store, error) {
 	if len(opts) == 0 {
 		return nil, fmt.Errorf("no store options provided")
@@ -64,7 +69,7 @@ func New(opts []NewOptions, globalScopes []string) (ratify.Store, error) {
 	if len(opts) > 1 {
 		globalScopes = []string{}
 	}
-	storeMux := ratify.NewStoreMux()
+	mux := &storeMux{}
 	for _, storeOptions := range opts {
 		if len(storeOptions.Scopes) == 0 {
 			// if no scopes are provided, use the global scopes of the executor.
@@ -78,17 +83,18 @@ func New(opts []NewOptions, globalScopes []string) (ratify.Store, error) {
 			return nil, fmt.Errorf("failed to create store for type %q: %w", storeOptions.Type, err)
 		}
 		for _, scope := range storeOptions.Scopes {
-			if err = storeMux.Register(scope, store); err != nil {
+			if err = mux.Register(scope, storeOptions.Type, store); err != nil {
 				return nil, fmt.Errorf("failed to register store for scope %q: %w", scope, err)
 			}
 		}
 	}
 
-	return storeMux, nil
+	return mux, nil
 }
 
 // newStore creates a new [ratify.Store] instance based on the provided options
-// and will be used to register the store in the [ratify.StoreMux].
+// and will be used to register the store in the store multiplexer. The store
+// is wrapped with a disk cache if configured.
 func newStore(opts NewOptions) (ratify.Store, error) {
 	if opts.Type == "" {
 		return nil, fmt.Errorf("store type is not provided in the store options")
@@ -97,5 +103,9 @@ func newStore(opts NewOptions) (ratify.Store, error) {
 	if !ok {
 		return nil, fmt.Errorf("store factory of type %s is not registered", opts.Type)
 	}
-	return create(opts)
+	store, err := create(opts)
+	if err != nil || opts.DiskCache == nil {
+		return store, err
+	}
+	return newDiskCachedStore(store, *opts.DiskCache)
 }
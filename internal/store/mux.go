@@ -0,0 +1,156 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.opentelemetry.io/otel/trace"
+	orasregistry "oras.land/oras-go/v2/registry"
+
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/scope"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+)
+
+var logOpt = logger.Option{ComponentType: logger.ReferrerStore}
+
+// storeMux is a store multiplexer that routes each request to the store
+// registered for the most specific scope matching the request. Unlike
+// [ratify.StoreMux], it resolves scopes with [scope.Matcher] so that stores
+// accept the same scope patterns as executors and verifiers.
+type storeMux struct {
+	matcher scope.Matcher[*muxEntry]
+}
+
+// muxEntry is the value registered for each scope of a storeMux.
+type muxEntry struct {
+	store     ratify.Store
+	storeType string
+}
+
+// Explanation describes the store that requests for an artifact are routed to.
+type Explanation struct {
+	// Type is the type of the store.
+	Type string `json:"type"`
+
+	// Scope is the store scope matched by the artifact.
+	Scope string `json:"scope"`
+
+	// MatchKind is the kind of the matched scope.
+	MatchKind scope.Kind `json:"matchKind"`
+}
+
+// Explain describes the store that requests for the artifact are routed to.
+// The store must be created by [New].
+func Explain(store ratify.Store, artifact string) (*Explanation, error) {
+	mux, ok := store.(*storeMux)
+	if !ok {
+		return nil, fmt.Errorf("store of type %T cannot be explained", store)
+	}
+	ref, err := orasregistry.ParseReference(artifact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reference %q: %w", artifact, err)
+	}
+	entry, match, ok := mux.matcher.MatchScope(ref)
+	if !ok {
+		return nil, fmt.Errorf("no matching store found for %q", artifact)
+	}
+	return &Explanation{
+		Type:      entry.storeType,
+		Scope:     match.Scope,
+		MatchKind: match.Kind,
+	}, nil
+}
+
+// Register registers a store of the given type for the given scope.
+func (s *storeMux) Register(scope, storeType string, store ratify.Store) error {
+	if store == nil {
+		return fmt.Errorf("store cannot be nil")
+	}
+	return s.matcher.Register(scope, &muxEntry{
+		store:     store,
+		storeType: storeType,
+	})
+}
+
+// Resolve resolves to a descriptor for the given artifact reference.
+func (s *storeMux) Resolve(ctx context.Context, ref string) (desc ocispec.Descriptor, err error) {
+	ctx, span := tracing.StartSpan(ctx, "store.Resolve", tracing.AttributeArtifact.String(ref))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	entry, err := s.match(ctx, ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return entry.store.Resolve(ctx, ref)
+}
+
+// ListReferrers returns the immediate set of supply chain artifacts for the
+// given subject reference.
+func (s *storeMux) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "store.ListReferrers", tracing.AttributeArtifact.String(ref))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	entry, err := s.match(ctx, ref)
+	if err != nil {
+		return err
+	}
+	return entry.store.ListReferrers(ctx, ref, artifactTypes, fn)
+}
+
+// FetchBlob returns the blob by the given reference.
+func (s *storeMux) FetchBlob(ctx context.Context, repo string, desc ocispec.Descriptor) (blob []byte, err error) {
+	ctx, span := tracing.StartSpan(ctx, "store.FetchBlob", tracing.AttributeArtifact.String(repo+"@"+desc.Digest.String()))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	entry, err := s.match(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return entry.store.FetchBlob(ctx, repo, desc)
+}
+
+// FetchManifest returns the referenced manifest as given by the descriptor.
+func (s *storeMux) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) (manifest []byte, err error) {
+	ctx, span := tracing.StartSpan(ctx, "store.FetchManifest", tracing.AttributeArtifact.String(repo+"@"+desc.Digest.String()))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	entry, err := s.match(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return entry.store.FetchManifest(ctx, repo, desc)
+}
+
+// match finds the store entry for the given artifact reference or repository
+// and records the store type on the span in ctx.
+func (s *storeMux) match(ctx context.Context, reference string) (*muxEntry, error) {
+	ref, err := orasregistry.ParseReference(reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reference %q: %w", reference, err)
+	}
+	entry, ok := s.matcher.Match(ref)
+	if !ok {
+		return nil, fmt.Errorf("no matching store found for %q", reference)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttributeStoreType.String(entry.storeType))
+	logger.GetLogger(ctx, logOpt).Debugf("routing %s to store of type %s", reference, entry.storeType)
+	return entry, nil
+}
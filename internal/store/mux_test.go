@@ -0,0 +1,128 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/notaryproject/ratify/v2/internal/scope"
+)
+
+type namedStore struct {
+	mockStore
+	name string
+}
+
+func (s *namedStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{Digest: digest.Digest(s.name)}, nil
+}
+
+func TestStoreMux(t *testing.T) {
+	mux := &storeMux{}
+	if err := mux.Register("registry.example.com", "mock", nil); err == nil {
+		t.Fatal("expected error when registering nil store")
+	}
+	for _, scope := range []string{"registry.example.com", "registry.example.com/team/**"} {
+		if err := mux.Register(scope, "mock", &namedStore{name: scope}); err != nil {
+			t.Fatalf("failed to register store for scope %q: %v", scope, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "registry scope",
+			reference: "registry.example.com/app:v1",
+			want:      "registry.example.com",
+		},
+		{
+			name:      "repository prefix scope",
+			reference: "registry.example.com/team/group/app:v1",
+			want:      "registry.example.com/team/**",
+		},
+		{
+			name:      "no matching store",
+			reference: "unknown.com/app:v1",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid reference",
+			reference: "invalid",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, err := mux.Resolve(context.Background(), tt.reference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(desc.Digest) != tt.want {
+				t.Errorf("Resolve() routed to %q, want %q", desc.Digest, tt.want)
+			}
+			if err := mux.ListReferrers(context.Background(), tt.reference, nil, nil); (err != nil) != tt.wantErr {
+				t.Errorf("ListReferrers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := mux.FetchBlob(context.Background(), "registry.example.com/team/app", ocispec.Descriptor{}); err != nil {
+		t.Errorf("FetchBlob() unexpected error = %v", err)
+	}
+	if _, err := mux.FetchManifest(context.Background(), "unknown.com/app", ocispec.Descriptor{}); err == nil {
+		t.Error("FetchManifest() expected error for unknown repository")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	mux := &storeMux{}
+	if err := mux.Register("registry.example.com/team/**", "team-store", &mockStore{}); err != nil {
+		t.Fatalf("failed to register store: %v", err)
+	}
+
+	explanation, err := Explain(mux, "registry.example.com/team/app:v1")
+	if err != nil {
+		t.Fatalf("Explain() unexpected error = %v", err)
+	}
+	expected := &Explanation{
+		Type:      "team-store",
+		Scope:     "registry.example.com/team/**",
+		MatchKind: scope.KindRepository,
+	}
+	if !reflect.DeepEqual(explanation, expected) {
+		t.Errorf("Explain() = %+v, want %+v", explanation, expected)
+	}
+
+	if _, err := Explain(mux, "unknown.com/app:v1"); err == nil {
+		t.Error("Explain() expected error for unknown registry")
+	}
+	if _, err := Explain(mux, "invalid"); err == nil {
+		t.Error("Explain() expected error for invalid reference")
+	}
+	if _, err := Explain(&mockStore{}, "registry.example.com/team/app:v1"); err == nil {
+		t.Error("Explain() expected error for store not created by New")
+	}
+}
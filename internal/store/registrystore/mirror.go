@@ -0,0 +1,373 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasregistry "oras.land/oras-go/v2/registry"
+
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/store/credentialprovider"
+)
+
+var logOpt = logger.Option{ComponentType: logger.ReferrerStore}
+
+// AnnotationEndpoint is the annotation added to the descriptors resolved and
+// listed by a store with registry mirrors, naming the registry endpoint that
+// served the artifact.
+const AnnotationEndpoint = "dev.ratify.store.endpoint"
+
+// errDigestMismatch is returned when the endpoints of a registry resolve a
+// reference to different digests.
+var errDigestMismatch = errors.New("subject digest mismatch across registry endpoints")
+
+// mirrorOptions configures a mirror of a registry.
+type mirrorOptions struct {
+	// Endpoint is the host of the mirror, optionally followed by the
+	// repository prefix the repositories of the registry are mirrored under,
+	// e.g. "mirror.example.com/docker.io". Required.
+	Endpoint string `json:"endpoint"`
+
+	// PlainHTTP indicates whether to use HTTP instead of HTTPS. Optional.
+	PlainHTTP bool `json:"plainHttp,omitempty"`
+
+	// CredentialProvider is the credential provider configuration of the
+	// mirror. The mirror is accessed anonymously if not specified. Optional.
+	CredentialProvider credentialprovider.Options `json:"credential,omitempty"`
+
+	// CAPem is a PEM encoded CA bundle to use for TLS connections to the
+	// mirror. Optional.
+	CAPem string `json:"caPem,omitempty"`
+
+	// CABase64 is a base64 encoded CA bundle to use for TLS connections to the
+	// mirror. Either CABase64 or CAPem can be used, but CAPem is preferred.
+	// Optional.
+	CABase64 string `json:"caBase64,omitempty"`
+}
+
+// endpoint is a registry endpoint serving the repositories of a registry,
+// either a mirror or the registry itself.
+type endpoint struct {
+	// name identifies the endpoint in the descriptors it serves.
+	name string
+
+	// host and prefix locate the repositories of the registry on a mirror.
+	// host is empty for the registry itself.
+	host   string
+	prefix string
+
+	store ratify.Store
+}
+
+// newMirrorEndpoint creates the endpoint of a mirror, accessed with its own
+// TLS and credential settings and the limits of the registry store.
+func newMirrorEndpoint(opts mirrorOptions, params options) (*endpoint, error) {
+	host, prefix, _ := strings.Cut(opts.Endpoint, "/")
+	ref := orasregistry.Reference{Registry: host, Repository: prefix}
+	if err := ref.ValidateRegistry(); err != nil {
+		return nil, fmt.Errorf("invalid mirror endpoint %q: %w", opts.Endpoint, err)
+	}
+	if prefix != "" {
+		if err := ref.ValidateRepository(); err != nil {
+			return nil, fmt.Errorf("invalid mirror endpoint %q: %w", opts.Endpoint, err)
+		}
+	}
+
+	var credProvider ratify.RegistryCredentialGetter
+	if len(opts.CredentialProvider) > 0 {
+		var err error
+		if credProvider, err = credentialprovider.NewCredentialProvider(opts.CredentialProvider); err != nil {
+			return nil, fmt.Errorf("failed to create credential provider of mirror %s: %w", opts.Endpoint, err)
+		}
+	}
+	httpClient, err := createHTTPClient(opts.CAPem, opts.CABase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client of mirror %s: %w", opts.Endpoint, err)
+	}
+
+	return &endpoint{
+		name:   opts.Endpoint,
+		host:   host,
+		prefix: prefix,
+		store: ratify.NewRegistryStore(ratify.RegistryStoreOptions{
+			HTTPClient:         instrumentHTTPClient(httpClient),
+			PlainHTTP:          opts.PlainHTTP,
+			UserAgent:          params.UserAgent,
+			MaxBlobBytes:       params.MaxBlobBytes,
+			MaxManifestBytes:   params.MaxManifestBytes,
+			AllowCosignTag:     params.AllowCosignTag,
+			CredentialProvider: credProvider,
+		}),
+	}, nil
+}
+
+// reference returns the reference on the endpoint of the reference on the
+// registry.
+func (e *endpoint) reference(ref orasregistry.Reference) string {
+	if e.host != "" {
+		ref.Registry = e.host
+		if e.prefix != "" {
+			ref.Repository = e.prefix + "/" + ref.Repository
+		}
+	}
+	return ref.String()
+}
+
+// annotate returns a copy of the descriptor annotated with the endpoint.
+func (e *endpoint) annotate(desc ocispec.Descriptor) ocispec.Descriptor {
+	annotations := make(map[string]string, len(desc.Annotations)+1)
+	maps.Copy(annotations, desc.Annotations)
+	annotations[AnnotationEndpoint] = e.name
+	desc.Annotations = annotations
+	return desc
+}
+
+// mirrorStore is a [ratify.Store] trying the mirrors of a registry in order
+// before the registry itself. The descriptors resolved and listed from a
+// mirrored registry are annotated with [AnnotationEndpoint]. Requests
+// for registries without mirrors go to the upstream store.
+type mirrorStore struct {
+	upstream ratify.Store
+
+	// endpoints holds the endpoints of each mirrored registry in the order
+	// they are tried, the registry itself being the last.
+	endpoints map[string][]*endpoint
+}
+
+// newMirrorStore wraps the upstream store with the mirrors of the options.
+func newMirrorStore(upstream ratify.Store, params options) (ratify.Store, error) {
+	s := &mirrorStore{
+		upstream:  upstream,
+		endpoints: make(map[string][]*endpoint),
+	}
+	for registry, mirrors := range params.Mirrors {
+		if len(mirrors) == 0 {
+			continue
+		}
+		if err := (orasregistry.Reference{Registry: registry}).ValidateRegistry(); err != nil {
+			return nil, fmt.Errorf("invalid mirrored registry %q: %w", registry, err)
+		}
+		endpoints := make([]*endpoint, 0, len(mirrors)+1)
+		for _, mirror := range mirrors {
+			e, err := newMirrorEndpoint(mirror, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create mirror of registry %s: %w", registry, err)
+			}
+			endpoints = append(endpoints, e)
+		}
+		s.endpoints[registry] = append(endpoints, &endpoint{name: registry, store: upstream})
+	}
+	return s, nil
+}
+
+// Resolve resolves to a descriptor for the given artifact reference. A
+// reference by digest is resolved on the first endpoint serving it. A
+// reference by tag is resolved on all endpoints so that the digests they
+// serve are cross-checked.
+func (s *mirrorStore) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	ref, endpoints, err := s.route(reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if endpoints == nil {
+		return s.upstream.Resolve(ctx, reference)
+	}
+	if dgst, err := ref.Digest(); err == nil {
+		return resolveDigest(ctx, ref, dgst, endpoints)
+	}
+	return resolveTag(ctx, ref, endpoints)
+}
+
+// ListReferrers returns the immediate set of supply chain artifacts for the
+// given subject reference merged from all endpoints in order, as a mirror may
+// only serve part of the referrers. A referrer listed by several endpoints is
+// only returned once, annotated with the first endpoint listing it.
+func (s *mirrorStore) ListReferrers(ctx context.Context, reference string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	ref, endpoints, err := s.route(reference)
+	if err != nil {
+		return err
+	}
+	if endpoints == nil {
+		return s.upstream.ListReferrers(ctx, reference, artifactTypes, fn)
+	}
+	if _, err := ref.Digest(); err != nil {
+		// Resolve the subject across the endpoints first, so that the
+		// referrers of the same digest are listed on every endpoint.
+		desc, err := s.Resolve(ctx, reference)
+		if err != nil {
+			return err
+		}
+		ref.Reference = desc.Digest.String()
+	}
+
+	var errs []error
+	var listed bool
+	seen := make(map[digest.Digest]struct{})
+	for _, e := range endpoints {
+		var fnErr error
+		err := e.store.ListReferrers(ctx, e.reference(ref), artifactTypes, func(referrers []ocispec.Descriptor) error {
+			var merged []ocispec.Descriptor
+			for _, referrer := range referrers {
+				if _, ok := seen[referrer.Digest]; ok {
+					continue
+				}
+				seen[referrer.Digest] = struct{}{}
+				merged = append(merged, e.annotate(referrer))
+			}
+			if len(merged) == 0 {
+				return nil
+			}
+			fnErr = fn(merged)
+			return fnErr
+		})
+		switch {
+		case fnErr != nil:
+			return fnErr
+		case err != nil:
+			logger.GetLogger(ctx, logOpt).Debugf("failed to list referrers of %s on %s: %v", ref, e.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+		default:
+			listed = true
+		}
+	}
+	if listed {
+		return nil
+	}
+	return fmt.Errorf("failed to list referrers of %s on any endpoint: %w", ref, errors.Join(errs...))
+}
+
+// FetchBlob returns the blob by the given reference. The endpoint annotated on
+// the descriptor is tried first.
+func (s *mirrorStore) FetchBlob(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(ctx, repo, desc, ratify.Store.FetchBlob)
+}
+
+// FetchManifest returns the referenced manifest as given by the descriptor.
+// The endpoint annotated on the descriptor is tried first.
+func (s *mirrorStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(ctx, repo, desc, ratify.Store.FetchManifest)
+}
+
+// fetch fetches the content of the descriptor from the first endpoint serving
+// it.
+func (s *mirrorStore) fetch(ctx context.Context, repo string, desc ocispec.Descriptor, fetch func(ratify.Store, context.Context, string, ocispec.Descriptor) ([]byte, error)) ([]byte, error) {
+	ref, endpoints, err := s.route(repo)
+	if err != nil {
+		return nil, err
+	}
+	if endpoints == nil {
+		return fetch(s.upstream, ctx, repo, desc)
+	}
+
+	var errs []error
+	for _, e := range preferEndpoint(endpoints, desc.Annotations[AnnotationEndpoint]) {
+		content, err := fetch(e.store, ctx, e.reference(ref), desc)
+		if err == nil {
+			return content, nil
+		}
+		logger.GetLogger(ctx, logOpt).Debugf("failed to fetch %s from %s on %s: %v", desc.Digest, repo, e.name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+	}
+	return nil, fmt.Errorf("failed to fetch %s from %s on any endpoint: %w", desc.Digest, repo, errors.Join(errs...))
+}
+
+// route parses the reference or repository and returns the endpoints of its
+// registry, which are nil if the registry is not mirrored.
+func (s *mirrorStore) route(reference string) (orasregistry.Reference, []*endpoint, error) {
+	ref, err := orasregistry.ParseReference(reference)
+	if err != nil {
+		return orasregistry.Reference{}, nil, fmt.Errorf("failed to parse reference %q: %w", reference, err)
+	}
+	return ref, s.endpoints[ref.Registry], nil
+}
+
+// resolveDigest resolves the reference by digest on the first endpoint serving
+// the digest.
+func resolveDigest(ctx context.Context, ref orasregistry.Reference, dgst digest.Digest, endpoints []*endpoint) (ocispec.Descriptor, error) {
+	var errs []error
+	for _, e := range endpoints {
+		desc, err := e.store.Resolve(ctx, e.reference(ref))
+		if err == nil && desc.Digest != dgst {
+			err = fmt.Errorf("%w: resolved %s", errDigestMismatch, desc.Digest)
+		}
+		if err == nil {
+			return e.annotate(desc), nil
+		}
+		logger.GetLogger(ctx, logOpt).Debugf("failed to resolve %s on %s: %v", ref, e.name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s on any endpoint: %w", ref, errors.Join(errs...))
+}
+
+// resolveTag resolves the reference by tag on all endpoints concurrently and
+// returns the descriptor of the first endpoint in order resolving it. The
+// endpoints resolving the tag must agree on its digest.
+func resolveTag(ctx context.Context, ref orasregistry.Reference, endpoints []*endpoint) (ocispec.Descriptor, error) {
+	descs := make([]ocispec.Descriptor, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, e := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			descs[i], errs[i] = e.store.Resolve(ctx, e.reference(ref))
+		}()
+	}
+	wg.Wait()
+
+	served := -1
+	for i, e := range endpoints {
+		if errs[i] != nil {
+			logger.GetLogger(ctx, logOpt).Debugf("failed to resolve %s on %s: %v", ref, e.name, errs[i])
+			errs[i] = fmt.Errorf("%s: %w", e.name, errs[i])
+			continue
+		}
+		if served < 0 {
+			served = i
+			continue
+		}
+		if descs[i].Digest != descs[served].Digest {
+			return ocispec.Descriptor{}, fmt.Errorf("%w: %s resolved %s to %s but %s resolved it to %s", errDigestMismatch, endpoints[served].name, ref, descs[served].Digest, e.name, descs[i].Digest)
+		}
+	}
+	if served < 0 {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s on any endpoint: %w", ref, errors.Join(errs...))
+	}
+	return endpoints[served].annotate(descs[served]), nil
+}
+
+// preferEndpoint returns the endpoints with the named endpoint moved first.
+func preferEndpoint(endpoints []*endpoint, name string) []*endpoint {
+	for i, e := range endpoints {
+		if e.name != name || i == 0 {
+			continue
+		}
+		ordered := make([]*endpoint, 0, len(endpoints))
+		ordered = append(ordered, e)
+		ordered = append(ordered, endpoints[:i]...)
+		return append(ordered, endpoints[i+1:]...)
+	}
+	return endpoints
+}
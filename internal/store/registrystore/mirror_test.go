@@ -0,0 +1,437 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	orasregistry "oras.land/oras-go/v2/registry"
+
+	"github.com/notaryproject/ratify/v2/internal/store"
+	"github.com/notaryproject/ratify/v2/internal/store/credentialprovider"
+)
+
+const (
+	testRegistry = "registry.example.com"
+	testMirror   = "mirror.example.com/registry"
+)
+
+var (
+	testSubject  = digest.FromString("subject")
+	testReferrer = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("referrer"),
+	}
+)
+
+// endpointStore is a registry endpoint serving manifests, referrers and
+// content by reference, and recording the requested references.
+type endpointStore struct {
+	mu        sync.Mutex
+	manifests map[string]ocispec.Descriptor
+	referrers map[string][]ocispec.Descriptor
+	contents  map[string][]byte
+	requests  []string
+}
+
+func (s *endpointStore) record(reference string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, reference)
+}
+
+func (s *endpointStore) requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *endpointStore) Resolve(_ context.Context, ref string) (ocispec.Descriptor, error) {
+	s.record(ref)
+	desc, ok := s.manifests[ref]
+	if !ok {
+		return ocispec.Descriptor{}, errors.New("not found")
+	}
+	return desc, nil
+}
+
+func (s *endpointStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	s.record(ref)
+	referrers, ok := s.referrers[ref]
+	if !ok {
+		return errors.New("not found")
+	}
+	return fn(referrers)
+}
+
+func (s *endpointStore) FetchBlob(_ context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	ref := repo + "@" + desc.Digest.String()
+	s.record(ref)
+	content, ok := s.contents[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return content, nil
+}
+
+func (s *endpointStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.FetchBlob(ctx, repo, desc)
+}
+
+// newTestMirrorStore creates a mirror store of testRegistry with a mirror at
+// testMirror.
+func newTestMirrorStore(mirror, upstream *endpointStore) *mirrorStore {
+	return &mirrorStore{
+		upstream: upstream,
+		endpoints: map[string][]*endpoint{
+			testRegistry: {
+				{name: testMirror, host: "mirror.example.com", prefix: "registry", store: mirror},
+				{name: testRegistry, store: upstream},
+			},
+		},
+	}
+}
+
+func annotated(desc ocispec.Descriptor, endpoint string) ocispec.Descriptor {
+	desc.Annotations = map[string]string{AnnotationEndpoint: endpoint}
+	return desc
+}
+
+func TestNewMirrorStore(t *testing.T) {
+	tests := []struct {
+		name      string
+		mirrors   map[string][]mirrorOptions
+		expectErr bool
+	}{
+		{
+			name: "mirrors with own settings",
+			mirrors: map[string][]mirrorOptions{
+				"docker.io": {
+					{Endpoint: "mirror.example.com/docker.io", PlainHTTP: true},
+					{Endpoint: "localhost:5000", CredentialProvider: credentialprovider.Options{"provider": "static", "password": "token"}},
+				},
+				"ghcr.io": nil,
+			},
+		},
+		{
+			name:      "invalid registry",
+			mirrors:   map[string][]mirrorOptions{"invalid registry": {{Endpoint: "mirror.example.com"}}},
+			expectErr: true,
+		},
+		{
+			name:      "missing endpoint",
+			mirrors:   map[string][]mirrorOptions{"docker.io": {{}}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid repository prefix",
+			mirrors:   map[string][]mirrorOptions{"docker.io": {{Endpoint: "mirror.example.com/Invalid"}}},
+			expectErr: true,
+		},
+		{
+			name: "invalid credential provider",
+			mirrors: map[string][]mirrorOptions{"docker.io": {{
+				Endpoint:           "mirror.example.com",
+				CredentialProvider: credentialprovider.Options{"provider": "nonexistent"},
+			}}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid CA",
+			mirrors:   map[string][]mirrorOptions{"docker.io": {{Endpoint: "mirror.example.com", CAPem: "invalid"}}},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upstream := &endpointStore{}
+			s, err := newMirrorStore(upstream, options{Mirrors: test.mirrors})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+			endpoints := s.(*mirrorStore).endpoints
+			if _, ok := endpoints["ghcr.io"]; ok {
+				t.Errorf("expected registry without mirrors not to be mirrored")
+			}
+			var names []string
+			for _, e := range endpoints["docker.io"] {
+				names = append(names, e.name)
+			}
+			if want := []string{"mirror.example.com/docker.io", "localhost:5000", "docker.io"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("expected endpoints %v, got %v", want, names)
+			}
+			if last := endpoints["docker.io"][2]; last.store != upstream {
+				t.Errorf("expected the registry itself to be tried last")
+			}
+		})
+	}
+}
+
+func TestRegistryStoreFactory_Mirrors(t *testing.T) {
+	s, err := store.New([]store.NewOptions{{
+		Type: registryStoreType,
+		Parameters: map[string]any{
+			"credential": map[string]any{
+				"provider": "static",
+				"password": "token",
+			},
+			"mirrors": map[string]any{
+				testRegistry: []any{
+					map[string]any{"endpoint": testMirror, "plainHttp": true},
+				},
+			},
+		},
+		Scopes: []string{testScope},
+	}}, nil)
+	if err != nil || s == nil {
+		t.Fatalf("expected store with mirrors to be created, got: %v", err)
+	}
+}
+
+func TestEndpoint_Reference(t *testing.T) {
+	ref, err := orasregistry.ParseReference(testRegistry + "/app:v1")
+	if err != nil {
+		t.Fatalf("failed to parse reference: %v", err)
+	}
+	tests := []struct {
+		endpoint endpoint
+		expected string
+	}{
+		{endpoint: endpoint{name: testRegistry}, expected: testRegistry + "/app:v1"},
+		{endpoint: endpoint{name: "localhost:5000", host: "localhost:5000"}, expected: "localhost:5000/app:v1"},
+		{endpoint: endpoint{name: testMirror, host: "mirror.example.com", prefix: "registry"}, expected: testMirror + "/app:v1"},
+	}
+	for _, test := range tests {
+		if got := test.endpoint.reference(ref); got != test.expected {
+			t.Errorf("expected %s on %s, got %s", test.expected, test.endpoint.name, got)
+		}
+	}
+}
+
+func TestMirrorStore_Resolve(t *testing.T) {
+	ctx := context.Background()
+	subject := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: testSubject}
+	other := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("other")}
+
+	t.Run("served by mirror", func(t *testing.T) {
+		mirror := &endpointStore{manifests: map[string]ocispec.Descriptor{testMirror + "/app:v1": subject}}
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{testRegistry + "/app:v1": subject}}
+		desc, err := newTestMirrorStore(mirror, upstream).Resolve(ctx, testRegistry+"/app:v1")
+		if err != nil {
+			t.Fatalf("failed to resolve: %v", err)
+		}
+		if want := annotated(subject, testMirror); !reflect.DeepEqual(desc, want) {
+			t.Errorf("expected %v, got %v", want, desc)
+		}
+		if len(upstream.requested()) != 1 {
+			t.Errorf("expected the tag to be cross-checked on the registry")
+		}
+	})
+
+	t.Run("falls back to registry", func(t *testing.T) {
+		mirror := &endpointStore{}
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{testRegistry + "/app:v1": subject}}
+		desc, err := newTestMirrorStore(mirror, upstream).Resolve(ctx, testRegistry+"/app:v1")
+		if err != nil {
+			t.Fatalf("failed to resolve: %v", err)
+		}
+		if want := annotated(subject, testRegistry); !reflect.DeepEqual(desc, want) {
+			t.Errorf("expected %v, got %v", want, desc)
+		}
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		mirror := &endpointStore{manifests: map[string]ocispec.Descriptor{testMirror + "/app:v1": other}}
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{testRegistry + "/app:v1": subject}}
+		if _, err := newTestMirrorStore(mirror, upstream).Resolve(ctx, testRegistry+"/app:v1"); !errors.Is(err, errDigestMismatch) {
+			t.Errorf("expected digest mismatch error, got: %v", err)
+		}
+	})
+
+	t.Run("by digest", func(t *testing.T) {
+		byDigest := "/app@" + testSubject.String()
+		mirror := &endpointStore{manifests: map[string]ocispec.Descriptor{testMirror + byDigest: other}}
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{testRegistry + byDigest: subject}}
+		desc, err := newTestMirrorStore(mirror, upstream).Resolve(ctx, testRegistry+byDigest)
+		if err != nil {
+			t.Fatalf("failed to resolve: %v", err)
+		}
+		if want := annotated(subject, testRegistry); !reflect.DeepEqual(desc, want) {
+			t.Errorf("expected the endpoint serving the digest, got %v", desc)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := newTestMirrorStore(&endpointStore{}, &endpointStore{}).Resolve(ctx, testRegistry+"/app:v1"); err == nil {
+			t.Errorf("expected error when no endpoint resolves the reference")
+		}
+	})
+
+	t.Run("not mirrored", func(t *testing.T) {
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{"other.example.com/app:v1": subject}}
+		desc, err := newTestMirrorStore(&endpointStore{}, upstream).Resolve(ctx, "other.example.com/app:v1")
+		if err != nil {
+			t.Fatalf("failed to resolve: %v", err)
+		}
+		if !reflect.DeepEqual(desc, subject) {
+			t.Errorf("expected unannotated descriptor, got %v", desc)
+		}
+	})
+}
+
+func TestMirrorStore_ListReferrers(t *testing.T) {
+	ctx := context.Background()
+	byDigest := "/app@" + testSubject.String()
+	list := func(s *mirrorStore, ref string) ([]ocispec.Descriptor, error) {
+		var listed []ocispec.Descriptor
+		err := s.ListReferrers(ctx, ref, nil, func(referrers []ocispec.Descriptor) error {
+			listed = append(listed, referrers...)
+			return nil
+		})
+		return listed, err
+	}
+
+	t.Run("served by mirror", func(t *testing.T) {
+		mirror := &endpointStore{referrers: map[string][]ocispec.Descriptor{testMirror + byDigest: {testReferrer}}}
+		upstream := &endpointStore{}
+		listed, err := list(newTestMirrorStore(mirror, upstream), testRegistry+byDigest)
+		if err != nil {
+			t.Fatalf("failed to list referrers: %v", err)
+		}
+		if want := []ocispec.Descriptor{annotated(testReferrer, testMirror)}; !reflect.DeepEqual(listed, want) {
+			t.Errorf("expected %v, got %v", want, listed)
+		}
+	})
+
+	t.Run("merges partial mirror", func(t *testing.T) {
+		otherReferrer := ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("other referrer"),
+		}
+		mirror := &endpointStore{referrers: map[string][]ocispec.Descriptor{testMirror + byDigest: {testReferrer}}}
+		upstream := &endpointStore{referrers: map[string][]ocispec.Descriptor{testRegistry + byDigest: {testReferrer, otherReferrer}}}
+		listed, err := list(newTestMirrorStore(mirror, upstream), testRegistry+byDigest)
+		if err != nil {
+			t.Fatalf("failed to list referrers: %v", err)
+		}
+		want := []ocispec.Descriptor{
+			annotated(testReferrer, testMirror),
+			annotated(otherReferrer, testRegistry),
+		}
+		if !reflect.DeepEqual(listed, want) {
+			t.Errorf("expected %v, got %v", want, listed)
+		}
+	})
+
+	t.Run("falls back on error and empty list", func(t *testing.T) {
+		for _, mirror := range []*endpointStore{
+			{},
+			{referrers: map[string][]ocispec.Descriptor{testMirror + byDigest: {}}},
+		} {
+			upstream := &endpointStore{referrers: map[string][]ocispec.Descriptor{testRegistry + byDigest: {testReferrer}}}
+			listed, err := list(newTestMirrorStore(mirror, upstream), testRegistry+byDigest)
+			if err != nil {
+				t.Fatalf("failed to list referrers: %v", err)
+			}
+			if want := []ocispec.Descriptor{annotated(testReferrer, testRegistry)}; !reflect.DeepEqual(listed, want) {
+				t.Errorf("expected %v, got %v", want, listed)
+			}
+		}
+	})
+
+	t.Run("no referrers", func(t *testing.T) {
+		upstream := &endpointStore{referrers: map[string][]ocispec.Descriptor{testRegistry + byDigest: {}}}
+		if listed, err := list(newTestMirrorStore(&endpointStore{}, upstream), testRegistry+byDigest); err != nil || len(listed) != 0 {
+			t.Errorf("expected no referrers, got %v, %v", listed, err)
+		}
+		if _, err := list(newTestMirrorStore(&endpointStore{}, &endpointStore{}), testRegistry+byDigest); err == nil {
+			t.Errorf("expected error when no endpoint lists referrers")
+		}
+	})
+
+	t.Run("by tag", func(t *testing.T) {
+		subject := ocispec.Descriptor{Digest: testSubject}
+		mirror := &endpointStore{
+			manifests: map[string]ocispec.Descriptor{testMirror + "/app:v1": subject},
+			referrers: map[string][]ocispec.Descriptor{testMirror + byDigest: {testReferrer}},
+		}
+		upstream := &endpointStore{manifests: map[string]ocispec.Descriptor{testRegistry + "/app:v1": {Digest: digest.FromString("other")}}}
+		if _, err := list(newTestMirrorStore(mirror, upstream), testRegistry+"/app:v1"); !errors.Is(err, errDigestMismatch) {
+			t.Errorf("expected digest mismatch error, got: %v", err)
+		}
+
+		upstream.manifests[testRegistry+"/app:v1"] = subject
+		listed, err := list(newTestMirrorStore(mirror, upstream), testRegistry+"/app:v1")
+		if err != nil {
+			t.Fatalf("failed to list referrers: %v", err)
+		}
+		if want := []ocispec.Descriptor{annotated(testReferrer, testMirror)}; !reflect.DeepEqual(listed, want) {
+			t.Errorf("expected %v, got %v", want, listed)
+		}
+	})
+
+	t.Run("fn error", func(t *testing.T) {
+		errFn := errors.New("fn error")
+		mirror := &endpointStore{referrers: map[string][]ocispec.Descriptor{testMirror + byDigest: {testReferrer}}}
+		upstream := &endpointStore{referrers: map[string][]ocispec.Descriptor{testRegistry + byDigest: {testReferrer}}}
+		err := newTestMirrorStore(mirror, upstream).ListReferrers(ctx, testRegistry+byDigest, nil, func([]ocispec.Descriptor) error {
+			return errFn
+		})
+		if !errors.Is(err, errFn) {
+			t.Errorf("expected fn error, got: %v", err)
+		}
+		if len(upstream.requested()) != 0 {
+			t.Errorf("expected no fallback once referrers are handed to fn")
+		}
+	})
+}
+
+func TestMirrorStore_Fetch(t *testing.T) {
+	ctx := context.Background()
+	blob := "/app@" + testReferrer.Digest.String()
+	mirror := &endpointStore{contents: map[string][]byte{testMirror + blob: []byte("mirror")}}
+	upstream := &endpointStore{contents: map[string][]byte{testRegistry + blob: []byte("upstream"), "other.example.com" + blob: []byte("other")}}
+	s := newTestMirrorStore(mirror, upstream)
+
+	if content, err := s.FetchManifest(ctx, testRegistry+"/app", testReferrer); err != nil || string(content) != "mirror" {
+		t.Errorf("expected content from the mirror, got %q, %v", content, err)
+	}
+	if content, err := s.FetchBlob(ctx, testRegistry+"/app", annotated(testReferrer, testRegistry)); err != nil || string(content) != "upstream" {
+		t.Errorf("expected content from the annotated endpoint, got %q, %v", content, err)
+	}
+	if content, err := s.FetchBlob(ctx, "other.example.com/app", testReferrer); err != nil || string(content) != "other" {
+		t.Errorf("expected content from the registry not mirrored, got %q, %v", content, err)
+	}
+
+	delete(mirror.contents, testMirror+blob)
+	if content, err := s.FetchBlob(ctx, testRegistry+"/app", testReferrer); err != nil || string(content) != "upstream" {
+		t.Errorf("expected fallback to the registry, got %q, %v", content, err)
+	}
+	delete(upstream.contents, testRegistry+blob)
+	if _, err := s.FetchBlob(ctx, testRegistry+"/app", testReferrer); err == nil {
+		t.Errorf("expected error when no endpoint serves the content")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"net/http"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
 	factory "github.com/notaryproject/ratify/v2/internal/store"
 	"github.com/notaryproject/ratify/v2/internal/store/credentialprovider"
 )
@@ -60,6 +61,11 @@ type options struct {
 	// registry. Either CABase64 or CAPem can be used, but CAPem is preferred.
 	// Optional.
 	CABase64 string `json:"caBase64,omitempty"`
+
+	// Mirrors maps a registry host, e.g. "docker.io", to the mirrors of the
+	// registry. Mirrors are tried in order before the registry itself, each
+	// with its own TLS and credential settings. Optional.
+	Mirrors map[string][]mirrorOptions `json:"mirrors,omitempty"`
 }
 
 // createHTTPClient creates an HTTP client with optional CA PEM configuration
@@ -97,6 +103,14 @@ func createHTTPClient(caPem, caBase64 string) (*http.Client, error) {
 	}, nil
 }
 
+// instrumentHTTPClient returns a copy of the HTTP client reporting the
+// requests to the registry.
+func instrumentHTTPClient(client *http.Client) *http.Client {
+	instrumented := *client
+	instrumented.Transport = metrics.NewTransport(client.Transport)
+	return &instrumented
+}
+
 func init() {
 	// Register the registry store factory.
 	factory.Register(registryStoreType, func(opts factory.NewOptions) (ratify.Store, error) {
@@ -122,7 +136,7 @@ func init() {
 		}
 
 		registryStoreOpts := ratify.RegistryStoreOptions{
-			HTTPClient:         httpClient,
+			HTTPClient:         instrumentHTTPClient(httpClient),
 			PlainHTTP:          params.PlainHTTP,
 			UserAgent:          params.UserAgent,
 			MaxBlobBytes:       params.MaxBlobBytes,
@@ -131,6 +145,10 @@ func init() {
 			CredentialProvider: credProvider,
 		}
 
-		return ratify.NewRegistryStore(registryStoreOpts), nil
+		store := ratify.NewRegistryStore(registryStoreOpts)
+		if len(params.Mirrors) == 0 {
+			return store, nil
+		}
+		return newMirrorStore(store, params)
 	})
 }
@@ -471,6 +471,19 @@ func TestCreateHTTPClient(t *testing.T) {
 	}
 }
 
+func TestInstrumentHTTPClient(t *testing.T) {
+	client := instrumentHTTPClient(http.DefaultClient)
+	if client == http.DefaultClient {
+		t.Fatal("expected a copy of the default HTTP client")
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Errorf("expected the default HTTP client to be unchanged, got transport %T", http.DefaultClient.Transport)
+	}
+	if client.Transport == nil {
+		t.Error("expected the HTTP client to be instrumented")
+	}
+}
+
 func TestOptionsUnmarshal(t *testing.T) {
 	// Generate test certificate
 	testCACert, err := generateTestCertificate()
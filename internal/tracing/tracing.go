@@ -0,0 +1,116 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/notaryproject/ratify/v2"
+	serviceName = "ratify"
+)
+
+// Attribute keys recorded on the spans.
+const (
+	AttributeArtifact      = attribute.Key("ratify.artifact")
+	AttributeCached        = attribute.Key("ratify.cached")
+	AttributeScope         = attribute.Key("ratify.scope")
+	AttributeStoreType     = attribute.Key("ratify.store.type")
+	AttributeVerifierName  = attribute.Key("ratify.verifier.name")
+	AttributeVerifierType  = attribute.Key("ratify.verifier.type")
+	AttributeProvider      = attribute.Key("ratify.credential.provider")
+	AttributeRegistry      = attribute.Key("ratify.registry")
+	AttributeSubjectDigest = attribute.Key("ratify.subject.digest")
+	AttributeSucceeded     = attribute.Key("ratify.succeeded")
+)
+
+// Options configures the OTLP trace exporter.
+type Options struct {
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g.
+	// "http://localhost:4318". Spans are not exported if empty.
+	Endpoint string
+
+	// SampleRatio is the fraction of root spans to sample, between 0 and 1.
+	// Spans with a sampled remote parent are always sampled.
+	SampleRatio float64
+}
+
+// Init installs the W3C trace context propagator and, if an endpoint is
+// configured, a tracer provider exporting spans to the OTLP collector. The
+// returned function flushes and shuts down the exporter.
+func Init(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid tracing sample ratio %v", opts.SampleRatio)
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q, expected an http or https URL", opts.Endpoint)
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(opts.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span with the given name and attributes as a child of the
+// span in ctx, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err, if any, on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Middleware extracts the incoming W3C trace context from the request headers
+// so that spans started by the handlers join the caller's trace.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
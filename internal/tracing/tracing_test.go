@@ -0,0 +1,136 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+)
+
+func newTestRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{
+			name: "no endpoint",
+		},
+		{
+			name: "valid endpoint",
+			opts: Options{Endpoint: "http://localhost:4318", SampleRatio: 1},
+		},
+		{
+			name:    "invalid sample ratio",
+			opts:    Options{Endpoint: "http://localhost:4318", SampleRatio: 2},
+			wantErr: true,
+		},
+		{
+			name:    "invalid endpoint",
+			opts:    Options{Endpoint: "://invalid", SampleRatio: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Init(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("failed to shut down: %v", err)
+			}
+		})
+	}
+}
+
+func TestSpans(t *testing.T) {
+	recorder := newTestRecorder(t)
+
+	ctx, parent := StartSpan(context.Background(), "parent", attribute.String("key", "value"))
+	_, child := StartSpan(ctx, "child")
+	EndSpan(child, errors.New("failed"))
+	EndSpan(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "child" || spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("expected child span of parent, got %s", spans[0].Name())
+	}
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "failed" {
+		t.Errorf("expected error status, got %v", spans[0].Status())
+	}
+	if spans[1].Status().Code != codes.Unset {
+		t.Errorf("expected unset status, got %v", spans[1].Status())
+	}
+	if attrs := spans[1].Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("key", "value") {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := newTestRecorder(t)
+	if _, err := Init(context.Background(), Options{}); err != nil {
+		t.Fatalf("failed to initialize tracing: %v", err)
+	}
+
+	handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, span := StartSpan(r.Context(), "handler")
+		span.End()
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	wantTraceID, _ := trace.TraceIDFromHex(testTraceID)
+	if got := spans[0].SpanContext().TraceID(); got != wantTraceID {
+		t.Errorf("expected trace ID %s, got %s", wantTraceID, got)
+	}
+	if !spans[0].Parent().IsRemote() {
+		t.Errorf("expected remote parent")
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify-verifier-go/cosign"
@@ -27,6 +26,7 @@ import (
 	"github.com/sigstore/sigstore-go/pkg/verify"
 	"oras.land/oras-go/v2/registry"
 
+	"github.com/notaryproject/ratify/v2/internal/scope"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 )
 
@@ -40,22 +40,21 @@ const (
 // [cosign.Verifier] instances, each associated with specific scopes
 // (registries or repositories).
 //
-// The verifier supports three types of scope patterns:
-//   - Wildcard registries: "*.example.com" matches any subdomain of example.com
-//   - Specific registries: "registry.example.com" matches only that registry
-//   - Repository paths: "registry.example.com/namespace/repo" matches a
-//     specific repository
+// Scopes are matched by [scope.Matcher], the same matcher used to route
+// artifacts to executors. Besides wildcard registries, specific registries and
+// repository paths, repository prefixes such as
+// "registry.example.com/namespace/*" and "registry.example.com/namespace/**"
+// are supported.
 //
 // Scope matching follows a precedence order from most specific to least
 // specific:
 //  1. Exact repository match
-//  2. Exact registry match
-//  3. Wildcard registry match
+//  2. Longest repository prefix match
+//  3. Exact registry match
+//  4. Wildcard registry match
 type Verifier struct {
-	name       string
-	wildcard   map[string]*cosign.Verifier
-	registry   map[string]*cosign.Verifier
-	repository map[string]*cosign.Verifier
+	name    string
+	matcher scope.Matcher[*cosign.Verifier]
 }
 
 // ScopedOptions defines the configuration options for a scoped
@@ -122,10 +121,7 @@ func NewVerifier(opts verifier.NewOptions, globalScopes []string) (ratify.Verifi
 	}
 
 	scopedVerifier := &Verifier{
-		name:       opts.Name,
-		wildcard:   make(map[string]*cosign.Verifier),
-		registry:   make(map[string]*cosign.Verifier),
-		repository: make(map[string]*cosign.Verifier),
+		name: opts.Name,
 	}
 
 	for _, trustPolicy := range params.TrustPolicies {
@@ -195,22 +191,9 @@ func (v *Verifier) matchVerifier(repository string) (*cosign.Verifier, error) {
 		return nil, fmt.Errorf("failed to parse repository reference %q: %w", repository, err)
 	}
 
-	repo := ref.Registry + "/" + ref.Repository
-	if verifier, ok := v.repository[repo]; ok {
+	if verifier, ok := v.matcher.Match(ref); ok {
 		return verifier, nil
 	}
-
-	registry := ref.Registry
-	if verifier, ok := v.registry[registry]; ok {
-		return verifier, nil
-	}
-
-	if _, after, ok := strings.Cut(ref.Registry, "."); ok {
-		if verifier, ok := v.wildcard[after]; ok {
-			return verifier, nil
-		}
-	}
-
 	return nil, fmt.Errorf("no verifier configured for the repository %q", repository)
 }
 
@@ -222,67 +205,7 @@ func (v *Verifier) registerVerifier(scope string, verifier *cosign.Verifier) err
 	if verifier == nil {
 		return fmt.Errorf("verifier cannot be nil")
 	}
-
-	if strings.Contains(scope, "/") {
-		return v.registerRepository(scope, verifier)
-	}
-	return v.registerRegistry(scope, verifier)
-}
-
-// registerRepository registers a verifier for a specific repository scope.
-// The scope must be a valid repository path without wildcards, tags, or digests.
-func (v *Verifier) registerRepository(scope string, verifier *cosign.Verifier) error {
-	if strings.Contains(scope, "*") {
-		return fmt.Errorf("invalid scope %q: scope cannot contain wildcard for repository", scope)
-	}
-	ref, err := registry.ParseReference(scope)
-	if err != nil {
-		return fmt.Errorf("invalid scope %q: %w", scope, err)
-	}
-	if ref.Reference != "" {
-		return fmt.Errorf("invalid scope %q: scope cannot contain a tag or digest", scope)
-	}
-
-	if _, ok := v.repository[scope]; ok {
-		return fmt.Errorf("duplicate repository scope %q detected", scope)
-	}
-	v.repository[scope] = verifier
-	return nil
-}
-
-// registerRegistry registers a verifier for a given registry scope.
-// It supports both exact registry matches and wildcard registry matches.
-// The scope can be a specific registry (e.g., "registry.example.com") or a
-// wildcard registry (e.g., "*.example.com").
-func (v *Verifier) registerRegistry(scope string, verifier *cosign.Verifier) error {
-	ref := registry.Reference{
-		Registry: scope,
-	}
-	if err := ref.ValidateRegistry(); err != nil {
-		return fmt.Errorf("invalid scope %q: %w", scope, err)
-	}
-
-	switch strings.Count(scope, "*") {
-	case 0:
-		if _, ok := v.registry[scope]; ok {
-			return fmt.Errorf("duplicate registry scope %q detected", scope)
-		}
-		v.registry[scope] = verifier
-	case 1:
-		// Wildcard registry match
-		if !strings.HasPrefix(scope, "*.") {
-			return fmt.Errorf("invalid scope %q: wildcard must be at the beginning of the scope", scope)
-		}
-		scope = scope[2:] // Remove "*." prefix
-		if _, ok := v.wildcard[scope]; ok {
-			return fmt.Errorf("duplicate wildcard scope %q detected", scope)
-		}
-		v.wildcard[scope] = verifier
-	default:
-		return fmt.Errorf("invalid scope %q: scope can only contain one wildcard", scope)
-	}
-
-	return nil
+	return v.matcher.Register(scope, verifier)
 }
 
 // toVerifierOptions converts [ScopedOptions] to [cosign.VerifierOptions].
@@ -121,7 +121,7 @@ func TestNewVerifier(t *testing.T) {
 				},
 			},
 			wantErr:     true,
-			errContains: "scope cannot contain wildcard for repository",
+			errContains: "wildcard is only supported as the last path segment",
 		},
 		{
 			name: "repository scope with tag",
@@ -412,6 +412,10 @@ func TestVerifier_MatchVerifier(t *testing.T) {
 					"name":   "repo-policy",
 					"scopes": []string{"registry.example.com/namespace/repo"},
 				},
+				map[string]interface{}{
+					"name":   "prefix-policy",
+					"scopes": []string{"other.registry.com/team/**"},
+				},
 			},
 		},
 	}
@@ -444,6 +448,11 @@ func TestVerifier_MatchVerifier(t *testing.T) {
 			repository: "sub.example.com/some/repo",
 			wantErr:    false,
 		},
+		{
+			name:       "repository prefix match",
+			repository: "other.registry.com/team/group/repo",
+			wantErr:    false,
+		},
 		{
 			name:        "no match",
 			repository:  "other.registry.com/repo",
@@ -541,10 +550,7 @@ func TestRegisterVerifier(t *testing.T) {
 	}
 
 	verifier := &Verifier{
-		name:       testVerifierName,
-		wildcard:   make(map[string]*cosign.Verifier),
-		registry:   make(map[string]*cosign.Verifier),
-		repository: make(map[string]*cosign.Verifier),
+		name: testVerifierName,
 	}
 
 	tests := []struct {
@@ -736,10 +742,7 @@ func TestInit_RegistryFactoryRegistration(t *testing.T) {
 
 func TestVerifier_RegisterRegistry_EdgeCases(t *testing.T) {
 	verifier := &Verifier{
-		name:       testVerifierName,
-		wildcard:   make(map[string]*cosign.Verifier),
-		registry:   make(map[string]*cosign.Verifier),
-		repository: make(map[string]*cosign.Verifier),
+		name: testVerifierName,
 	}
 
 	// Create a mock cosign verifier
@@ -779,36 +782,36 @@ func TestVerifier_RegisterRegistry_EdgeCases(t *testing.T) {
 	}
 
 	// First register a wildcard to test duplicate detection
-	err = verifier.registerRegistry("*.test.com", mockCosignVerifier)
+	err = verifier.registerVerifier("*.test.com", mockCosignVerifier)
 	if err != nil {
 		t.Fatalf("Failed to register initial wildcard: %v", err)
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifier.registerRegistry(tt.scope, mockCosignVerifier)
+			err := verifier.registerVerifier(tt.scope, mockCosignVerifier)
 
 			if tt.name == "duplicate wildcard registration" {
 				// This should fail due to duplicate
 				if err == nil {
-					t.Errorf("registerRegistry() expected error for duplicate wildcard but got none")
+					t.Errorf("registerVerifier() expected error for duplicate wildcard but got none")
 				}
 				return
 			}
 
 			if tt.wantErr {
 				if err == nil {
-					t.Errorf("registerRegistry() expected error but got none")
+					t.Errorf("registerVerifier() expected error but got none")
 					return
 				}
 				if tt.errContains != "" && !containsError(err.Error(), tt.errContains) {
-					t.Errorf("registerRegistry() error = %v, want error containing %q", err, tt.errContains)
+					t.Errorf("registerVerifier() error = %v, want error containing %q", err, tt.errContains)
 				}
 				return
 			}
 
 			if err != nil {
-				t.Errorf("registerRegistry() unexpected error = %v", err)
+				t.Errorf("registerVerifier() unexpected error = %v", err)
 			}
 		})
 	}
@@ -816,10 +819,7 @@ func TestVerifier_RegisterRegistry_EdgeCases(t *testing.T) {
 
 func TestVerifier_RegisterRepository_EdgeCases(t *testing.T) {
 	verifier := &Verifier{
-		name:       testVerifierName,
-		wildcard:   make(map[string]*cosign.Verifier),
-		registry:   make(map[string]*cosign.Verifier),
-		repository: make(map[string]*cosign.Verifier),
+		name: testVerifierName,
 	}
 
 	// Create a mock cosign verifier
@@ -856,21 +856,21 @@ func TestVerifier_RegisterRepository_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := verifier.registerRepository(tt.scope, mockCosignVerifier)
+			err := verifier.registerVerifier(tt.scope, mockCosignVerifier)
 
 			if tt.wantErr {
 				if err == nil {
-					t.Errorf("registerRepository() expected error but got none")
+					t.Errorf("registerVerifier() expected error but got none")
 					return
 				}
 				if tt.errContains != "" && !containsError(err.Error(), tt.errContains) {
-					t.Errorf("registerRepository() error = %v, want error containing %q", err, tt.errContains)
+					t.Errorf("registerVerifier() error = %v, want error containing %q", err, tt.errContains)
 				}
 				return
 			}
 
 			if err != nil {
-				t.Errorf("registerRepository() unexpected error = %v", err)
+				t.Errorf("registerVerifier() unexpected error = %v", err)
 			}
 		})
 	}
@@ -64,7 +64,11 @@ func New(opts NewOptions, globalScopes []string) (ratify.Verifier, error) {
 	if !ok {
 		return nil, fmt.Errorf("verifier factory of type %s is not registered", opts.Type)
 	}
-	return create(opts, globalScopes)
+	verifier, err := create(opts, globalScopes)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedVerifier{Verifier: verifier}, nil
 }
 
 // NewVerifiers creates a slice of [ratify.Verifier] instances based on the
@@ -96,15 +96,21 @@ func TestNewVerifier(t *testing.T) {
 	})
 
 	t.Run("Creating a verifier with registered type", func(t *testing.T) {
-		Register(testType, createVerifier)
+		Register(testType, createMockVerifier)
 		defer func() {
 			delete(registeredVerifiers, testType)
 		}()
 
 		opts := NewOptions{Name: testName, Type: testType}
-		_, err := New(opts, nil)
+		verifier, err := New(opts, nil)
 		if err != nil {
-			t.Errorf("Did not expect error when creating a verifier with registered type, but got: %v", err)
+			t.Fatalf("Did not expect error when creating a verifier with registered type, but got: %v", err)
+		}
+		if _, ok := verifier.(*instrumentedVerifier); !ok {
+			t.Errorf("Expected the verifier to be instrumented, got %T", verifier)
+		}
+		if _, err = verifier.Verify(context.Background(), &ratify.VerifyOptions{}); err != nil {
+			t.Errorf("Did not expect error when verifying, but got: %v", err)
 		}
 	})
 }
@@ -0,0 +1,66 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifier
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/logger"
+	"github.com/notaryproject/ratify/v2/internal/metrics"
+	"github.com/notaryproject/ratify/v2/internal/tracing"
+)
+
+var logOpt = logger.Option{ComponentType: logger.Verifier}
+
+// instrumentedVerifier wraps a [ratify.Verifier] and reports the duration and
+// outcome of every verification, each recorded as a span.
+type instrumentedVerifier struct {
+	ratify.Verifier
+}
+
+// Verify verifies the artifact with the wrapped verifier and reports it.
+func (v *instrumentedVerifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	ctx, span := tracing.StartSpan(ctx, "verifier.Verify",
+		tracing.AttributeVerifierName.String(v.Name()),
+		tracing.AttributeVerifierType.String(v.Type()),
+		tracing.AttributeArtifact.String(opts.Repository+"@"+opts.ArtifactDescriptor.Digest.String()),
+		tracing.AttributeSubjectDigest.String(opts.SubjectDescriptor.Digest.String()),
+	)
+	start := time.Now()
+	result, err := v.Verifier.Verify(ctx, opts)
+	success := err == nil && result != nil && result.Err == nil
+	metrics.ReportVerifierDuration(ctx, time.Since(start), v.Name(), v.Type(), success, err != nil)
+	span.SetAttributes(tracing.AttributeSucceeded.Bool(success))
+	logger.GetLogger(ctx, logOpt).Debugf("verifier %s verified artifact %s@%s: succeeded: %t", v.Name(), opts.Repository, opts.ArtifactDescriptor.Digest, success)
+	spanErr := err
+	if spanErr == nil && result != nil {
+		spanErr = result.Err
+	}
+	tracing.EndSpan(span, spanErr)
+	return result, err
+}
+
+// Close closes the wrapped verifier if it holds background resources, e.g. a
+// trust store refreshing its key providers.
+func (v *instrumentedVerifier) Close() error {
+	if closer, ok := v.Verifier.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -27,6 +27,14 @@ type KeyProvider interface {
 	GetCertificates(ctx context.Context) ([]*x509.Certificate, error)
 }
 
+// Refresher is implemented by key providers whose key material can change
+// after initialization, e.g. when certificates are rotated in a key vault.
+type Refresher interface {
+	// Refresh refetches the key material and reports whether it changed. The
+	// key material is kept as is if the refresh fails.
+	Refresh(ctx context.Context) (bool, error)
+}
+
 type keyProviderFactory func(options any) (KeyProvider, error)
 
 var keyProviderFactories = make(map[string]keyProviderFactory)
@@ -36,11 +44,36 @@ func RegisterKeyProvider(name string, factory keyProviderFactory) {
 	keyProviderFactories[name] = factory
 }
 
-// CreateKeyProvider creates a new key provider instance.
+// InitError is returned by [CreateKeyProvider] if a registered key provider
+// fails to initialize, e.g. because the key material cannot be fetched.
+type InitError struct {
+	// Name is the name of the key provider.
+	Name string
+
+	// Err is the error returned by the key provider factory.
+	Err error
+}
+
+// Error returns the error message.
+func (e *InitError) Error() string {
+	return fmt.Sprintf("failed to initialize key provider %s: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the key provider factory.
+func (e *InitError) Unwrap() error {
+	return e.Err
+}
+
+// CreateKeyProvider creates a new key provider instance. If the key provider
+// fails to initialize, the returned error is an [*InitError].
 func CreateKeyProvider(name string, options any) (KeyProvider, error) {
 	factory, exists := keyProviderFactories[name]
 	if !exists {
 		return nil, fmt.Errorf("key provider %s not registered", name)
 	}
-	return factory(options)
+	provider, err := factory(options)
+	if err != nil {
+		return nil, &InitError{Name: name, Err: err}
+	}
+	return provider, nil
 }
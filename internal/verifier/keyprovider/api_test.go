@@ -18,6 +18,7 @@ package keyprovider
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"testing"
 )
 
@@ -46,3 +47,22 @@ func TestCreateKeyProvider(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestCreateKeyProvider_InitError(t *testing.T) {
+	factoryErr := errors.New("failed to fetch certificates")
+	RegisterKeyProvider(mockProvider, func(_ any) (KeyProvider, error) {
+		return nil, factoryErr
+	})
+
+	_, err := CreateKeyProvider(mockProvider, nil)
+	var initErr *InitError
+	if !errors.As(err, &initErr) {
+		t.Fatalf("expected InitError, got %v", err)
+	}
+	if initErr.Name != mockProvider {
+		t.Errorf("expected key provider name %q, got %q", mockProvider, initErr.Name)
+	}
+	if !errors.Is(err, factoryErr) {
+		t.Errorf("expected error to wrap %v, got %v", factoryErr, err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"slices"
 	"sync"
 
 	"golang.org/x/crypto/pkcs12"
@@ -126,6 +127,21 @@ func (p *Provider) GetCertificates(_ context.Context) ([]*x509.Certificate, erro
 	return p.cachedCerts, nil
 }
 
+// Refresh implements [keyprovider.Refresher]. It refetches the certificate
+// chains from Azure Key Vault, e.g. to pick up the latest version of a
+// certificate specified without version, and reports whether they changed.
+func (p *Provider) Refresh(ctx context.Context) (bool, error) {
+	certs, err := p.fetchAllCertificates(ctx)
+	if err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := !slices.EqualFunc(p.cachedCerts, certs, (*x509.Certificate).Equal)
+	p.cachedCerts = certs
+	return changed, nil
+}
+
 // fetchAllCertificates fetches all certificate chains from Azure Key Vault
 // during initialization
 func (p *Provider) fetchAllCertificates(ctx context.Context) ([]*x509.Certificate, error) {
@@ -23,6 +23,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	notationx509 "github.com/notaryproject/notation-core-go/x509"
 	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
@@ -36,6 +38,7 @@ const fileSystemProviderName = "files"
 type FileSystemProvider struct {
 	certPaths    []string
 	certificates []*x509.Certificate
+	mu           sync.RWMutex
 }
 
 func init() {
@@ -54,13 +57,9 @@ func init() {
 		}
 
 		// Load certificates during initialization
-		var allCertificates []*x509.Certificate
-		for _, certPath := range paths {
-			certificates, err := loadCertificatesFromPath(certPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load certificates from path %s: %w", certPath, err)
-			}
-			allCertificates = append(allCertificates, certificates...)
+		allCertificates, err := loadCertificatesFromPaths(paths)
+		if err != nil {
+			return nil, err
 		}
 
 		return &FileSystemProvider{
@@ -73,11 +72,41 @@ func init() {
 // FileSystemProvider implements GetCertificates of [truststore.X509TrustStore]
 // interface.
 func (f *FileSystemProvider) GetCertificates(_ context.Context) ([]*x509.Certificate, error) {
-	// Return cached certificates loaded during initialization
+	// Return cached certificates loaded during initialization or the last
+	// refresh
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	logrus.Debugf("Returning %d cached certificate(s) from file system", len(f.certificates))
 	return f.certificates, nil
 }
 
+// Refresh implements [keyprovider.Refresher]. It reloads the certificates from
+// the file system, e.g. after a mounted secret is updated, and reports whether
+// they changed.
+func (f *FileSystemProvider) Refresh(_ context.Context) (bool, error) {
+	certificates, err := loadCertificatesFromPaths(f.certPaths)
+	if err != nil {
+		return false, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	changed := !slices.EqualFunc(f.certificates, certificates, (*x509.Certificate).Equal)
+	f.certificates = certificates
+	return changed, nil
+}
+
+func loadCertificatesFromPaths(paths []string) ([]*x509.Certificate, error) {
+	var allCertificates []*x509.Certificate
+	for _, certPath := range paths {
+		certificates, err := loadCertificatesFromPath(certPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load certificates from path %s: %w", certPath, err)
+		}
+		allCertificates = append(allCertificates, certificates...)
+	}
+	return allCertificates, nil
+}
+
 func loadCertificatesFromPath(path string) ([]*x509.Certificate, error) {
 	logrus.Infof("Loading certificates from path: %s", path)
 	var certificates []*x509.Certificate
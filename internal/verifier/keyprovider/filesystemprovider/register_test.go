@@ -166,6 +166,57 @@ func TestGetCertificatesFromCache(t *testing.T) {
 	}
 }
 
+func TestRefresh(t *testing.T) {
+	tempDir := t.TempDir()
+	certFile := filepath.Join(tempDir, certFileName)
+	writeCert := func() {
+		t.Helper()
+		certContent, err := createCert()
+		if err != nil {
+			t.Fatalf("failed to create certificate: %v", err)
+		}
+		if err := os.WriteFile(certFile, certContent, 0600); err != nil {
+			t.Fatalf("failed to write cert file: %v", err)
+		}
+	}
+	writeCert()
+	provider, err := keyprovider.CreateKeyProvider(fileSystemProviderName, []string{tempDir})
+	if err != nil {
+		t.Fatalf("failed to create key provider: %v", err)
+	}
+	refresher, ok := provider.(keyprovider.Refresher)
+	if !ok {
+		t.Fatalf("expected the key provider to be refreshable")
+	}
+	ctx := context.Background()
+	original, _ := provider.GetCertificates(ctx)
+
+	if changed, err := refresher.Refresh(ctx); err != nil || changed {
+		t.Errorf("expected unchanged certificates, got changed: %t, err: %v", changed, err)
+	}
+
+	// The certificate is rotated.
+	writeCert()
+	if changed, err := refresher.Refresh(ctx); err != nil || !changed {
+		t.Errorf("expected changed certificates, got changed: %t, err: %v", changed, err)
+	}
+	certs, _ := provider.GetCertificates(ctx)
+	if len(certs) != 1 || certs[0].Equal(original[0]) {
+		t.Errorf("expected the rotated certificate to be served")
+	}
+
+	// The certificates are kept if the refresh fails.
+	if err := os.WriteFile(certFile, []byte("invalid cert content"), 0600); err != nil {
+		t.Fatalf("failed to write cert file: %v", err)
+	}
+	if _, err := refresher.Refresh(ctx); err == nil {
+		t.Errorf("expected error for an invalid certificate")
+	}
+	if kept, _ := provider.GetCertificates(ctx); len(kept) != 1 || !kept[0].Equal(certs[0]) {
+		t.Errorf("expected the certificates to be kept after a failed refresh")
+	}
+}
+
 func createCert() ([]byte, error) {
 	// Generate a private key first (needed for signing)
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -18,6 +18,7 @@ package notation
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
 	"github.com/notaryproject/notation-go/verifier/truststore"
@@ -53,10 +54,16 @@ type options struct {
 	// verifier. Certificates would be loaded into trust store for Notation
 	// verifier to access. Required.
 	Certificates []trustStoreOptions `json:"certificates"`
+
+	// RefreshInterval is the interval between refreshes of the certificates of
+	// the key providers supporting it, e.g. "1h". The cached results of the
+	// executor scopes are invalidated whenever the refreshed certificates
+	// change. Certificates are only loaded once if not set. Optional.
+	RefreshInterval string `json:"refreshInterval,omitempty"`
 }
 
 func init() {
-	verifier.Register(verifierTypeNotation, func(opts verifier.NewOptions, _ []string) (ratify.Verifier, error) {
+	verifier.Register(verifierTypeNotation, func(opts verifier.NewOptions, scopes []string) (ratify.Verifier, error) {
 		raw, err := json.Marshal(opts.Parameters)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
@@ -71,6 +78,13 @@ func init() {
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize trust store: %w", err)
 		}
+		var refreshInterval time.Duration
+		if params.RefreshInterval != "" {
+			refreshInterval, err = time.ParseDuration(params.RefreshInterval)
+			if err != nil || refreshInterval <= 0 {
+				return nil, fmt.Errorf("invalid refresh interval %q", params.RefreshInterval)
+			}
+		}
 
 		notationOpts := &notation.VerifierOptions{
 			Name:           opts.Name,
@@ -78,11 +92,28 @@ func init() {
 			TrustStore:     trustStore,
 		}
 
-		return notation.NewVerifier(notationOpts)
+		notationVerifier, err := notation.NewVerifier(notationOpts)
+		if err != nil || refreshInterval == 0 {
+			return notationVerifier, err
+		}
+		trustStore.startRefresh(refreshInterval, scopes)
+		return &refreshingVerifier{Verifier: notationVerifier, trustStore: trustStore}, nil
 	})
 }
 
-func initTrustStore(opts []trustStoreOptions) (truststore.X509TrustStore, []truststore.Type, error) {
+// refreshingVerifier is a Notation verifier whose trust store refreshes its
+// key providers in the background until the verifier is closed.
+type refreshingVerifier struct {
+	ratify.Verifier
+	trustStore *trustStore
+}
+
+// Close stops refreshing the key providers of the trust store.
+func (v *refreshingVerifier) Close() error {
+	return v.trustStore.Close()
+}
+
+func initTrustStore(opts []trustStoreOptions) (*trustStore, []truststore.Type, error) {
 	if len(opts) == 0 {
 		return nil, nil, fmt.Errorf("no trust store options provided")
 	}
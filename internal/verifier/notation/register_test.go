@@ -165,6 +165,40 @@ func TestNewVerifier(t *testing.T) {
 			},
 			expectErr: false, // Should not fail during initialization with lazy loading
 		},
+		{
+			name: "Invalid refresh interval",
+			opts: verifier.NewOptions{
+				Type: verifierTypeNotation,
+				Name: testName,
+				Parameters: options{
+					Certificates: []trustStoreOptions{
+						{
+							"type":              "ca",
+							mockKeyProviderName: nil,
+						},
+					},
+					RefreshInterval: "-1h",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Valid notation options with refresh interval",
+			opts: verifier.NewOptions{
+				Type: verifierTypeNotation,
+				Name: testName,
+				Parameters: options{
+					Certificates: []trustStoreOptions{
+						{
+							"type":              "ca",
+							mockKeyProviderName: nil,
+						},
+					},
+					RefreshInterval: "1h",
+				},
+			},
+			expectErr: false,
+		},
 		{
 			name: "Valid notation options",
 			opts: verifier.NewOptions{
@@ -17,15 +17,37 @@ package notation
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/logger"
 	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
-	"github.com/sirupsen/logrus"
 )
 
+var logOpt = logger.Option{ComponentType: logger.Verifier}
+
+// fingerprintLength is the length of the fingerprint of the certificates of a
+// trust store, a prefix of the hex encoded hash of the certificates.
+const fingerprintLength = 16
+
 type trustStore struct {
 	stores map[truststore.Type]map[string][]keyprovider.KeyProvider
+
+	// scopes are the executor scopes whose cached results are invalidated
+	// when the refreshed certificates change. The cached results of all scopes
+	// are invalidated if empty.
+	scopes []string
+
+	// stop stops refreshing the key providers. It is nil if the key providers
+	// are not refreshed.
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func newTrustStore() *trustStore {
@@ -36,19 +58,19 @@ func newTrustStore() *trustStore {
 
 // GetCertificates implements [truststore.X509TrustStore] interface.
 func (s *trustStore) GetCertificates(ctx context.Context, storeType truststore.Type, namedStore string) ([]*x509.Certificate, error) {
-	logrus.Debugf("Getting certificates from trust store %s", namedStore)
+	logger.GetLogger(ctx, logOpt).Debugf("Getting certificates from trust store %s", namedStore)
 	if namedStores, ok := s.stores[storeType]; ok {
 		if keyProviders, ok := namedStores[namedStore]; ok {
 			var allCerts []*x509.Certificate
 			for _, keyProvider := range keyProviders {
 				certs, err := keyProvider.GetCertificates(ctx)
 				if err != nil {
-					logrus.Errorf("Failed to get certificates from key provider: %v", err)
+					logger.GetLogger(ctx, logOpt).Errorf("Failed to get certificates from key provider: %v", err)
 					return nil, err
 				}
 				allCerts = append(allCerts, certs...)
 			}
-			logrus.Debugf("Found %d certificates in trust store %s", len(allCerts), namedStore)
+			logger.GetLogger(ctx, logOpt).Debugf("Found %d certificates in trust store %s", len(allCerts), namedStore)
 			return allCerts, nil
 		}
 	}
@@ -62,3 +84,107 @@ func (s *trustStore) addKeyProvider(storeType truststore.Type, namedStore string
 	}
 	s.stores[storeType][namedStore] = append(s.stores[storeType][namedStore], keyProvider)
 }
+
+// startRefresh refreshes the key providers every interval in the background
+// until the trust store is closed, invalidating the cached results of the
+// scopes whenever the certificates change. The key providers are refreshed
+// regardless of verifications, so that results cached before a rotation are
+// not served until their TTL expires.
+func (s *trustStore) startRefresh(interval time.Duration, scopes []string) {
+	s.scopes = scopes
+	s.stop = make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.refresh(context.Background())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops refreshing the key providers. It is safe to call multiple times.
+func (s *trustStore) Close() error {
+	if s.stop != nil {
+		s.stopOnce.Do(func() {
+			close(s.stop)
+		})
+	}
+	return nil
+}
+
+// refresh refreshes the key providers implementing [keyprovider.Refresher]
+// and invalidates the cached results of the scopes if any certificate
+// changed. The results are invalidated with the fingerprint of the refreshed
+// certificates, so that replicas refreshing to the same certificates share
+// their cached results.
+func (s *trustStore) refresh(ctx context.Context) {
+	var changed bool
+	for _, keyProvider := range s.keyProviders() {
+		refresher, ok := keyProvider.(keyprovider.Refresher)
+		if !ok {
+			continue
+		}
+		refreshed, err := refresher.Refresh(ctx)
+		if err != nil {
+			logger.GetLogger(ctx, logOpt).Warnf("Failed to refresh key provider, keeping its certificates: %v", err)
+			continue
+		}
+		changed = changed || refreshed
+	}
+	if !changed {
+		return
+	}
+	fingerprint, err := s.fingerprint(ctx)
+	if err != nil {
+		logger.GetLogger(ctx, logOpt).Warnf("Failed to fingerprint refreshed certificates: %v", err)
+		return
+	}
+	logger.GetLogger(ctx, logOpt).Infof("Certificates changed on refresh, invalidating cached results of scopes %v", s.scopes)
+	cache.Invalidate(fingerprint, s.scopes...)
+}
+
+// fingerprint returns the fingerprint of the certificates of all key
+// providers.
+func (s *trustStore) fingerprint(ctx context.Context) (string, error) {
+	hash := sha256.New()
+	for _, keyProvider := range s.keyProviders() {
+		certs, err := keyProvider.GetCertificates(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, cert := range certs {
+			sum := sha256.Sum256(cert.Raw)
+			hash.Write(sum[:])
+		}
+		// Separate the certificates of each key provider.
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:fingerprintLength], nil
+}
+
+// keyProviders returns the key providers of all trust stores in a stable
+// order.
+func (s *trustStore) keyProviders() []keyprovider.KeyProvider {
+	var keyProviders []keyprovider.KeyProvider
+	storeTypes := make([]truststore.Type, 0, len(s.stores))
+	for storeType := range s.stores {
+		storeTypes = append(storeTypes, storeType)
+	}
+	slices.Sort(storeTypes)
+	for _, storeType := range storeTypes {
+		namedStores := make([]string, 0, len(s.stores[storeType]))
+		for namedStore := range s.stores[storeType] {
+			namedStores = append(namedStores, namedStore)
+		}
+		slices.Sort(namedStores)
+		for _, namedStore := range namedStores {
+			keyProviders = append(keyProviders, s.stores[storeType][namedStore]...)
+		}
+	}
+	return keyProviders
+}
@@ -20,9 +20,13 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/inmemory"
 )
 
 const (
@@ -155,3 +159,132 @@ func TestTrustStoreEmptyKeyProvider(t *testing.T) {
 		t.Fatalf("expected 0 certificates, got %d", len(certs))
 	}
 }
+
+// refreshableKeyProvider is a test key provider serving the certificates set
+// for the next refresh once refreshed.
+type refreshableKeyProvider struct {
+	mu        sync.Mutex
+	current   []*x509.Certificate
+	next      []*x509.Certificate
+	refreshes int
+}
+
+func (p *refreshableKeyProvider) GetCertificates(_ context.Context) ([]*x509.Certificate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.current, nil
+}
+
+func (p *refreshableKeyProvider) Refresh(_ context.Context) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refreshes++
+	changed := len(p.next) != len(p.current) || (len(p.next) > 0 && p.next[0] != p.current[0])
+	p.current = p.next
+	return changed, nil
+}
+
+func (p *refreshableKeyProvider) refreshCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshes
+}
+
+func TestTrustStoreRefresh(t *testing.T) {
+	const (
+		scope      = "refresh.example.com"
+		otherScope = "other-refresh.example.com"
+	)
+	ctx := context.Background()
+	cert1 := &x509.Certificate{Raw: []byte("cert1")}
+	cert2 := &x509.Certificate{Raw: []byte("cert2")}
+	keyProvider := &refreshableKeyProvider{current: []*x509.Certificate{cert1}, next: []*x509.Certificate{cert1}}
+	trustStore := newTrustStore()
+	trustStore.addKeyProvider(truststore.TypeCA, storeName1, keyProvider)
+	trustStore.addKeyProvider(truststore.TypeCA, storeName1, &testKeyProvider{})
+	trustStore.scopes = []string{scope}
+
+	gen, otherGen := cache.Generation(scope), cache.Generation(otherScope)
+	trustStore.refresh(ctx)
+	if cache.Generation(scope) != gen {
+		t.Errorf("expected unchanged certificates not to invalidate cached results")
+	}
+
+	// The certificates are rotated.
+	keyProvider.next = []*x509.Certificate{cert2}
+	trustStore.refresh(ctx)
+	rotatedGen := cache.Generation(scope)
+	if rotatedGen == gen {
+		t.Errorf("expected rotated certificates to invalidate cached results of the scope")
+	}
+	if cache.Generation(otherScope) != otherGen {
+		t.Errorf("expected cached results of other scopes to be kept")
+	}
+
+	// Another trust store refreshing to the same certificates agrees on the
+	// generation.
+	other := newTrustStore()
+	other.addKeyProvider(truststore.TypeCA, storeName1, &refreshableKeyProvider{current: []*x509.Certificate{cert1}, next: []*x509.Certificate{cert2}})
+	other.addKeyProvider(truststore.TypeCA, storeName1, &testKeyProvider{})
+	other.scopes = []string{scope}
+	other.refresh(ctx)
+	if cache.Generation(scope) != rotatedGen {
+		t.Errorf("expected the generation to be derived from the certificates")
+	}
+}
+
+func TestTrustStoreStartRefresh(t *testing.T) {
+	const (
+		scope    = "ticker.example.com"
+		interval = 10 * time.Millisecond
+	)
+	ctx := context.Background()
+	cert1 := &x509.Certificate{Raw: []byte("ticker-cert1")}
+	cert2 := &x509.Certificate{Raw: []byte("ticker-cert2")}
+	keyProvider := &refreshableKeyProvider{current: []*x509.Certificate{cert1}, next: []*x509.Certificate{cert1}}
+	trustStore := newTrustStore()
+	trustStore.addKeyProvider(truststore.TypeCA, storeName1, keyProvider)
+
+	// A result is cached under the generation of its scope, as the server
+	// folds it into its cache keys.
+	results, err := inmemory.NewCache[bool](0)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	resultKey := func() string {
+		return "ticker.example.com/app@sha256:abc" + cache.Generation(scope)
+	}
+	if err := results.Set(ctx, resultKey(), true, time.Hour); err != nil {
+		t.Fatalf("failed to cache result: %v", err)
+	}
+
+	trustStore.startRefresh(interval, []string{scope})
+	defer trustStore.Close()
+
+	// The certificates are rotated while the result stays cached, and no
+	// verification gets the certificates of the trust store.
+	keyProvider.mu.Lock()
+	keyProvider.next = []*x509.Certificate{cert2}
+	keyProvider.mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := results.Get(ctx, resultKey()); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the cached result to be invalidated without any verification")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Key providers are no longer refreshed once the trust store is closed.
+	if err := trustStore.Close(); err != nil {
+		t.Fatalf("failed to close trust store: %v", err)
+	}
+	time.Sleep(2 * interval)
+	refreshes := keyProvider.refreshCount()
+	time.Sleep(5 * interval)
+	if got := keyProvider.refreshCount(); got != refreshes {
+		t.Errorf("expected no refresh after close, got %d more", got-refreshes)
+	}
+}